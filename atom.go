@@ -0,0 +1,353 @@
+package frozen
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/arr-ai/frozen/internal/pkg/value"
+)
+
+// Atom holds a reference to an immutable value that many goroutines can read
+// and update concurrently without locks. Updates are applied with
+// compare-and-swap, so update functions may run more than once under
+// contention and must be free of side-effects. The zero value holds the zero
+// value of T.
+//
+// An Atom must not be copied after first use.
+type Atom[T any] struct {
+	p atomic.Pointer[T]
+
+	updates atomic.Uint64
+	retries atomic.Uint64
+
+	watchersMutex sync.Mutex
+	watchers      map[int]func(old, current T)
+	nextWatcher   int
+}
+
+// AtomStats reports contention metrics for an Atom.
+type AtomStats struct {
+	// Updates counts successful changes to the Atom.
+	Updates uint64
+
+	// Retries counts compare-and-swap attempts that lost a race with another
+	// goroutine and had to be retried.
+	Retries uint64
+
+	// Batches counts the batches applied by a MapAtom or SetAtom. It is always
+	// zero for a plain Atom.
+	Batches uint64
+
+	// BatchedEdits counts the edits applied in batches by a MapAtom or SetAtom.
+	// BatchedEdits/Batches is the mean batch size.
+	BatchedEdits uint64
+}
+
+// NewAtom returns an Atom holding v.
+func NewAtom[T any](v T) *Atom[T] {
+	a := &Atom[T]{}
+	a.p.Store(&v)
+	return a
+}
+
+// Load returns the current value.
+func (a *Atom[T]) Load() T {
+	return deref(a.p.Load())
+}
+
+// Store replaces the current value with v.
+func (a *Atom[T]) Store(v T) {
+	old := a.p.Swap(&v)
+	a.updates.Add(1)
+	a.notify(deref(old), v)
+}
+
+// Swap replaces the current value v with f(v) and returns the new value. If
+// another goroutine changes the value between the load and the store, f is
+// called again with the newer value.
+func (a *Atom[T]) Swap(f func(v T) T) T {
+	for {
+		p := a.p.Load()
+		old := deref(p)
+		v := f(old)
+		if a.p.CompareAndSwap(p, &v) {
+			a.updates.Add(1)
+			a.notify(old, v)
+			return v
+		}
+		a.retries.Add(1)
+	}
+}
+
+// CompareAndSwap replaces the current value with replacement iff it equals
+// old, and returns true iff it did so.
+func (a *Atom[T]) CompareAndSwap(old, replacement T) bool {
+	for {
+		p := a.p.Load()
+		cur := deref(p)
+		if !value.Equal(cur, old) {
+			return false
+		}
+		if a.p.CompareAndSwap(p, &replacement) {
+			a.updates.Add(1)
+			a.notify(cur, replacement)
+			return true
+		}
+		a.retries.Add(1)
+	}
+}
+
+// Watch registers f to be called after every successful update, and returns
+// a function that unregisters it. f is called synchronously on the updating
+// goroutine, so it should return quickly. Concurrent updates may call f
+// concurrently and in any order.
+func (a *Atom[T]) Watch(f func(old, current T)) (cancel func()) {
+	a.watchersMutex.Lock()
+	defer a.watchersMutex.Unlock()
+	if a.watchers == nil {
+		a.watchers = map[int]func(old, current T){}
+	}
+	id := a.nextWatcher
+	a.nextWatcher++
+	a.watchers[id] = f
+	return func() {
+		a.watchersMutex.Lock()
+		defer a.watchersMutex.Unlock()
+		delete(a.watchers, id)
+	}
+}
+
+// Stats returns contention metrics for the Atom.
+func (a *Atom[T]) Stats() AtomStats {
+	return AtomStats{
+		Updates: a.updates.Load(),
+		Retries: a.retries.Load(),
+	}
+}
+
+func (a *Atom[T]) notify(old, current T) {
+	a.watchersMutex.Lock()
+	watchers := make([]func(old, current T), 0, len(a.watchers))
+	for _, f := range a.watchers {
+		watchers = append(watchers, f)
+	}
+	a.watchersMutex.Unlock()
+	for _, f := range watchers {
+		f(old, current)
+	}
+}
+
+func deref[T any](p *T) (_ T) {
+	if p != nil {
+		return *p
+	}
+	return
+}
+
+// atomBatcher combines edits from concurrent callers into batches. The first
+// caller to find no batch in flight becomes the flusher and applies pending
+// batches until none remain. Other callers enqueue their edits and wait for
+// the flusher to apply them.
+//
+// If applying a batch panics, every caller with edits in it panics with the
+// same value, and callers waiting on the next batch resubmit their edits, so
+// that none of them wait forever.
+type atomBatcher[E any] struct {
+	mutex    sync.Mutex
+	pending  *atomBatch[E]
+	flushing bool
+
+	batches      atomic.Uint64
+	batchedEdits atomic.Uint64
+}
+
+type atomBatch[E any] struct {
+	edits []E
+	done  chan struct{}
+
+	// panicked and retry are set before done is closed.
+	panicked any
+	retry    bool
+}
+
+func (b *atomBatcher[E]) submit(apply func(edits []E), edits ...E) {
+	b.mutex.Lock()
+	if b.pending == nil {
+		b.pending = &atomBatch[E]{done: make(chan struct{})}
+	}
+	batch := b.pending
+	batch.edits = append(batch.edits, edits...)
+	if b.flushing {
+		b.mutex.Unlock()
+		<-batch.done
+		switch {
+		case batch.retry:
+			b.submit(apply, edits...)
+		case batch.panicked != nil:
+			panic(batch.panicked)
+		}
+		return
+	}
+	b.flushing = true
+	for b.pending != nil {
+		batch := b.pending
+		b.pending = nil
+		b.mutex.Unlock()
+		b.flush(apply, batch)
+		b.mutex.Lock()
+	}
+	b.flushing = false
+	b.mutex.Unlock()
+}
+
+// flush applies batch. If apply panics, it fails batch, has the callers
+// waiting on the next batch retry and stops flushing before re-panicking.
+func (b *atomBatcher[E]) flush(apply func(edits []E), batch *atomBatch[E]) {
+	defer func() {
+		if r := recover(); r != nil {
+			batch.panicked = r
+			close(batch.done)
+			b.mutex.Lock()
+			if next := b.pending; next != nil {
+				b.pending = nil
+				next.retry = true
+				close(next.done)
+			}
+			b.flushing = false
+			b.mutex.Unlock()
+			panic(r)
+		}
+	}()
+	apply(batch.edits)
+	b.batches.Add(1)
+	b.batchedEdits.Add(uint64(len(batch.edits)))
+	close(batch.done)
+}
+
+func (b *atomBatcher[E]) addStats(stats AtomStats) AtomStats {
+	stats.Batches = b.batches.Load()
+	stats.BatchedEdits = b.batchedEdits.Load()
+	return stats
+}
+
+type mapEdit[K any, V any] struct {
+	key    K
+	value  V
+	remove bool
+}
+
+// MapAtom is an Atom holding a Map. Its Put and Remove methods combine edits
+// made concurrently by different goroutines into batches, which are applied to
+// the Map as a single update. This keeps throughput high under contention,
+// where per-edit compare-and-swap loops would mostly retry.
+//
+// A MapAtom must not be copied after first use.
+type MapAtom[K any, V any] struct {
+	Atom[Map[K, V]]
+
+	b atomBatcher[mapEdit[K, V]]
+}
+
+// NewMapAtom returns a MapAtom holding m.
+func NewMapAtom[K any, V any](m Map[K, V]) *MapAtom[K, V] {
+	a := &MapAtom[K, V]{}
+	a.p.Store(&m)
+	return a
+}
+
+// Put associates key with val. It returns once the change is visible to Load.
+func (a *MapAtom[K, V]) Put(key K, val V) {
+	a.b.submit(a.apply, mapEdit[K, V]{key: key, value: val})
+}
+
+// Remove removes key. It returns once the change is visible to Load.
+func (a *MapAtom[K, V]) Remove(key K) {
+	a.b.submit(a.apply, mapEdit[K, V]{key: key, remove: true})
+}
+
+// Stats returns contention and batching metrics for the MapAtom.
+func (a *MapAtom[K, V]) Stats() AtomStats {
+	return a.b.addStats(a.Atom.Stats())
+}
+
+func (a *MapAtom[K, V]) apply(edits []mapEdit[K, V]) {
+	// Collapse the edits so that only the last edit for each key survives.
+	final := NewMapBuilder[K, mapEdit[K, V]](len(edits))
+	for _, e := range edits {
+		final.Put(e.key, e)
+	}
+	var puts MapBuilder[K, V]
+	var removes []K
+	for i := final.Finish().Range(); i.Next(); {
+		if e := i.Value(); e.remove {
+			removes = append(removes, e.key)
+		} else {
+			puts.Put(e.key, e.value)
+		}
+	}
+	updates := puts.Finish()
+	a.Swap(func(m Map[K, V]) Map[K, V] {
+		m = m.Update(updates)
+		for _, key := range removes {
+			m = m.Without(key)
+		}
+		return m
+	})
+}
+
+type setEdit[T any] struct {
+	elem   T
+	remove bool
+}
+
+// SetAtom is an Atom holding a Set. Its Add and Remove methods batch
+// concurrent edits in the same way as MapAtom.
+//
+// A SetAtom must not be copied after first use.
+type SetAtom[T any] struct {
+	Atom[Set[T]]
+
+	b atomBatcher[setEdit[T]]
+}
+
+// NewSetAtom returns a SetAtom holding s.
+func NewSetAtom[T any](s Set[T]) *SetAtom[T] {
+	a := &SetAtom[T]{}
+	a.p.Store(&s)
+	return a
+}
+
+// Add adds elem. It returns once the change is visible to Load.
+func (a *SetAtom[T]) Add(elem T) {
+	a.b.submit(a.apply, setEdit[T]{elem: elem})
+}
+
+// Remove removes elem. It returns once the change is visible to Load.
+func (a *SetAtom[T]) Remove(elem T) {
+	a.b.submit(a.apply, setEdit[T]{elem: elem, remove: true})
+}
+
+// Stats returns contention and batching metrics for the SetAtom.
+func (a *SetAtom[T]) Stats() AtomStats {
+	return a.b.addStats(a.Atom.Stats())
+}
+
+func (a *SetAtom[T]) apply(edits []setEdit[T]) {
+	// Collapse the edits so that only the last edit for each element survives.
+	final := NewMapBuilder[T, bool](len(edits))
+	for _, e := range edits {
+		final.Put(e.elem, e.remove)
+	}
+	var adds, removes SetBuilder[T]
+	for i := final.Finish().Range(); i.Next(); {
+		if elem, remove := i.Entry(); remove {
+			removes.Add(elem)
+		} else {
+			adds.Add(elem)
+		}
+	}
+	added, removed := adds.Finish(), removes.Finish()
+	a.Swap(func(s Set[T]) Set[T] {
+		return s.Union(added).Difference(removed)
+	})
+}
@@ -0,0 +1,183 @@
+package frozen_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+)
+
+func TestAtom(t *testing.T) {
+	t.Parallel()
+
+	var a frozen.Atom[mapIntInt]
+	test.True(t, a.Load().IsEmpty())
+
+	var watched []mapIntInt
+	cancel := a.Watch(func(_, current mapIntInt) {
+		watched = append(watched, current)
+	})
+
+	a.Store(frozen.NewMap(frozen.KV(1, 1)))
+	m := a.Swap(func(m mapIntInt) mapIntInt { return m.With(2, 2) })
+	assertMapEqual(t, frozen.NewMap(frozen.KV(1, 1), frozen.KV(2, 2)), m)
+	assertMapEqual(t, m, a.Load())
+
+	test.False(t, a.CompareAndSwap(mapIntInt{}, mapIntInt{}))
+	test.True(t, a.CompareAndSwap(frozen.NewMap(frozen.KV(2, 2), frozen.KV(1, 1)), mapIntInt{}))
+	test.True(t, a.Load().IsEmpty())
+
+	cancel()
+	a.Store(m)
+	test.Equal(t, 3, len(watched))
+	test.Equal(t, uint64(4), a.Stats().Updates)
+}
+
+func TestAtomStress(t *testing.T) {
+	t.Parallel()
+
+	const goroutines = 16
+	n := 1000
+	if testing.Short() {
+		n = 100
+	}
+
+	a := frozen.NewAtom(mapIntInt{})
+	var watchedUpdates atomic.Int64
+	a.Watch(func(old, current mapIntInt) {
+		test.Equal(t, old.Count()+1, current.Count())
+		watchedUpdates.Add(1)
+	})
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		g := g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				a.Swap(func(m mapIntInt) mapIntInt { return m.With(g*n+i, i) })
+				a.Load()
+			}
+		}()
+	}
+	wg.Wait()
+
+	test.Equal(t, goroutines*n, a.Load().Count())
+	test.Equal(t, int64(goroutines*n), watchedUpdates.Load())
+	test.Equal(t, uint64(goroutines*n), a.Stats().Updates)
+}
+
+func TestMapAtomStress(t *testing.T) {
+	t.Parallel()
+
+	const goroutines = 16
+	n := 1000
+	if testing.Short() {
+		n = 100
+	}
+
+	a := frozen.NewMapAtom(mapIntInt{})
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		g := g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				a.Put(g*n+i, i)
+				test.True(t, a.Load().Has(g*n+i))
+				if i%2 == 1 {
+					a.Remove(g*n + i)
+					test.False(t, a.Load().Has(g*n+i))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	m := a.Load()
+	test.Equal(t, goroutines*n/2, m.Count())
+	for g := 0; g < goroutines; g++ {
+		for i := 0; i < n; i += 2 {
+			assertMapHas(t, m, g*n+i, i)
+		}
+	}
+	stats := a.Stats()
+	test.Equal(t, uint64(goroutines*n*3/2), stats.BatchedEdits)
+	test.True(t, stats.Batches <= stats.BatchedEdits)
+}
+
+func TestSetAtom(t *testing.T) {
+	t.Parallel()
+
+	const goroutines = 8
+	const n = 200
+
+	a := frozen.NewSetAtom(frozen.NewSet(-1))
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		g := g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				a.Add(g*n + i)
+			}
+		}()
+	}
+	wg.Wait()
+	a.Remove(-1)
+
+	test.True(t, a.Load().Equal(frozen.Iota(goroutines*n)))
+}
+
+func TestMapAtomApplyPanics(t *testing.T) {
+	t.Parallel()
+
+	const goroutines = 8
+	const n = 50
+
+	a := frozen.NewMapAtom(mapIntInt{})
+	a.Watch(func(_, current mapIntInt) {
+		if current.Has(-1) {
+			panic("bad key")
+		}
+	})
+	var panics atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		g := g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				key := g*n + i
+				if i == n/2 {
+					key = -1
+				}
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							test.Equal(t, "bad key", r)
+							panics.Add(1)
+						}
+					}()
+					a.Put(key, key)
+					if key == -1 {
+						a.Remove(-1)
+					}
+				}()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Bad Puts panicked, and later edits weren't blocked.
+	test.True(t, panics.Load() > 0)
+	a.Remove(-1)
+	a.Put(-2, -2)
+	assertMapHas(t, a.Load(), -2, -2)
+}
@@ -0,0 +1,198 @@
+package frozen
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrIterationLimit is returned when a fixpoint computation hasn't converged
+// within its iteration limit.
+var ErrIterationLimit = errors.New("iteration limit reached")
+
+// Closure returns the smallest superset of seed that is closed under step,
+// i.e., that contains step(s) for every subset s of it. It uses semi-naive
+// evaluation: each round passes step only the elements found in the previous
+// round. This is only correct if step distributes over union, i.e.,
+// step(a.Union(b)) equals step(a).Union(step(b)), which holds for steps that
+// map or join each element independently.
+func Closure[T any](seed Set[T], step func(delta Set[T]) Set[T]) Set[T] {
+	result, _ := ClosureContext(context.Background(), seed, step, 0) //nolint:errcheck // no limit or cancellation
+	return result
+}
+
+// ClosureContext is Closure with cancellation and an iteration limit. It stops
+// after limit rounds, if limit is positive, or when ctx is done. It then
+// returns the elements found so far, with ErrIterationLimit or ctx.Err().
+func ClosureContext[T any](
+	ctx context.Context,
+	seed Set[T],
+	step func(delta Set[T]) Set[T],
+	limit int,
+) (Set[T], error) {
+	result, delta := seed, seed
+	for round := 0; !delta.IsEmpty(); round++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if limit > 0 && round == limit {
+			return result, ErrIterationLimit
+		}
+		delta = step(delta).Difference(result)
+		result = result.Union(delta)
+	}
+	return result, nil
+}
+
+// TransitiveClosure returns the transitive closure of the graph with edges
+// from each key of m to the elements of its value. The result maps each node
+// to all the nodes reachable from it by one or more edges. Nodes with no
+// outgoing edges are omitted.
+//
+// It condenses the graph into its strongly connected components and computes
+// the reach of each component once, from the reach of the components it has
+// edges to, so nodes share their reach with their successors instead of each
+// searching the graph again.
+func TransitiveClosure[K any](m Map[K, Set[K]]) Map[K, Set[K]] {
+	result, _ := TransitiveClosureContext(context.Background(), m, 0) //nolint:errcheck // no limit or cancellation
+	return result
+}
+
+// TransitiveClosureContext is TransitiveClosure with cancellation and an
+// iteration limit, which applies to the search from each node, as in
+// ClosureContext. On error, it returns the reachability found so far.
+//
+// A positive limit bounds the length of the paths followed, which the
+// condensation can't do, so each node is then searched separately.
+func TransitiveClosureContext[K any](ctx context.Context, m Map[K, Set[K]], limit int) (Map[K, Set[K]], error) {
+	if limit > 0 {
+		return transitiveClosureLimited(ctx, m, limit)
+	}
+	c := closer[K]{ctx: ctx, m: m}
+	for i := m.Range(); i.Next(); {
+		if v := c.id(i.Key()); c.index[v] == unvisited {
+			if err := c.connect(v); err != nil {
+				return c.result.Finish(), err
+			}
+		}
+	}
+	return c.result.Finish(), nil
+}
+
+const unvisited = -1
+
+// closer finds the strongly connected components of a graph with Tarjan's
+// algorithm. Tarjan's algorithm completes each component after all the
+// components it has edges to, so their reach is known by then.
+type closer[K any] struct {
+	ctx context.Context //nolint:containedctx
+	m   Map[K, Set[K]]
+
+	ids   Map[K, int]
+	nodes []K
+	index []int // order of discovery, or unvisited
+	low   []int // smallest index reachable within the current search
+	comp  []int // component, or unvisited until it is complete
+	stack []int
+	count int
+
+	reach  []Set[K] // by component
+	result MapBuilder[K, Set[K]]
+}
+
+// id returns the id of node, assigning a new one if needed.
+func (c *closer[K]) id(node K) int {
+	if v, has := c.ids.Get(node); has {
+		return v
+	}
+	v := len(c.nodes)
+	c.ids = c.ids.With(node, v)
+	c.nodes = append(c.nodes, node)
+	c.index = append(c.index, unvisited)
+	c.low = append(c.low, 0)
+	c.comp = append(c.comp, unvisited)
+	return v
+}
+
+func (c *closer[K]) connect(v int) error {
+	c.index[v], c.low[v] = c.count, c.count
+	c.count++
+	c.stack = append(c.stack, v)
+	for i := c.m.GetElse(c.nodes[v], Set[K]{}).Range(); i.Next(); {
+		w := c.id(i.Value())
+		switch {
+		case c.index[w] == unvisited:
+			if err := c.connect(w); err != nil {
+				return err
+			}
+			if c.low[w] < c.low[v] {
+				c.low[v] = c.low[w]
+			}
+		case c.comp[w] == unvisited && c.index[w] < c.low[v]:
+			// w is on the stack, in the component being searched.
+			c.low[v] = c.index[w]
+		}
+	}
+	if c.low[v] < c.index[v] {
+		return nil
+	}
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+
+	// v is the root of a component, which is the top of the stack down to v.
+	k := len(c.stack) - 1
+	for c.stack[k] != v {
+		k--
+	}
+	members := c.stack[k:]
+	c.stack = c.stack[:k]
+	comp := len(c.reach)
+	for _, u := range members {
+		c.comp[u] = comp
+	}
+
+	// Every edge within a component of more than one node is part of a cycle,
+	// so the targets of those edges are all of its members, each reachable
+	// from all the others. A single node only reaches itself by a self-loop.
+	var reach Set[K]
+	for _, u := range members {
+		for i := c.m.GetElse(c.nodes[u], Set[K]{}).Range(); i.Next(); {
+			reach = reach.With(i.Value())
+			if w := c.comp[c.id(i.Value())]; w != comp {
+				reach = reach.Union(c.reach[w])
+			}
+		}
+	}
+	c.reach = append(c.reach, reach)
+	if !reach.IsEmpty() {
+		for _, u := range members {
+			if c.m.Has(c.nodes[u]) {
+				c.result.Put(c.nodes[u], reach)
+			}
+		}
+	}
+	return nil
+}
+
+// transitiveClosureLimited searches from each node of m separately, with at
+// most limit rounds per search.
+func transitiveClosureLimited[K any](ctx context.Context, m Map[K, Set[K]], limit int) (Map[K, Set[K]], error) {
+	step := func(delta Set[K]) Set[K] {
+		var next Set[K]
+		for i := delta.Range(); i.Next(); {
+			next = next.Union(m.GetElse(i.Value(), Set[K]{}))
+		}
+		return next
+	}
+	var b MapBuilder[K, Set[K]]
+	for i := m.Range(); i.Next(); {
+		reach, err := ClosureContext(ctx, i.Value(), step, limit)
+		if !reach.IsEmpty() {
+			b.Put(i.Key(), reach)
+		}
+		if err != nil {
+			return b.Finish(), err
+		}
+	}
+	return b.Finish(), nil
+}
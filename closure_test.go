@@ -0,0 +1,140 @@
+package frozen_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+)
+
+func doubleBelow(n int) func(delta frozen.Set[int]) frozen.Set[int] {
+	return func(delta frozen.Set[int]) frozen.Set[int] {
+		return frozen.SetMap(delta, func(el int) int { return 2 * el }).Where(func(el int) bool { return el < n })
+	}
+}
+
+func TestClosure(t *testing.T) {
+	t.Parallel()
+
+	test.True(t, frozen.Closure(frozen.NewSet(1), doubleBelow(100)).Equal(frozen.NewSet(1, 2, 4, 8, 16, 32, 64)))
+	test.True(t, frozen.Closure(frozen.NewSet(3, 5), doubleBelow(30)).Equal(frozen.NewSet(3, 5, 6, 10, 12, 20, 24)))
+	test.True(t, frozen.Closure(frozen.Set[int]{}, doubleBelow(30)).IsEmpty())
+
+	// Each round only sees the elements that are new.
+	var seen frozen.Set[int]
+	step := func(delta frozen.Set[int]) frozen.Set[int] {
+		test.True(t, delta.Intersection(seen).IsEmpty())
+		seen = seen.Union(delta)
+		return frozen.SetMap(delta, func(el int) int { return (el + 1) % 10 })
+	}
+	test.True(t, frozen.Closure(frozen.NewSet(0), step).Equal(frozen.Iota(10)))
+	test.True(t, seen.Equal(frozen.Iota(10)))
+}
+
+func TestClosureContext(t *testing.T) {
+	t.Parallel()
+
+	step := doubleBelow(1000)
+	result, err := frozen.ClosureContext(context.Background(), frozen.NewSet(1), step, 3)
+	test.True(t, errors.Is(err, frozen.ErrIterationLimit))
+	test.True(t, result.Equal(frozen.NewSet(1, 2, 4, 8)))
+
+	result, err = frozen.ClosureContext(context.Background(), frozen.NewSet(1), step, 10)
+	test.NoError(t, err)
+	test.Equal(t, 10, result.Count())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rounds := 0
+	result, err = frozen.ClosureContext(ctx, frozen.NewSet(1), func(delta frozen.Set[int]) frozen.Set[int] {
+		if rounds++; rounds == 2 {
+			cancel()
+		}
+		return step(delta)
+	}, 0)
+	test.True(t, errors.Is(err, context.Canceled))
+	test.True(t, result.Equal(frozen.NewSet(1, 2, 4)))
+}
+
+func TestTransitiveClosure(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	rounds := 20
+	if testing.Short() {
+		rounds = 5
+	}
+	for round := 0; round < rounds; round++ {
+		nodes := 1 + r.Intn(30)
+		var b frozen.MapBuilder[int, frozen.Set[int]]
+		for n := 0; n < nodes; n++ {
+			var edges frozen.Set[int]
+			for e := r.Intn(3); e > 0; e-- {
+				edges = edges.With(r.Intn(nodes))
+			}
+			b.Put(n, edges)
+		}
+		g := b.Finish()
+
+		// Naively add the neighbours of every node's reach until nothing changes.
+		expected := g.Where(func(_ int, v frozen.Set[int]) bool { return !v.IsEmpty() })
+		for changed := true; changed; {
+			changed = false
+			for i := expected.Range(); i.Next(); {
+				reach := i.Value()
+				for j := i.Value().Range(); j.Next(); {
+					reach = reach.Union(g.GetElse(j.Value(), frozen.Set[int]{}))
+				}
+				if reach.Count() > i.Value().Count() {
+					expected = expected.With(i.Key(), reach)
+					changed = true
+				}
+			}
+		}
+		if !test.True(t, frozen.TransitiveClosure(g).Equal(expected), "%v", g) {
+			break
+		}
+	}
+
+	chain := frozen.NewMap(
+		frozen.KV(1, frozen.NewSet(2)),
+		frozen.KV(2, frozen.NewSet(3)),
+		frozen.KV(3, frozen.NewSet(4)),
+	)
+	tc := frozen.TransitiveClosure(chain)
+	test.True(t, tc.MustGet(1).Equal(frozen.NewSet(2, 3, 4)))
+	test.False(t, tc.Has(4))
+
+	partial, err := frozen.TransitiveClosureContext(context.Background(), chain, 1)
+	test.True(t, errors.Is(err, frozen.ErrIterationLimit))
+	test.True(t, partial.Count() >= 1)
+}
+
+func TestTransitiveClosureLarge(t *testing.T) {
+	t.Parallel()
+
+	// A long chain into a cycle, which a search from every node would take
+	// quadratic time over.
+	n := 5000
+	if testing.Short() {
+		n = 500
+	}
+	var b frozen.MapBuilder[int, frozen.Set[int]]
+	for i := 0; i < 2*n; i++ {
+		b.Put(i, frozen.NewSet(i+1))
+	}
+	b.Put(2*n, frozen.NewSet(n))
+	g := b.Finish()
+	tc := frozen.TransitiveClosure(g)
+	test.Equal(t, 2*n+1, tc.Count())
+	test.True(t, tc.MustGet(0).Equal(frozen.Iota3(1, 2*n+1, 1)))
+	test.True(t, tc.MustGet(2*n).Equal(frozen.Iota3(n, 2*n+1, 1)))
+	test.True(t, tc.MustGet(n).Equal(tc.MustGet(2*n)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := frozen.TransitiveClosureContext(ctx, g, 0)
+	test.True(t, errors.Is(err, context.Canceled))
+}
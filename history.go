@@ -0,0 +1,151 @@
+package frozen
+
+import (
+	"fmt"
+	"time"
+)
+
+// HistoryEntry describes a version committed to a History.
+type HistoryEntry struct {
+	Version int
+	Time    time.Time
+	Message string
+}
+
+type historyVersion[K any, V any] struct {
+	HistoryEntry
+	m Map[K, V]
+}
+
+// History records successive versions of a Map along with metadata about each
+// commit. Versions are numbered from 1 and only ever increase, even across
+// reverts and pruning.
+//
+// Like the Map it tracks, a History is immutable. Every operation that changes
+// it returns a new History. Versions share structure with each other, so
+// keeping many versions of a large Map costs little more than the changes
+// between them. The zero value is the empty History.
+type History[K any, V any] struct {
+	versions Map[int, historyVersion[K, V]]
+	first    int
+	head     int
+}
+
+// NewHistory returns a History with m committed as version 1.
+func NewHistory[K any, V any](m Map[K, V], message string) History[K, V] {
+	return History[K, V]{}.Commit(m, message)
+}
+
+// Count returns the number of versions retained by the History.
+func (h History[K, V]) Count() int {
+	return h.versions.Count()
+}
+
+// Head returns the latest version number or 0 if nothing has been committed.
+func (h History[K, V]) Head() int {
+	return h.head
+}
+
+// Current returns the latest version of the Map or the empty Map if nothing
+// has been committed.
+func (h History[K, V]) Current() Map[K, V] {
+	m, _ := h.At(h.head)
+	return m
+}
+
+// Commit returns a History with m recorded as the new head version.
+func (h History[K, V]) Commit(m Map[K, V], message string) History[K, V] {
+	h.head++
+	if h.first == 0 {
+		h.first = h.head
+	}
+	h.versions = h.versions.With(h.head, historyVersion[K, V]{
+		HistoryEntry: HistoryEntry{
+			Version: h.head,
+			Time:    time.Now(),
+			Message: message,
+		},
+		m: m,
+	})
+	return h
+}
+
+// At returns the Map committed as version and true, or false if version was
+// never committed or has been pruned.
+func (h History[K, V]) At(version int) (_ Map[K, V], _ bool) {
+	if v, has := h.versions.Get(version); has {
+		return v.m, true
+	}
+	return
+}
+
+// MustAt returns the Map committed as version or panics if it isn't retained.
+func (h History[K, V]) MustAt(version int) Map[K, V] {
+	if m, has := h.At(version); has {
+		return m
+	}
+	panic(fmt.Sprintf("version not found: %d", version))
+}
+
+// Entry returns the metadata for version and true, or false if version isn't
+// retained.
+func (h History[K, V]) Entry(version int) (_ HistoryEntry, _ bool) {
+	if v, has := h.versions.Get(version); has {
+		return v.HistoryEntry, true
+	}
+	return
+}
+
+// Log returns the metadata of all retained versions, oldest first.
+func (h History[K, V]) Log() []HistoryEntry {
+	result := make([]HistoryEntry, 0, h.Count())
+	for i := h.first; i > 0 && i <= h.head; i++ {
+		if v, has := h.versions.Get(i); has {
+			result = append(result, v.HistoryEntry)
+		}
+	}
+	return result
+}
+
+// Diff returns the changes that turn version from into version to, or false if
+// either version isn't retained. Since versions share structure, the cost is
+// proportional to the size of the changes rather than the size of the Maps.
+func (h History[K, V]) Diff(from, to int) (_ MapDiff[K, V], _ bool) {
+	a, has := h.At(from)
+	if !has {
+		return
+	}
+	b, has := h.At(to)
+	if !has {
+		return
+	}
+	return a.Diff(b), true
+}
+
+// Revert returns a History with the Map from version committed as a new head
+// version. Earlier versions, including those after version, are retained so
+// that the revert itself can be undone. Revert panics if version isn't
+// retained.
+func (h History[K, V]) Revert(version int) History[K, V] {
+	return h.Commit(h.MustAt(version), fmt.Sprintf("revert to version %d", version))
+}
+
+// Prune returns a History that retains only the n most recent versions.
+func (h History[K, V]) Prune(n int) History[K, V] {
+	if n < 0 {
+		panic(fmt.Sprintf("negative prune count: %d", n))
+	}
+	first := h.head - n + 1
+	if first <= h.first {
+		return h
+	}
+	for i := h.first; i < first; i++ {
+		h.versions = h.versions.Without(i)
+	}
+	if h.versions.IsEmpty() {
+		h.first = 0
+	} else {
+		h.first = first
+	}
+	return h
+}
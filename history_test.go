@@ -0,0 +1,106 @@
+package frozen_test
+
+import (
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+)
+
+func TestMapDiff(t *testing.T) {
+	t.Parallel()
+
+	var m mapIntInt
+	for i := 0; i < 1000; i++ {
+		m = m.With(i, i)
+	}
+	n := m.With(1000, 1000).Without(3).With(7, 70).With(8, 8)
+
+	d := m.Diff(n)
+	assertMapEqual(t, frozen.NewMap(frozen.KV(1000, 1000)), d.Added)
+	assertMapEqual(t, frozen.NewMap(frozen.KV(3, 3)), d.Removed)
+	assertMapEqual(t, frozen.NewMap(frozen.KV(7, 70)), d.Updated)
+
+	test.True(t, m.Diff(m).IsEmpty())
+	test.True(t, m.Diff(frozen.NewMapFromKeys(frozen.Iota(1000), func(i int) int { return i })).IsEmpty())
+
+	d = mapIntInt{}.Diff(m)
+	assertMapEqual(t, m, d.Added)
+	test.True(t, d.Removed.IsEmpty())
+	d = m.Diff(mapIntInt{})
+	assertMapEqual(t, m, d.Removed)
+	test.True(t, d.Added.IsEmpty())
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	var h frozen.History[string, int]
+	test.Equal(t, 0, h.Head())
+	test.True(t, h.Current().IsEmpty())
+
+	a := frozen.NewMap(frozen.KV("x", 1))
+	b := a.With("y", 2)
+	c := b.Without("x")
+	h = h.Commit(a, "a").Commit(b, "b").Commit(c, "c")
+
+	test.Equal(t, 3, h.Head())
+	test.Equal(t, 3, h.Count())
+	assertMapEqual(t, c, h.Current())
+	assertMapEqual(t, a, h.MustAt(1))
+	_, has := h.At(4)
+	test.False(t, has)
+	test.Panic(t, func() { h.MustAt(0) })
+
+	log := h.Log()
+	if test.Equal(t, 3, len(log)) {
+		for i, msg := range []string{"a", "b", "c"} {
+			test.Equal(t, i+1, log[i].Version)
+			test.Equal(t, msg, log[i].Message)
+		}
+	}
+
+	d, ok := h.Diff(1, 3)
+	if test.True(t, ok) {
+		assertMapEqual(t, frozen.NewMap(frozen.KV("y", 2)), d.Added)
+		assertMapEqual(t, frozen.NewMap(frozen.KV("x", 1)), d.Removed)
+		test.True(t, d.Updated.IsEmpty())
+	}
+	_, ok = h.Diff(1, 4)
+	test.False(t, ok)
+
+	h = h.Revert(1)
+	test.Equal(t, 4, h.Head())
+	assertMapEqual(t, a, h.Current())
+	e, _ := h.Entry(4)
+	test.Equal(t, "revert to version 1", e.Message)
+	test.Panic(t, func() { h.Revert(5) })
+}
+
+func TestHistoryPrune(t *testing.T) {
+	t.Parallel()
+
+	var h frozen.History[int, int]
+	var m mapIntInt
+	for i := 0; i < 10; i++ {
+		m = m.With(i, i)
+		h = h.Commit(m, "")
+	}
+
+	p := h.Prune(3)
+	test.Equal(t, 3, p.Count())
+	test.Equal(t, 10, p.Head())
+	_, has := p.At(7)
+	test.False(t, has)
+	test.Equal(t, 8, p.Log()[0].Version)
+	test.Equal(t, 10, h.Count())
+
+	test.Equal(t, 3, p.Prune(5).Count())
+
+	p = h.Prune(0)
+	test.Equal(t, 0, p.Count())
+	test.Equal(t, 0, len(p.Log()))
+	p = p.Commit(m, "")
+	test.Equal(t, 11, p.Head())
+	test.Equal(t, 1, len(p.Log()))
+}
@@ -0,0 +1,57 @@
+package patricia
+
+// Iterator iterates over the keys of a Trie within a range, in ascending or
+// descending order.
+type Iterator[V any] struct {
+	stack   []*node[V]
+	lo, hi  uint64
+	reverse bool
+	current *node[V]
+}
+
+// Range returns an Iterator over all keys of t in ascending order.
+func (t Trie[V]) Range() *Iterator[V] {
+	return t.Between(0, ^uint64(0), false)
+}
+
+// Between returns an Iterator over the keys of t in [lo, hi], in descending
+// order if reverse is true.
+func (t Trie[V]) Between(lo, hi uint64, reverse bool) *Iterator[V] {
+	i := &Iterator[V]{stack: make([]*node[V], 0, 65), lo: lo, hi: hi, reverse: reverse}
+	if t.root != nil && lo <= hi {
+		i.stack = append(i.stack, t.root)
+	}
+	return i
+}
+
+// Next advances to the next key, returning false if there are none.
+func (i *Iterator[V]) Next() bool {
+	for len(i.stack) > 0 {
+		n := i.stack[len(i.stack)-1]
+		i.stack = i.stack[:len(i.stack)-1]
+		if n.last() < i.lo || n.prefix > i.hi {
+			continue
+		}
+		if n.isLeaf() {
+			i.current = n
+			return true
+		}
+		if i.reverse {
+			i.stack = append(i.stack, n.left, n.right)
+		} else {
+			i.stack = append(i.stack, n.right, n.left)
+		}
+	}
+	i.current = nil
+	return false
+}
+
+// Key returns the current key.
+func (i *Iterator[V]) Key() uint64 {
+	return i.current.prefix
+}
+
+// Value returns the current value.
+func (i *Iterator[V]) Value() V {
+	return i.current.value
+}
@@ -0,0 +1,428 @@
+// Package patricia implements a persistent big-endian PATRICIA trie keyed by
+// uint64, after Okasaki and Gill's "Fast Mergeable Integer Maps". Keys are kept
+// in unsigned order, so iteration is ordered and range queries only visit the
+// subtrees that overlap the range. The shape of a trie depends only on its keys,
+// so tries with equal keys can be compared structurally.
+package patricia
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// Trie is a persistent map from uint64 to V. The zero value is empty.
+type Trie[V any] struct {
+	root *node[V]
+}
+
+// node is a leaf if mask is zero, in which case prefix is the key. Otherwise it
+// is a branch whose keys share the bits of prefix above mask, and whose left and
+// right subtrees have the mask bit clear and set respectively.
+type node[V any] struct {
+	prefix      uint64
+	mask        uint64
+	left, right *node[V]
+	value       V
+	count       int // the number of keys in the subtree
+}
+
+func newLeaf[V any](key uint64, v V) *node[V] {
+	return &node[V]{prefix: key, value: v, count: 1}
+}
+
+// newBranch returns a branch with the given children, either of which may be
+// nil.
+func newBranch[V any](prefix, mask uint64, left, right *node[V]) *node[V] {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	}
+	return &node[V]{prefix: prefix, mask: mask, left: left, right: right, count: left.count + right.count}
+}
+
+// join returns a branch holding two nodes whose prefixes differ.
+func join[V any](p0 uint64, n0 *node[V], p1 uint64, n1 *node[V]) *node[V] {
+	m := branchingBit(p0, p1)
+	if zeroBit(p0, m) {
+		return newBranch(maskAbove(p0, m), m, n0, n1)
+	}
+	return newBranch(maskAbove(p0, m), m, n1, n0)
+}
+
+func (n *node[V]) isLeaf() bool {
+	return n.mask == 0
+}
+
+// matches returns true iff key could be in the subtree rooted at n.
+func (n *node[V]) matches(key uint64) bool {
+	if n.isLeaf() {
+		return key == n.prefix
+	}
+	return maskAbove(key, n.mask) == n.prefix
+}
+
+// last returns the greatest key that could be in the subtree rooted at n.
+func (n *node[V]) last() uint64 {
+	if n.isLeaf() {
+		return n.prefix
+	}
+	return n.prefix | (n.mask<<1 - 1)
+}
+
+func zeroBit(key, mask uint64) bool {
+	return key&mask == 0
+}
+
+// maskAbove returns the bits of key above mask.
+func maskAbove(key, mask uint64) uint64 {
+	return key &^ (mask | (mask - 1))
+}
+
+// branchingBit returns the highest bit at which p0 and p1 differ.
+func branchingBit(p0, p1 uint64) uint64 {
+	return 1 << (63 - bits.LeadingZeros64(p0^p1))
+}
+
+// Count returns the number of keys in t.
+func (t Trie[V]) Count() int {
+	if t.root == nil {
+		return 0
+	}
+	return t.root.count
+}
+
+// IsEmpty returns true iff t has no keys.
+func (t Trie[V]) IsEmpty() bool {
+	return t.root == nil
+}
+
+// Get returns the value for key and true, or false if key is absent.
+func (t Trie[V]) Get(key uint64) (_ V, _ bool) {
+	n := t.root
+	for n != nil && n.matches(key) {
+		if n.isLeaf() {
+			return n.value, true
+		}
+		if zeroBit(key, n.mask) {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return
+}
+
+// With returns t with key set to v.
+func (t Trie[V]) With(key uint64, v V) Trie[V] {
+	return Trie[V]{root: insert(t.root, newLeaf(key, v))}
+}
+
+func insert[V any](n, l *node[V]) *node[V] {
+	switch {
+	case n == nil:
+		return l
+	case !n.matches(l.prefix):
+		return join(l.prefix, l, n.prefix, n)
+	case n.isLeaf():
+		return l
+	case zeroBit(l.prefix, n.mask):
+		return newBranch(n.prefix, n.mask, insert(n.left, l), n.right)
+	default:
+		return newBranch(n.prefix, n.mask, n.left, insert(n.right, l))
+	}
+}
+
+// Without returns t without key.
+func (t Trie[V]) Without(key uint64) Trie[V] {
+	return Trie[V]{root: remove(t.root, key)}
+}
+
+func remove[V any](n *node[V], key uint64) *node[V] {
+	switch {
+	case n == nil || !n.matches(key):
+		return n
+	case n.isLeaf():
+		return nil
+	case zeroBit(key, n.mask):
+		if left := remove(n.left, key); left != n.left {
+			return newBranch(n.prefix, n.mask, left, n.right)
+		}
+	default:
+		if right := remove(n.right, key); right != n.right {
+			return newBranch(n.prefix, n.mask, n.left, right)
+		}
+	}
+	return n
+}
+
+// Resolver combines the values for a key found in both operands of Union,
+// Intersection or Difference. It returns false to drop the key from the result.
+//
+// A nil Resolver takes the value from u in Union and from t in Intersection,
+// and drops the key in Difference. Since that doesn't depend on the values,
+// subtrees that t and u share are then handled whole, so operations on tries
+// derived from one another take time proportional to their differences.
+type Resolver[V any] func(key uint64, a, b V) (V, bool)
+
+func keepA[V any](_ uint64, a, _ V) (V, bool) { return a, true }
+func keepB[V any](_ uint64, _, b V) (V, bool) { return b, true }
+func drop[V any](_ uint64, a, _ V) (V, bool)  { return a, false }
+
+// Union returns the keys in either t or u. Keys in both are resolved with f.
+func (t Trie[V]) Union(u Trie[V], f Resolver[V]) Trie[V] {
+	if f == nil {
+		return Trie[V]{root: union(t.root, u.root, keepB[V], true)}
+	}
+	return Trie[V]{root: union(t.root, u.root, f, false)}
+}
+
+// union returns the union of s and t. If shared is true, f ignores its
+// arguments' values, so s is its own union with itself.
+func union[V any](s, t *node[V], f Resolver[V], shared bool) *node[V] {
+	switch {
+	case shared && s == t:
+		return s
+	case s == nil:
+		return t
+	case t == nil:
+		return s
+	case s.isLeaf():
+		return alter(t, s.prefix, func(v V, has bool) (V, bool) {
+			if !has {
+				return s.value, true
+			}
+			return f(s.prefix, s.value, v)
+		})
+	case t.isLeaf():
+		return alter(s, t.prefix, func(v V, has bool) (V, bool) {
+			if !has {
+				return t.value, true
+			}
+			return f(t.prefix, v, t.value)
+		})
+	case s.mask == t.mask && s.prefix == t.prefix:
+		return newBranch(s.prefix, s.mask, union(s.left, t.left, f, shared), union(s.right, t.right, f, shared))
+	case s.mask > t.mask && s.matches(t.prefix):
+		if zeroBit(t.prefix, s.mask) {
+			return newBranch(s.prefix, s.mask, union(s.left, t, f, shared), s.right)
+		}
+		return newBranch(s.prefix, s.mask, s.left, union(s.right, t, f, shared))
+	case s.mask < t.mask && t.matches(s.prefix):
+		if zeroBit(s.prefix, t.mask) {
+			return newBranch(t.prefix, t.mask, union(s, t.left, f, shared), t.right)
+		}
+		return newBranch(t.prefix, t.mask, t.left, union(s, t.right, f, shared))
+	default:
+		return join(s.prefix, s, t.prefix, t)
+	}
+}
+
+// Intersection returns the keys in both t and u, resolved with f.
+func (t Trie[V]) Intersection(u Trie[V], f Resolver[V]) Trie[V] {
+	if f == nil {
+		return Trie[V]{root: intersection(t.root, u.root, keepA[V], true)}
+	}
+	return Trie[V]{root: intersection(t.root, u.root, f, false)}
+}
+
+// intersection returns the intersection of s and t. If shared is true, f
+// ignores its arguments' values, so s is its own intersection with itself.
+func intersection[V any](s, t *node[V], f Resolver[V], shared bool) *node[V] {
+	switch {
+	case shared && s == t:
+		return s
+	case s == nil || t == nil:
+		return nil
+	case s.isLeaf():
+		if v, has := (Trie[V]{root: t}).Get(s.prefix); has {
+			if r, keep := f(s.prefix, s.value, v); keep {
+				return newLeaf(s.prefix, r)
+			}
+		}
+		return nil
+	case t.isLeaf():
+		if v, has := (Trie[V]{root: s}).Get(t.prefix); has {
+			if r, keep := f(t.prefix, v, t.value); keep {
+				return newLeaf(t.prefix, r)
+			}
+		}
+		return nil
+	case s.mask == t.mask && s.prefix == t.prefix:
+		return newBranch(s.prefix, s.mask,
+			intersection(s.left, t.left, f, shared), intersection(s.right, t.right, f, shared))
+	case s.mask > t.mask && s.matches(t.prefix):
+		if zeroBit(t.prefix, s.mask) {
+			return intersection(s.left, t, f, shared)
+		}
+		return intersection(s.right, t, f, shared)
+	case s.mask < t.mask && t.matches(s.prefix):
+		if zeroBit(s.prefix, t.mask) {
+			return intersection(s, t.left, f, shared)
+		}
+		return intersection(s, t.right, f, shared)
+	default:
+		return nil
+	}
+}
+
+// Difference returns the keys in t, except that keys also in u are resolved
+// with f.
+func (t Trie[V]) Difference(u Trie[V], f Resolver[V]) Trie[V] {
+	if f == nil {
+		return Trie[V]{root: difference(t.root, u.root, drop[V], true)}
+	}
+	return Trie[V]{root: difference(t.root, u.root, f, false)}
+}
+
+// difference returns the difference of s and t. If shared is true, f drops
+// every key, so s less itself is empty.
+func difference[V any](s, t *node[V], f Resolver[V], shared bool) *node[V] {
+	switch {
+	case shared && s == t:
+		return nil
+	case s == nil || t == nil:
+		return s
+	case s.isLeaf():
+		if v, has := (Trie[V]{root: t}).Get(s.prefix); has {
+			if r, keep := f(s.prefix, s.value, v); keep {
+				return newLeaf(s.prefix, r)
+			}
+			return nil
+		}
+		return s
+	case t.isLeaf():
+		return alter(s, t.prefix, func(v V, has bool) (V, bool) {
+			if !has {
+				return v, false
+			}
+			return f(t.prefix, v, t.value)
+		})
+	case s.mask == t.mask && s.prefix == t.prefix:
+		return newBranch(s.prefix, s.mask,
+			difference(s.left, t.left, f, shared), difference(s.right, t.right, f, shared))
+	case s.mask > t.mask && s.matches(t.prefix):
+		if zeroBit(t.prefix, s.mask) {
+			return newBranch(s.prefix, s.mask, difference(s.left, t, f, shared), s.right)
+		}
+		return newBranch(s.prefix, s.mask, s.left, difference(s.right, t, f, shared))
+	case s.mask < t.mask && t.matches(s.prefix):
+		if zeroBit(s.prefix, t.mask) {
+			return difference(s, t.left, f, shared)
+		}
+		return difference(s, t.right, f, shared)
+	default:
+		return s
+	}
+}
+
+// alter replaces the value for key with the result of f, which receives the
+// current value, if any. If f returns false, key is removed.
+func alter[V any](n *node[V], key uint64, f func(v V, has bool) (V, bool)) *node[V] {
+	t := Trie[V]{root: n}
+	v, has := t.Get(key)
+	r, keep := f(v, has)
+	switch {
+	case keep:
+		return insert(n, newLeaf(key, r))
+	case has:
+		return remove(n, key)
+	default:
+		return n
+	}
+}
+
+// Equal returns true iff t and u have the same keys and eq returns true for
+// the values of each key.
+func (t Trie[V]) Equal(u Trie[V], eq func(a, b V) bool) bool {
+	return equal(t.root, u.root, eq)
+}
+
+func equal[V any](s, t *node[V], eq func(a, b V) bool) bool {
+	switch {
+	case s == t:
+		return true
+	case s == nil || t == nil:
+		return false
+	case s.prefix != t.prefix || s.mask != t.mask:
+		return false
+	case s.isLeaf():
+		return eq(s.value, t.value)
+	default:
+		return equal(s.left, t.left, eq) && equal(s.right, t.right, eq)
+	}
+}
+
+// Min returns the least key in t and its value, or false if t is empty.
+func (t Trie[V]) Min() (key uint64, _ V, _ bool) {
+	n := t.root
+	if n == nil {
+		return
+	}
+	for !n.isLeaf() {
+		n = n.left
+	}
+	return n.prefix, n.value, true
+}
+
+// Max returns the greatest key in t and its value, or false if t is empty.
+func (t Trie[V]) Max() (key uint64, _ V, _ bool) {
+	n := t.root
+	if n == nil {
+		return
+	}
+	for !n.isLeaf() {
+		n = n.right
+	}
+	return n.prefix, n.value, true
+}
+
+// Entry is a key-value pair in a Trie.
+type Entry[V any] struct {
+	Key   uint64
+	Value V
+}
+
+// FromSorted returns a Trie holding entries, which must be in strictly
+// ascending order of key. It takes time linear in the number of entries.
+func FromSorted[V any](entries []Entry[V]) Trie[V] {
+	if len(entries) == 0 {
+		return Trie[V]{}
+	}
+	return Trie[V]{root: fromSorted(entries)}
+}
+
+func fromSorted[V any](entries []Entry[V]) *node[V] {
+	if len(entries) == 1 {
+		return newLeaf(entries[0].Key, entries[0].Value)
+	}
+	first, last := entries[0].Key, entries[len(entries)-1].Key
+	m := branchingBit(first, last)
+	split := sort.Search(len(entries), func(i int) bool { return !zeroBit(entries[i].Key, m) })
+	return newBranch(maskAbove(first, m), m, fromSorted(entries[:split]), fromSorted(entries[split:]))
+}
+
+// Split returns the keys of t less than key and the rest. It only rebuilds the
+// path to key, so both halves share the remaining nodes with t.
+func (t Trie[V]) Split(key uint64) (below, above Trie[V]) {
+	lo, hi := split(t.root, key)
+	return Trie[V]{root: lo}, Trie[V]{root: hi}
+}
+
+func split[V any](n *node[V], key uint64) (lo, hi *node[V]) {
+	switch {
+	case n == nil:
+		return nil, nil
+	case n.last() < key:
+		return n, nil
+	case n.prefix >= key:
+		return nil, n
+	case zeroBit(key, n.mask):
+		lo, hi := split(n.left, key)
+		return lo, newBranch(n.prefix, n.mask, hi, n.right)
+	default:
+		lo, hi := split(n.right, key)
+		return newBranch(n.prefix, n.mask, n.left, lo), hi
+	}
+}
@@ -0,0 +1,211 @@
+package patricia_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/arr-ai/frozen/internal/pkg/patricia"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+)
+
+func randomTrie(r *rand.Rand, n int) (patricia.Trie[int], map[uint64]int) {
+	var t patricia.Trie[int]
+	m := map[uint64]int{}
+	for i := 0; i < n; i++ {
+		var k uint64
+		switch r.Intn(3) {
+		case 0:
+			k = uint64(r.Intn(100))
+		case 1:
+			k = ^uint64(0) - uint64(r.Intn(100))
+		default:
+			k = r.Uint64()
+		}
+		t = t.With(k, i)
+		m[k] = i
+	}
+	return t, m
+}
+
+func assertTrie(t *testing.T, expected map[uint64]int, actual patricia.Trie[int]) bool {
+	t.Helper()
+
+	keys := make([]uint64, 0, len(expected))
+	for k := range expected {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	var got []uint64
+	for i := actual.Range(); i.Next(); {
+		got = append(got, i.Key())
+		if !test.Equal(t, expected[i.Key()], i.Value()) {
+			return false
+		}
+	}
+	return test.Equal(t, len(expected), actual.Count()) && test.Equal(t, keys, append([]uint64{}, got...))
+}
+
+func TestTrie(t *testing.T) {
+	t.Parallel()
+
+	rounds := 100
+	if testing.Short() {
+		rounds = 10
+	}
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	sum := func(_ uint64, a, b int) (int, bool) { return a + b, true }
+	for round := 0; round < rounds; round++ {
+		a, ma := randomTrie(r, r.Intn(200))
+		b, mb := randomTrie(r, r.Intn(200))
+
+		union := map[uint64]int{}
+		intersection := map[uint64]int{}
+		difference := map[uint64]int{}
+		for k, v := range ma {
+			union[k] = v
+			difference[k] = v
+		}
+		for k, v := range mb {
+			if w, has := ma[k]; has {
+				union[k] = w + v
+				intersection[k] = w + v
+				delete(difference, k)
+			} else {
+				union[k] = v
+			}
+		}
+
+		if !assertTrie(t, ma, a) ||
+			!assertTrie(t, union, a.Union(b, sum)) ||
+			!assertTrie(t, intersection, a.Intersection(b, sum)) ||
+			!assertTrie(t, difference, a.Difference(b, func(uint64, int, int) (int, bool) { return 0, false })) {
+			break
+		}
+		test.True(t, a.Union(b, sum).Equal(b.Union(a, sum), func(x, y int) bool { return x == y }))
+
+		for k := range ma {
+			delete(ma, k)
+			a = a.Without(k)
+			if r.Intn(2) == 0 {
+				break
+			}
+		}
+		assertTrie(t, ma, a)
+	}
+}
+
+func TestTrieBetween(t *testing.T) {
+	t.Parallel()
+
+	var tr patricia.Trie[int]
+	for i := 0; i < 1000; i += 3 {
+		tr = tr.With(uint64(i), i)
+	}
+	var got []int
+	for i := tr.Between(10, 20, false); i.Next(); {
+		got = append(got, i.Value())
+	}
+	test.Equal(t, []int{12, 15, 18}, got)
+
+	got = got[:0]
+	for i := tr.Between(10, 21, true); i.Next(); {
+		got = append(got, i.Value())
+	}
+	test.Equal(t, []int{21, 18, 15, 12}, got)
+
+	k, _, _ := tr.Min()
+	test.Equal(t, uint64(0), k)
+	k, _, _ = tr.Max()
+	test.Equal(t, uint64(999), k)
+}
+
+func TestTrieSplit(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	for round := 0; round < 100; round++ {
+		a, ma := randomTrie(r, r.Intn(100))
+		var key uint64
+		if r.Intn(2) == 0 {
+			for k := range ma {
+				key = k
+				break
+			}
+		} else {
+			key = r.Uint64()
+		}
+		below, above := map[uint64]int{}, map[uint64]int{}
+		for k, v := range ma {
+			if k < key {
+				below[k] = v
+			} else {
+				above[k] = v
+			}
+		}
+		lo, hi := a.Split(key)
+		if !assertTrie(t, below, lo) || !assertTrie(t, above, hi) {
+			break
+		}
+		test.True(t, lo.Union(hi, nil).Equal(a, func(x, y int) bool { return x == y }))
+	}
+}
+
+func TestTrieShared(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	sum := func(_ uint64, a, b int) (int, bool) { return a + b, true }
+	for round := 0; round < 100; round++ {
+		a, ma := randomTrie(r, r.Intn(200))
+
+		// b shares most of its nodes with a.
+		b, mb := a, map[uint64]int{}
+		for k, v := range ma {
+			mb[k] = v
+		}
+		for i := r.Intn(5); i > 0; i-- {
+			k := r.Uint64() % 200
+			b, mb[k] = b.With(k, -1), -1
+		}
+		for k := range mb {
+			if r.Intn(10) == 0 {
+				b = b.Without(k)
+				delete(mb, k)
+			}
+		}
+
+		union := map[uint64]int{}
+		intersection := map[uint64]int{}
+		difference := map[uint64]int{}
+		for k, v := range ma {
+			union[k] = v
+			if w, has := mb[k]; has {
+				intersection[k] = v
+				union[k] = w
+			} else {
+				difference[k] = v
+			}
+		}
+		for k, v := range mb {
+			union[k] = v
+		}
+		doubled := map[uint64]int{}
+		for k, v := range ma {
+			doubled[k] = 2 * v
+		}
+
+		if !assertTrie(t, union, a.Union(b, nil)) ||
+			!assertTrie(t, intersection, a.Intersection(b, nil)) ||
+			!assertTrie(t, difference, a.Difference(b, nil)) ||
+			!assertTrie(t, ma, a.Union(a, nil)) ||
+			!assertTrie(t, ma, a.Intersection(a, nil)) ||
+			!assertTrie(t, map[uint64]int{}, a.Difference(a, nil)) ||
+			// Resolvers still see the keys of shared subtrees.
+			!assertTrie(t, doubled, a.Union(a, sum)) ||
+			!assertTrie(t, doubled, a.Intersection(a, sum)) {
+			break
+		}
+	}
+}
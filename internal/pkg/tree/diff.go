@@ -0,0 +1,53 @@
+package tree
+
+// Diff calls f for each element of t or u that is not in a subtree shared by
+// both. Subtrees that are pointer-identical in t and u are skipped without
+// being visited, so the cost is proportional to the size of the change rather
+// than the size of the trees.
+//
+// f receives (a, nil) for elements only in t, (nil, b) for elements only in u
+// and (a, b) for elements that compare equal but live in unshared subtrees. In
+// the last case, the caller decides whether a and b differ in any way that
+// matters (e.g., map values).
+func (t Tree[T]) Diff(u Tree[T], f func(a, b *T)) {
+	diffNodes(t.root, u.root, 0, f)
+}
+
+func diffNodes[T any](x, y node[T], depth int, f func(a, b *T)) {
+	switch {
+	case x == y:
+		return
+	case x == nil:
+		for i := y.Iterator(packedIteratorBuf[T](0)); i.Next(); {
+			b := i.Value()
+			f(nil, &b)
+		}
+		return
+	case y == nil:
+		for i := x.Iterator(packedIteratorBuf[T](0)); i.Next(); {
+			a := i.Value()
+			f(&a, nil)
+		}
+		return
+	}
+
+	if bx, is := x.(*branch[T]); is {
+		if by, is := y.(*branch[T]); is {
+			for m := bx.p.mask | by.p.mask; m != 0; m = m.Next() {
+				i := m.FirstIndex()
+				diffNodes(bx.p.data[i], by.p.data[i], depth+1, f)
+			}
+			return
+		}
+	}
+
+	for i := x.Iterator(packedIteratorBuf[T](0)); i.Next(); {
+		a := i.Value()
+		f(&a, y.Get(a, newHasher(a, depth)))
+	}
+	for i := y.Iterator(packedIteratorBuf[T](0)); i.Next(); {
+		if b := i.Value(); x.Get(b, newHasher(b, depth)) == nil {
+			f(nil, &b)
+		}
+	}
+}
@@ -111,6 +111,39 @@ func (t Tree[T]) Iterator() Iterator[T] {
 	return t.root.Iterator(buf)
 }
 
+// Parts returns iterators over disjoint parts of t that together cover it.
+// Branches are split breadth-first until there are at least n parts or no
+// branches remain.
+func (t Tree[T]) Parts(n int) []Iterator[T] {
+	if t.root == nil {
+		return nil
+	}
+	nodes := []node[T]{t.root}
+	for len(nodes) < n {
+		next := make([]node[T], 0, len(nodes)*fanout)
+		split := false
+		for _, nd := range nodes {
+			if b, is := nd.(*branch[T]); is {
+				for m := b.p.mask; m != 0; m = m.Next() {
+					next = append(next, b.p.GetChild(m))
+				}
+				split = true
+			} else {
+				next = append(next, nd)
+			}
+		}
+		if !split {
+			break
+		}
+		nodes = next
+	}
+	iters := make([]Iterator[T], 0, len(nodes))
+	for _, nd := range nodes {
+		iters = append(iters, nd.Iterator(packedIteratorBuf[T](t.count)))
+	}
+	return iters
+}
+
 func (t Tree[T]) OrderedIterator(less Less[T], n int) Iterator[T] {
 	if n < 0 || n > t.count {
 		n = t.count
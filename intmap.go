@@ -0,0 +1,313 @@
+package frozen
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/arr-ai/hash"
+
+	"github.com/arr-ai/frozen/internal/pkg/fu"
+	"github.com/arr-ai/frozen/internal/pkg/patricia"
+	"github.com/arr-ai/frozen/internal/pkg/value"
+)
+
+// IntMap maps integer keys to values. It is a big-endian PATRICIA trie, so
+// lookups take O(min(n, W)) time without hashing keys, unions, intersections
+// and differences work structurally, and iteration is in ascending key order.
+// The zero value is the empty IntMap.
+type IntMap[I integer, V any] struct {
+	trie patricia.Trie[V]
+}
+
+// intKey maps i to a trie key that sorts in the same order as i.
+func intKey[I integer](i I) uint64 {
+	return uint64(i) ^ signBit[I]()
+}
+
+// keyInt is the inverse of intKey.
+func keyInt[I integer](key uint64) I {
+	return I(key ^ signBit[I]())
+}
+
+// NewIntMap creates a new IntMap with kvs as keys and values. Later values
+// replace earlier ones with the same key.
+func NewIntMap[I integer, V any](kvs ...KeyValue[I, V]) IntMap[I, V] {
+	entries := make([]patricia.Entry[V], 0, len(kvs))
+	for _, kv := range kvs {
+		entries = append(entries, patricia.Entry[V]{Key: intKey(kv.Key), Value: kv.Value})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	n := 0
+	for _, e := range entries {
+		if n > 0 && entries[n-1].Key == e.Key {
+			entries[n-1] = e
+		} else {
+			entries[n] = e
+			n++
+		}
+	}
+	return IntMap[I, V]{trie: patricia.FromSorted(entries[:n])}
+}
+
+// NewIntMapFromKeys creates a new IntMap in which values are computed from
+// keys.
+func NewIntMapFromKeys[I integer, V any](keys IntSet[I], f func(key I) V) IntMap[I, V] {
+	entries := make([]patricia.Entry[V], 0, keys.Count())
+	for i := keys.Range(); i.Next(); {
+		key := i.Value()
+		entries = append(entries, patricia.Entry[V]{Key: intKey(key), Value: f(key)})
+	}
+	return IntMap[I, V]{trie: patricia.FromSorted(entries)}
+}
+
+// IsEmpty returns true if the IntMap has no entries.
+func (m IntMap[I, V]) IsEmpty() bool {
+	return m.trie.IsEmpty()
+}
+
+// Count returns the number of entries in the IntMap.
+func (m IntMap[I, V]) Count() int {
+	return m.trie.Count()
+}
+
+// Any returns an arbitrary entry from the IntMap.
+func (m IntMap[I, V]) Any() (key I, value V) {
+	if m.IsEmpty() {
+		panic("empty map")
+	}
+	return m.Min()
+}
+
+// With returns a new IntMap with key associated with val and all other keys
+// retained from m.
+func (m IntMap[I, V]) With(key I, val V) IntMap[I, V] {
+	m.trie = m.trie.With(intKey(key), val)
+	return m
+}
+
+// Without returns a new IntMap with all keys retained from m except key.
+func (m IntMap[I, V]) Without(key I) IntMap[I, V] {
+	m.trie = m.trie.Without(intKey(key))
+	return m
+}
+
+// Has returns true iff the key exists in the map.
+func (m IntMap[I, V]) Has(key I) bool {
+	_, has := m.Get(key)
+	return has
+}
+
+// Get returns the value associated with key in m and true iff the key is found.
+func (m IntMap[I, V]) Get(key I) (_ V, _ bool) {
+	return m.trie.Get(intKey(key))
+}
+
+// MustGet returns the value associated with key in m or panics if the key is
+// not found.
+func (m IntMap[I, V]) MustGet(key I) V {
+	if val, has := m.Get(key); has {
+		return val
+	}
+	panic(fmt.Sprintf("key not found: %v", key))
+}
+
+// GetElse returns the value associated with key in m or deflt if the key is not
+// found.
+func (m IntMap[I, V]) GetElse(key I, deflt V) V {
+	if val, has := m.Get(key); has {
+		return val
+	}
+	return deflt
+}
+
+// GetElseFunc returns the value associated with key in m or the result of
+// calling deflt if the key is not found.
+func (m IntMap[I, V]) GetElseFunc(key I, deflt func() V) V {
+	if val, has := m.Get(key); has {
+		return val
+	}
+	return deflt()
+}
+
+// Keys returns an IntSet with all the keys in the IntMap.
+func (m IntMap[I, V]) Keys() IntSet[I] {
+	b := NewIntSetBuilder[I](m.Count())
+	for i := m.Range(); i.Next(); {
+		b.Add(i.Key())
+	}
+	return b.Finish()
+}
+
+// Values returns a Set with all the Values in the IntMap.
+func (m IntMap[I, V]) Values() Set[V] {
+	var b SetBuilder[V]
+	for i := m.Range(); i.Next(); {
+		b.Add(i.Value())
+	}
+	return b.Finish()
+}
+
+// Project returns an IntMap with only keys included from this IntMap.
+func (m IntMap[I, V]) Project(keys ...I) IntMap[I, V] {
+	var result IntMap[I, V]
+	for _, k := range keys {
+		if v, has := m.Get(k); has {
+			result = result.With(k, v)
+		}
+	}
+	return result
+}
+
+// Where returns an IntMap with only key-value pairs satisfying pred.
+func (m IntMap[I, V]) Where(pred func(key I, val V) bool) IntMap[I, V] {
+	var entries []patricia.Entry[V]
+	for i := m.trie.Range(); i.Next(); {
+		if key, val := keyInt[I](i.Key()), i.Value(); pred(key, val) {
+			entries = append(entries, patricia.Entry[V]{Key: i.Key(), Value: val})
+		}
+	}
+	return IntMap[I, V]{trie: patricia.FromSorted(entries)}
+}
+
+// IntMapMap returns an IntMap with keys from m, but the values replaced by the
+// result of calling f.
+func IntMapMap[I integer, V, U any](m IntMap[I, V], f func(key I, val V) U) IntMap[I, U] {
+	entries := make([]patricia.Entry[U], 0, m.Count())
+	for i := m.trie.Range(); i.Next(); {
+		entries = append(entries, patricia.Entry[U]{Key: i.Key(), Value: f(keyInt[I](i.Key()), i.Value())})
+	}
+	return IntMap[I, U]{trie: patricia.FromSorted(entries)}
+}
+
+// Merge returns the union of m and n. Values of keys in both maps are combined
+// with resolve.
+func (m IntMap[I, V]) Merge(n IntMap[I, V], resolve func(key I, a, b V) V) IntMap[I, V] {
+	return IntMap[I, V]{trie: m.trie.Union(n.trie, func(key uint64, a, b V) (V, bool) {
+		return resolve(keyInt[I](key), a, b), true
+	})}
+}
+
+// Update returns an IntMap with key-value pairs from n added or replacing
+// existing keys.
+func (m IntMap[I, V]) Update(n IntMap[I, V]) IntMap[I, V] {
+	return IntMap[I, V]{trie: m.trie.Union(n.trie, nil)}
+}
+
+// Intersection returns the entries of m whose keys are also in n.
+func (m IntMap[I, V]) Intersection(n IntMap[I, V]) IntMap[I, V] {
+	return IntMap[I, V]{trie: m.trie.Intersection(n.trie, nil)}
+}
+
+// Difference returns the entries of m whose keys are not in n.
+func (m IntMap[I, V]) Difference(n IntMap[I, V]) IntMap[I, V] {
+	return IntMap[I, V]{trie: m.trie.Difference(n.trie, nil)}
+}
+
+// Min returns the entry with the least key. It panics if m is empty.
+func (m IntMap[I, V]) Min() (key I, val V) {
+	k, v, ok := m.trie.Min()
+	if !ok {
+		panic("IntMap.Min(): empty map")
+	}
+	return keyInt[I](k), v
+}
+
+// Max returns the entry with the greatest key. It panics if m is empty.
+func (m IntMap[I, V]) Max() (key I, val V) {
+	k, v, ok := m.trie.Max()
+	if !ok {
+		panic("IntMap.Max(): empty map")
+	}
+	return keyInt[I](k), v
+}
+
+// Split returns the entries of m with keys less than key and the rest. Only
+// the path to key is rebuilt, so the halves share the remaining structure with
+// m.
+func (m IntMap[I, V]) Split(key I) (below, above IntMap[I, V]) {
+	lo, hi := m.trie.Split(intKey(key))
+	return IntMap[I, V]{trie: lo}, IntMap[I, V]{trie: hi}
+}
+
+// Hash computes a hash val for m. It matches the hash of a Map with the same
+// entries.
+func (m IntMap[I, V]) Hash(seed uintptr) uintptr {
+	h := hash.Uintptr(uintptr(3167960924819262823&uint64(^uintptr(0))), seed)
+	for i := m.Range(); i.Next(); {
+		h ^= hash.Any(i.Value(), hash.Any(i.Key(), seed))
+	}
+	return h
+}
+
+// Equal returns true iff m and n have all the same key-value pairs.
+func (m IntMap[I, V]) Equal(n IntMap[I, V]) bool {
+	return m.Count() == n.Count() && m.trie.Equal(n.trie, value.Equal[V])
+}
+
+// Same returns true iff a is an IntMap and m and a have all the same
+// key-values.
+func (m IntMap[I, V]) Same(a any) bool {
+	n, is := a.(IntMap[I, V])
+	return is && m.Equal(n)
+}
+
+// String returns a string representation of the IntMap.
+func (m IntMap[I, V]) String() string {
+	return fmt.Sprintf("%v", m)
+}
+
+// Format writes a string representation of the IntMap into state.
+func (m IntMap[I, V]) Format(f fmt.State, verb rune) {
+	fu.WriteString(f, "(")
+	for i, n := m.Range(), 0; i.Next(); n++ {
+		fu.Comma(f, n)
+		fu.Format(i.Key(), f, verb)
+		fu.WriteString(f, ": ")
+		fu.Format(i.Value(), f, verb)
+	}
+	fu.WriteString(f, ")")
+}
+
+// Range returns an IntMapIterator over the IntMap in ascending key order.
+func (m IntMap[I, V]) Range() *IntMapIterator[I, V] {
+	return &IntMapIterator[I, V]{i: m.trie.Range()}
+}
+
+// Descending returns an IntMapIterator over the IntMap in descending key order.
+func (m IntMap[I, V]) Descending() *IntMapIterator[I, V] {
+	return &IntMapIterator[I, V]{i: m.trie.Between(0, ^uint64(0), true)}
+}
+
+// RangeBetween returns an IntMapIterator over the entries of m with keys in
+// [lo, hi), in ascending key order.
+func (m IntMap[I, V]) RangeBetween(lo, hi I) *IntMapIterator[I, V] {
+	if lo >= hi {
+		return &IntMapIterator[I, V]{i: patricia.Trie[V]{}.Range()}
+	}
+	return &IntMapIterator[I, V]{i: m.trie.Between(intKey(lo), intKey(hi-1), false)}
+}
+
+// IntMapIterator provides for iterating over an IntMap.
+type IntMapIterator[I integer, V any] struct {
+	i *patricia.Iterator[V]
+}
+
+// Next moves to the next key-value pair or returns false if there are no more.
+func (i *IntMapIterator[I, V]) Next() bool {
+	return i.i.Next()
+}
+
+// Key returns the key for the current entry.
+func (i *IntMapIterator[I, V]) Key() I {
+	return keyInt[I](i.i.Key())
+}
+
+// Value returns the value for the current entry.
+func (i *IntMapIterator[I, V]) Value() V {
+	return i.i.Value()
+}
+
+// Entry returns the current key-value pair as two return values.
+func (i *IntMapIterator[I, V]) Entry() (key I, value V) {
+	return i.Key(), i.Value()
+}
@@ -0,0 +1,49 @@
+package frozen_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+)
+
+var intMapBenchSink int
+
+func BenchmarkIntMap(b *testing.B) {
+	for _, n := range []int{100, 10_000, 1_000_000} {
+		r := rand.New(rand.NewSource(0)) //nolint:gosec
+		keys := make([]int, n)
+		var mb frozen.MapBuilder[int, int]
+		var im frozen.IntMap[int, int]
+		for i := range keys {
+			keys[i] = r.Int()
+			mb.Put(keys[i], i)
+			im = im.With(keys[i], i)
+		}
+		m := mb.Finish()
+
+		b.Run(fmt.Sprintf("Get/Map/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				v, _ := m.Get(keys[i%n])
+				intMapBenchSink += v
+			}
+		})
+		b.Run(fmt.Sprintf("Get/IntMap/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				v, _ := im.Get(keys[i%n])
+				intMapBenchSink += v
+			}
+		})
+		b.Run(fmt.Sprintf("Update/Map/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.Update(m.Where(func(k, _ int) bool { return k%2 == 0 }))
+			}
+		})
+		b.Run(fmt.Sprintf("Update/IntMap/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				im.Update(im.Where(func(k, _ int) bool { return k%2 == 0 }))
+			}
+		})
+	}
+}
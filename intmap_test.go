@@ -0,0 +1,174 @@
+package frozen_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+)
+
+func TestIntMapEmpty(t *testing.T) {
+	t.Parallel()
+
+	var m frozen.IntMap[int, string]
+	test.True(t, m.IsEmpty())
+	test.Equal(t, 0, m.Count())
+	test.Equal(t, "()", m.String())
+	test.Panic(t, func() { m.Min() })
+	test.Panic(t, func() { m.Max() })
+	test.Panic(t, func() { m.Any() })
+	test.Panic(t, func() { m.MustGet(1) })
+	test.False(t, m.Range().Next())
+
+	m = m.With(1, "a")
+	test.False(t, m.IsEmpty())
+	test.Equal(t, "a", m.MustGet(1))
+	m = m.Without(1)
+	test.True(t, m.IsEmpty())
+	test.True(t, m.Equal(frozen.IntMap[int, string]{}))
+}
+
+func TestIntMapBasics(t *testing.T) {
+	t.Parallel()
+
+	m := frozen.NewIntMap(frozen.KV(3, "c"), frozen.KV(-1, "z"), frozen.KV(1, "a"), frozen.KV(3, "C"))
+	test.Equal(t, 3, m.Count())
+	test.Equal(t, "(-1: z, 1: a, 3: C)", m.String())
+	test.Equal(t, "z", m.GetElse(-1, "?"))
+	test.Equal(t, "?", m.GetElse(2, "?"))
+	test.Equal(t, "!", m.GetElseFunc(2, func() string { return "!" }))
+	test.True(t, m.Keys().Equal(frozen.NewIntSet(-1, 1, 3)))
+	test.True(t, m.Values().Equal(frozen.NewSet("z", "a", "C")))
+	test.Equal(t, "(1: a, 3: C)", m.Project(1, 2, 3).String())
+	test.Equal(t, "(-1: z, 3: C)", m.Where(func(k int, _ string) bool { return k != 1 }).String())
+	test.Equal(t, "(-1: -1, 1: 1, 3: 3)", frozen.IntMapMap(m, func(k int, _ string) int { return k }).String())
+	test.Equal(t, 1, m.Without(2).Without(1).Without(3).Count())
+	test.Equal(t, 4, m.With(0, "0").With(0, "o").Count())
+
+	k, v := m.Min()
+	test.Equal(t, -1, k)
+	test.Equal(t, "z", v)
+	k, v = m.Max()
+	test.Equal(t, 3, k)
+	test.Equal(t, "C", v)
+
+	squares := frozen.NewIntMapFromKeys(frozen.NewIntSet(1, 2, 3), func(k int) int { return k * k })
+	test.Equal(t, "(1: 1, 2: 4, 3: 9)", squares.String())
+	test.True(t, squares.Same(frozen.NewIntMap(frozen.KV(3, 9), frozen.KV(2, 4), frozen.KV(1, 1))))
+	test.False(t, squares.Same(squares.With(2, 5)))
+	test.False(t, squares.Same(frozen.NewMap(frozen.KV(1, 1))))
+	test.Equal(t, frozen.NewMap(frozen.KV(1, 1), frozen.KV(2, 4), frozen.KV(3, 9)).Hash(0), squares.Hash(0))
+}
+
+func TestIntMapAlgebra(t *testing.T) {
+	t.Parallel()
+
+	t.Run("int", func(t *testing.T) { t.Parallel(); assertIntMapAlgebra[int](t, -1<<63, 1<<63-1) })
+	t.Run("int8", func(t *testing.T) { t.Parallel(); assertIntMapAlgebra[int8](t, -1<<7, 1<<7-1) })
+	t.Run("int32", func(t *testing.T) { t.Parallel(); assertIntMapAlgebra[int32](t, -1<<31, 1<<31-1) })
+	t.Run("uint8", func(t *testing.T) { t.Parallel(); assertIntMapAlgebra[uint8](t, 0, 1<<8-1) })
+	t.Run("uint64", func(t *testing.T) { t.Parallel(); assertIntMapAlgebra[uint64](t, 0, 1<<64-1) })
+}
+
+// assertIntMapAlgebra checks IntMap operations against Map on random maps with
+// keys drawn from near the ends and the middle of [lo, hi].
+func assertIntMapAlgebra[I testInteger](t *testing.T, lo, hi I) {
+	t.Helper()
+
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	randomKey := func() I {
+		switch r.Intn(3) {
+		case 0:
+			return lo + I(r.Intn(100))
+		case 1:
+			return hi - I(r.Intn(100))
+		default:
+			return lo/2 + hi/2 + I(r.Intn(100))
+		}
+	}
+	randomMaps := func() (frozen.IntMap[I, int], frozen.Map[I, int]) {
+		var im frozen.IntMap[I, int]
+		var m frozen.Map[I, int]
+		for n := r.Intn(200); n > 0; n-- {
+			k, v := randomKey(), r.Intn(1000)
+			im = im.With(k, v)
+			m = m.With(k, v)
+		}
+		return im, m
+	}
+	assertSame := func(expected frozen.Map[I, int], actual frozen.IntMap[I, int], op string) bool {
+		t.Helper()
+		if !test.Equal(t, expected.Count(), actual.Count(), op) {
+			return false
+		}
+		var keys []I
+		for i := actual.Range(); i.Next(); {
+			keys = append(keys, i.Key())
+			if v, has := expected.Get(i.Key()); !test.True(t, has, op) || !test.Equal(t, v, i.Value(), op) {
+				return false
+			}
+		}
+		return test.True(t, sort.SliceIsSorted(keys, func(i, j int) bool { return keys[i] < keys[j] }), op)
+	}
+	sum := func(_ I, a, b int) int { return a + b }
+
+	rounds := 100
+	if testing.Short() {
+		rounds = 10
+	}
+	for round := 0; round < rounds; round++ {
+		a, ma := randomMaps()
+		b, mb := randomMaps()
+		if !assertSame(ma, a, "build") ||
+			!assertSame(ma.Merge(mb, sum), a.Merge(b, sum), "merge") ||
+			!assertSame(ma.Update(mb), a.Update(b), "update") ||
+			!assertSame(ma.Where(func(k I, _ int) bool { return mb.Has(k) }), a.Intersection(b), "intersection") ||
+			!assertSame(ma.Where(func(k I, _ int) bool { return !mb.Has(k) }), a.Difference(b), "difference") {
+			break
+		}
+		test.True(t, a.Merge(b, sum).Equal(b.Merge(a, sum)))
+		test.True(t, a.Difference(b).Update(a.Intersection(b)).Equal(a))
+
+		k := randomKey()
+		assertSame(ma.With(k, -1), a.With(k, -1), "with")
+		assertSame(ma.Without(k), a.Without(k), "without")
+		rebuilt := a.Without(k).With(k, 0).Update(a).Where(func(key I, _ int) bool { return ma.Has(key) })
+		assertSame(ma, frozen.NewIntMap(intMapEntries(rebuilt.Descending())...), "round trip")
+
+		below, above := a.Split(k)
+		assertSame(ma.Where(func(key I, _ int) bool { return key < k }), below, "split below")
+		assertSame(ma.Where(func(key I, _ int) bool { return key >= k }), above, "split above")
+
+		k2 := randomKey()
+
+		// Maps derived from one another share most of their structure.
+		c, mc := a.With(k, -1).Without(k2), ma.With(k, -1).Without(k2)
+		assertSame(ma.Update(mc), a.Update(c), "update derived")
+		assertSame(ma.Where(func(key I, _ int) bool { return mc.Has(key) }), a.Intersection(c), "intersection derived")
+		assertSame(ma.Where(func(key I, _ int) bool { return !mc.Has(key) }), a.Difference(c), "difference derived")
+		assertSame(ma.Merge(ma, sum), a.Merge(a, sum), "merge self")
+
+		var between []I
+		for i := a.RangeBetween(k, k2); i.Next(); {
+			between = append(between, i.Key())
+		}
+		test.Equal(t, ma.Where(func(key I, _ int) bool { return k <= key && key < k2 }).Count(), len(between))
+
+		if !a.IsEmpty() {
+			first, _ := a.Min()
+			last, _ := a.Max()
+			test.Equal(t, first, intMapEntries(below.Update(above).Range())[0].Key)
+			test.Equal(t, last, intMapEntries(a.Descending())[0].Key)
+		}
+	}
+}
+
+func intMapEntries[I testInteger, V any](i *frozen.IntMapIterator[I, V]) []frozen.KeyValue[I, V] {
+	var kvs []frozen.KeyValue[I, V]
+	for i.Next() {
+		kvs = append(kvs, frozen.KV(i.Entry()))
+	}
+	return kvs
+}
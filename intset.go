@@ -2,49 +2,71 @@ package frozen
 
 import (
 	"fmt"
-	"math/bits"
+	"unsafe"
 
 	"github.com/arr-ai/hash"
 
 	"github.com/arr-ai/frozen/internal/pkg/fu"
-	internalIterator "github.com/arr-ai/frozen/internal/pkg/iterator"
+	"github.com/arr-ai/frozen/internal/pkg/patricia"
 )
 
+// IntSet is a persistent set of integers. Elements are grouped into chunks of
+// 2^16 values, each held in a compressed container (see intset_container.go).
+// The containers are kept in a PATRICIA trie ordered by chunk, so iteration is
+// in ascending order and IntSets share unchanged chunks.
 type IntSet[I integer] struct {
-	data  Map[I, cellMask]
-	count int
+	chunks patricia.Trie[container]
+	count  int
 }
 
-type cellMask uint64
+// signBit returns the bit to flip in chunk keys so that negative values of I
+// sort before non-negative ones.
+func signBit[I integer]() uint64 {
+	if I(0)-1 < 0 {
+		return 1 << 63
+	}
+	return 0
+}
 
-const (
-	cellShift = 6
-	cellBits  = 1 << cellShift
-)
+// intBounds returns the least and greatest values of I.
+func intBounds[I integer]() (lo, hi I) {
+	size := 8 * unsafe.Sizeof(lo)
+	if signBit[I]() != 0 {
+		return I(int64(-1) << (size - 1)), I(uint64(1)<<(size-1) - 1)
+	}
+	return 0, I(^uint64(0) >> (64 - size))
+}
 
-func locateCell[I integer](i I) (cell I, bitMask cellMask) {
-	return i >> cellShift, cellMask(1) << uint(i%cellBits)
+// locateChunk returns the trie key of the chunk containing i and the low bits
+// of i within the chunk. The shift floors negative values, so low is always
+// the distance from the start of the chunk.
+func locateChunk[I integer](i I) (key uint64, low uint16) {
+	// Converting to uint64 sign-extends, so the arithmetic is done in 64 bits
+	// for all integer types.
+	x := uint64(i)
+	if sign := signBit[I](); sign != 0 {
+		return uint64(int64(x)>>chunkShift) ^ sign, uint16(x)
+	}
+	return x >> chunkShift, uint16(x)
+}
+
+// chunkElem is the inverse of locateChunk.
+func chunkElem[I integer](key uint64, low uint16) I {
+	return I((key^signBit[I]())<<chunkShift | uint64(low))
 }
 
 // NewIntSet returns an IntSet with the values provided.
 func NewIntSet[I integer](is ...I) IntSet[I] {
-	m := map[I]cellMask{}
+	b := NewIntSetBuilder[I](len(is))
 	for _, i := range is {
-		cellIndex, bitMask := locateCell(i)
-		m[cellIndex] = m[cellIndex] | bitMask
+		b.Add(i)
 	}
-	b := NewMapBuilder[I, cellMask](len(m))
-	count := 0
-	for cellIndex, cell := range m {
-		b.Put(cellIndex, cell)
-		count += cell.count()
-	}
-	return IntSet[I]{data: b.Finish(), count: count}
+	return b.Finish()
 }
 
 // IsEmpty returns true if there is no values in s and false otherwise.
 func (s IntSet[I]) IsEmpty() bool {
-	return s.data.IsEmpty()
+	return s.count == 0
 }
 
 // Count returns the number of elements in IntSet.
@@ -52,15 +74,22 @@ func (s IntSet[I]) Count() int {
 	return s.count
 }
 
-// Range returns the iterator for IntSet.
+// Cardinality returns the number of elements in IntSet. It is the same as
+// Count.
+func (s IntSet[I]) Cardinality() int {
+	return s.count
+}
+
+// Range returns the iterator for IntSet. Elements are visited in ascending
+// order.
 func (s IntSet[I]) Range() Iterator[I] {
-	return &intSetIterator[I]{
-		cellIter: s.data.Range(),
-		cell:     1,
+	if s.IsEmpty() {
+		return newIntSetIterator(s, 0, 0, false)
 	}
+	return newIntSetIterator(s, s.Min(), s.Max(), false)
 }
 
-// Elements returns all the values of IntSet.
+// Elements returns all the values of IntSet in ascending order.
 func (s IntSet[I]) Elements() []I {
 	result := make([]I, 0, s.Count())
 	for i := s.Range(); i.Next(); {
@@ -71,11 +100,13 @@ func (s IntSet[I]) Elements() []I {
 
 // func (s IntSet[I]) OrderedElements(less Less) []I {}
 
-// Any returns a random value from s.
+// Any returns an arbitrary element of s, which is currently its least. It
+// panics if s is empty.
 func (s IntSet[I]) Any() I {
-	cellIndex, cell := s.data.Any()
-	bit := internalIterator.BitIterator(cell).Index()
-	return cellIndex<<cellShift + I(bit)
+	if s.IsEmpty() {
+		panic("IntSet.Any(): empty set")
+	}
+	return s.Min()
 }
 
 // func (s IntSet[I]) AnyN(n I) IntSet                  {}
@@ -83,15 +114,139 @@ func (s IntSet[I]) Any() I {
 // func (s IntSet[I]) First(less Less) I                {}
 // func (s IntSet[I]) FirstN(n I, less Less) IntSet     {}
 
+// Min returns the least element of s. It panics if s is empty.
+func (s IntSet[I]) Min() I {
+	key, c, ok := s.chunks.Min()
+	if !ok {
+		panic("IntSet.Min(): empty set")
+	}
+	return chunkElem[I](key, c.min())
+}
+
+// Max returns the greatest element of s. It panics if s is empty.
+func (s IntSet[I]) Max() I {
+	key, c, ok := s.chunks.Max()
+	if !ok {
+		panic("IntSet.Max(): empty set")
+	}
+	return chunkElem[I](key, c.max())
+}
+
+// Rank returns the number of elements of s less than i.
+func (s IntSet[I]) Rank(i I) int {
+	key, low := locateChunk(i)
+	n := 0
+	for r := s.chunks.Between(0, key, false); r.Next(); {
+		if c := r.Value(); r.Key() == key {
+			n += c.rank(low)
+		} else {
+			n += c.cardinality()
+		}
+	}
+	return n
+}
+
+// Select returns the element of s with the given rank, i.e., the k-th least
+// element, counting from zero. It panics if k is out of range.
+func (s IntSet[I]) Select(k int) I {
+	if k < 0 || k >= s.count {
+		panic(fmt.Sprintf("IntSet.Select(%d): out of range", k))
+	}
+	r := s.chunks.Range()
+	for r.Next() {
+		if n := r.Value().cardinality(); k >= n {
+			k -= n
+			continue
+		}
+		break
+	}
+	return chunkElem[I](r.Key(), r.Value().selectAt(k))
+}
+
+// Ascending returns an iterator over the elements of s in ascending order. It
+// is the same as Range.
+func (s IntSet[I]) Ascending() Iterator[I] {
+	return s.Range()
+}
+
+// Descending returns an iterator over the elements of s in descending order.
+func (s IntSet[I]) Descending() Iterator[I] {
+	if s.IsEmpty() {
+		return newIntSetIterator(s, 0, 0, true)
+	}
+	return newIntSetIterator(s, s.Min(), s.Max(), true)
+}
+
+// RangeBetween returns an iterator over the elements of s in [lo, hi), in
+// ascending order. Only the chunks that overlap the range are visited.
+func (s IntSet[I]) RangeBetween(lo, hi I) Iterator[I] {
+	if lo >= hi {
+		return newIntSetIterator(IntSet[I]{}, 0, 0, false)
+	}
+	return newIntSetIterator(s, lo, hi-1, false)
+}
+
+// CountBetween returns the number of elements of s in [lo, hi).
+func (s IntSet[I]) CountBetween(lo, hi I) int {
+	if lo >= hi {
+		return 0
+	}
+	loKey, loLow := locateChunk(lo)
+	hiKey, hiLow := locateChunk(hi - 1)
+	n := 0
+	for r := s.chunks.Between(loKey, hiKey, false); r.Next(); {
+		c := r.Value()
+		count := c.cardinality()
+		if r.Key() == hiKey {
+			count = c.rank(hiLow)
+			if c.has(hiLow) {
+				count++
+			}
+		}
+		if r.Key() == loKey {
+			count -= c.rank(loLow)
+		}
+		n += count
+	}
+	return n
+}
+
+// Next returns the least element of s greater than i, or false if there is
+// none.
+func (s IntSet[I]) Next(i I) (_ I, _ bool) {
+	if s.IsEmpty() || i >= s.Max() {
+		return
+	}
+	r := newIntSetIterator(s, i+1, s.Max(), false)
+	r.Next()
+	return r.Value(), true
+}
+
+// Prev returns the greatest element of s less than i, or false if there is
+// none.
+func (s IntSet[I]) Prev(i I) (_ I, _ bool) {
+	if s.IsEmpty() || i <= s.Min() {
+		return
+	}
+	r := newIntSetIterator(s, s.Min(), i-1, true)
+	r.Next()
+	return r.Value(), true
+}
+
 // String returns a string representation of IntSet.
 func (s IntSet[I]) String() string {
 	return fu.String(s)
 }
 
-// Format formats IntSet.
+// Format formats IntSet. The %+v verb shows the containers of each chunk.
 func (s IntSet[I]) Format(f fmt.State, verb rune) {
 	if verb == 'v' && f.Flag('+') {
-		fu.Fprint(f, s.data)
+		fu.WriteString(f, "{")
+		for i, r := 0, s.chunks.Range(); r.Next(); i++ {
+			fu.Comma(f, i)
+			fmt.Fprintf(f, "%d: %s", chunkElem[I](r.Key(), 0), containerString(r.Value()))
+		}
+		fu.WriteString(f, "}")
 		return
 	}
 
@@ -117,7 +272,7 @@ func (s IntSet[I]) EqualSet(t IntSet[I]) bool {
 
 // Equal returns true if both IntSets are equal.
 func (s IntSet[I]) Equal(t IntSet[I]) bool {
-	return s.data.Equal(t.data)
+	return s.count == t.count && s.chunks.Equal(t.chunks, containerEqual)
 }
 
 // Equal returns true if t is an IntSet and a and b are equal.
@@ -130,9 +285,24 @@ func (s IntSet[I]) Same(t any) bool {
 
 // IsSubsetOf returns true if s is a subset of t and false otherwise.
 func (s IntSet[I]) IsSubsetOf(t IntSet[I]) bool {
-	for r := s.data.Range(); r.Next(); {
-		sCell := r.Value()
-		if tCell, has := t.data.Get(r.Key()); !has || sCell&^tCell != 0 {
+	if s.count > t.count {
+		return false
+	}
+	for r := s.chunks.Range(); r.Next(); {
+		if tc, has := t.chunks.Get(r.Key()); !has || !containerIsSubset(r.Value(), tc) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsDisjoint returns true iff s and t have no elements in common.
+func (s IntSet[I]) IsDisjoint(t IntSet[I]) bool {
+	if s.count > t.count {
+		s, t = t, s
+	}
+	for r := s.chunks.Range(); r.Next(); {
+		if tc, has := t.chunks.Get(r.Key()); has && containerIntersects(r.Value(), tc) {
 			return false
 		}
 	}
@@ -141,117 +311,137 @@ func (s IntSet[I]) IsSubsetOf(t IntSet[I]) bool {
 
 // Has returns true if value exists in the IntSet and false otherwise.
 func (s IntSet[I]) Has(val I) bool {
-	cell, _, bitMask := s.locate(val)
-	return cell&bitMask != 0
+	key, low := locateChunk(val)
+	c, has := s.chunks.Get(key)
+	return has && c.has(low)
 }
 
 // With returns a new IntSet with the values of s and the provided values.
 func (s IntSet[I]) With(i I) IntSet[I] {
-	if cell, cellIndex, bitMask := s.locate(i); cell&bitMask == 0 {
-		s.data = s.data.With(cellIndex, cell|bitMask)
-		s.count++
+	key, low := locateChunk(i)
+	c, has := s.chunks.Get(key)
+	switch {
+	case !has:
+		s.chunks = s.chunks.With(key, arrayContainer{low})
+	case !c.has(low):
+		s.chunks = s.chunks.With(key, c.with(low))
+	default:
+		return s
 	}
+	s.count++
 	return s
 }
 
 // Without returns an IntSet without the provided values.
 func (s IntSet[I]) Without(i I) IntSet[I] {
-	if cell, cellIndex, bitMask := s.locate(i); cell&bitMask != 0 {
-		if cell &^= bitMask; cell != 0 {
-			// TODO: optimize this so it doesn't do With many times
-			s.data = s.data.With(cellIndex, cell)
-		} else {
-			s.data = s.data.Without(cellIndex)
-		}
-		s.count--
+	key, low := locateChunk(i)
+	c, has := s.chunks.Get(key)
+	switch {
+	case !has || !c.has(low):
+		return s
+	case c.cardinality() == 1:
+		s.chunks = s.chunks.Without(key)
+	default:
+		s.chunks = s.chunks.With(key, c.without(low))
 	}
+	s.count--
 	return s
 }
 
 // Where returns an IntSet whose values fulfill the provided condition.
 func (s IntSet[I]) Where(pred func(elem I) bool) IntSet[I] {
-	// TODO: find a way that works more on block level or maybe make IntSetBuilder?
-	var b MapBuilder[I, cellMask]
+	var entries []patricia.Entry[container]
 	count := 0
-	for r := s.data.Range(); r.Next(); {
-		cellIndex, cell := r.Entry()
-		cellOffset := cellIndex * cellBits
-		var newCell cellMask
-		for cell != 0 {
-			maskIndex := I(cell.index())
-			if pred(cellOffset + maskIndex) {
-				newCell |= cellMask(1) << maskIndex
+	for r := s.chunks.Range(); r.Next(); {
+		key := r.Key()
+		var m *bitmapContainer
+		var a arrayContainer
+		for next := r.Value().iterate(0, chunkSize-1, false); ; {
+			low, ok := next()
+			if !ok {
+				break
 			}
-			cell &= (cell - 1)
+			if pred(chunkElem[I](key, low)) {
+				if m != nil {
+					m.add(low)
+				} else if a = append(a, low); len(a) > arrayMaxCount {
+					m = a.toBitmap()
+				}
+			}
+		}
+		var c container = a
+		if m != nil {
+			c = m
 		}
-		if newCell != 0 {
-			b.Put(cellIndex, newCell)
-			count += newCell.count()
+		if c = optimize(c); c != nil {
+			entries = append(entries, patricia.Entry[container]{Key: key, Value: c})
+			count += c.cardinality()
 		}
 	}
-	return IntSet[I]{data: b.Finish(), count: count}
+	return IntSet[I]{chunks: patricia.FromSorted(entries), count: count}
 }
 
 // Map returns an IntSet with whose values are mapped from s.
 func (s IntSet[I]) Map(f func(elem I) I) IntSet[I] {
-	arr := make([]I, 0, s.count)
+	b := NewIntSetBuilder[I](s.count)
 	for i := s.Range(); i.Next(); {
-		arr = append(arr, f(i.Value()))
+		b.Add(f(i.Value()))
 	}
-	return NewIntSet(arr...)
+	return b.Finish()
 }
 
 // Intersection returns an IntSet whose values exists in s and t.
 func (s IntSet[I]) Intersection(t IntSet[I]) IntSet[I] {
-	var intersectMap MapBuilder[I, cellMask]
 	count := 0
-	for tCell := t.data.Range(); tCell.Next(); {
-		if sCell, has := s.data.Get(tCell.Key()); has {
-			if iCell := sCell & tCell.Value(); iCell != 0 {
-				intersectMap.Put(tCell.Key(), iCell)
-				count += iCell.count()
-			}
+	chunks := s.chunks.Intersection(t.chunks, func(_ uint64, a, b container) (container, bool) {
+		c := containerIntersection(a, b)
+		if c == nil {
+			return nil, false
 		}
-	}
-	return IntSet[I]{data: intersectMap.Finish(), count: count}
+		count += c.cardinality()
+		return c, true
+	})
+	return IntSet[I]{chunks: chunks, count: count}
 }
 
 // Union returns an integer set that is a union of s and t.
 func (s IntSet[I]) Union(t IntSet[I]) IntSet[I] {
-	unionMap := s.data
-	count := s.count
-	var unionCell cellMask
-	for tCell := t.data.Range(); tCell.Next(); {
-		if sCell, has := s.data.Get(tCell.Key()); has {
-			unionCell = sCell | tCell.Value()
-			count += unionCell.diffCount(sCell)
-		} else {
-			unionCell = tCell.Value()
-			count += unionCell.count()
-		}
-		unionMap = unionMap.With(tCell.Key(), unionCell)
-	}
-	return IntSet[I]{data: unionMap, count: count}
-}
-
-func (s IntSet[I]) locate(i I) (cell cellMask, cellIndex I, bitMask cellMask) {
-	cellIndex, bitMask = locateCell(i)
-	cell, _ = s.data.Get(cellIndex)
-	return
-}
-
-func (c cellMask) diffCount(c2 cellMask) int {
-	return (c ^ c2).count()
+	count := s.count + t.count
+	chunks := s.chunks.Union(t.chunks, func(_ uint64, a, b container) (container, bool) {
+		c := containerUnion(a, b)
+		count -= a.cardinality() + b.cardinality() - c.cardinality()
+		return c, true
+	})
+	return IntSet[I]{chunks: chunks, count: count}
 }
 
-func (c cellMask) count() int {
-	return bits.OnesCount64(uint64(c))
-}
-
-func (c cellMask) index() int {
-	return bits.TrailingZeros64(uint64(c))
+// Difference returns an IntSet with the values of s that aren't in t.
+func (s IntSet[I]) Difference(t IntSet[I]) IntSet[I] {
+	count := s.count
+	chunks := s.chunks.Difference(t.chunks, func(_ uint64, a, b container) (container, bool) {
+		c := containerDifference(a, b)
+		if c == nil {
+			count -= a.cardinality()
+			return nil, false
+		}
+		count -= a.cardinality() - c.cardinality()
+		return c, true
+	})
+	return IntSet[I]{chunks: chunks, count: count}
 }
 
-func (c cellMask) next() cellMask {
-	return c & (c - 1)
+// SymmetricDifference returns an IntSet with the values that are in either s
+// or t but not both.
+func (s IntSet[I]) SymmetricDifference(t IntSet[I]) IntSet[I] {
+	count := s.count + t.count
+	chunks := s.chunks.Union(t.chunks, func(_ uint64, a, b container) (container, bool) {
+		count -= a.cardinality() + b.cardinality()
+		c := containerSymmetricDifference(a, b)
+		if c == nil {
+			return nil, false
+		}
+		count += c.cardinality()
+		return c, true
+	})
+	return IntSet[I]{chunks: chunks, count: count}
 }
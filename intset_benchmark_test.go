@@ -2,6 +2,8 @@ package frozen_test
 
 import (
 	"fmt"
+	"math/rand"
+	"runtime"
 	"testing"
 
 	"github.com/arr-ai/frozen"
@@ -81,3 +83,119 @@ func BenchmarkIntSet(b *testing.B) {
 		})
 	}
 }
+
+// intSetLayouts generates n elements with different densities.
+var intSetLayouts = []struct {
+	name     string
+	generate func(r *rand.Rand, n int) []int
+}{
+	{"dense", func(_ *rand.Rand, n int) []int {
+		return frozen.Iota(n).Elements()
+	}},
+	{"sparse", func(r *rand.Rand, n int) []int {
+		arr := make([]int, 0, n)
+		for i := 0; i < n; i++ {
+			arr = append(arr, r.Intn(1<<32))
+		}
+		return arr
+	}},
+	// scattered data has about one element per 2^16 chunk, the worst case for
+	// per-chunk containers.
+	{"scattered", func(r *rand.Rand, n int) []int {
+		arr := make([]int, 0, n)
+		for i := 0; i < n; i++ {
+			arr = append(arr, r.Intn(1<<48))
+		}
+		return arr
+	}},
+	{"clustered", func(r *rand.Rand, n int) []int {
+		const cluster = 1000
+		arr := make([]int, 0, n)
+		for len(arr) < n {
+			start := r.Intn(1 << 40)
+			for i := 0; i < cluster && len(arr) < n; i++ {
+				arr = append(arr, start+i)
+			}
+		}
+		return arr
+	}},
+}
+
+// heapBytes returns the growth of the live heap due to the value f returns.
+func heapBytes(f func() any) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	v := f()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(v)
+	if after.HeapAlloc < before.HeapAlloc {
+		return 0
+	}
+	return after.HeapAlloc - before.HeapAlloc
+}
+
+func BenchmarkIntSetLayout(b *testing.B) {
+	for _, layout := range intSetLayouts {
+		for _, n := range []int{10_000, 1_000_000} {
+			r := rand.New(rand.NewSource(0)) //nolint:gosec
+			arr := layout.generate(r, n)
+			other := layout.generate(r, n)
+			s, t := frozen.NewIntSet(arr...), frozen.NewIntSet(other...)
+			prefix := fmt.Sprintf("%s/%d/", layout.name, n)
+
+			b.Run(prefix+"New", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					frozen.NewIntSet(arr...)
+				}
+				bytes := heapBytes(func() any { return frozen.NewIntSet(arr...) })
+				b.ReportMetric(float64(bytes)/float64(n), "heap-B/elem")
+			})
+			b.Run(prefix+"NewSet", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					frozen.NewSet(arr...)
+				}
+				bytes := heapBytes(func() any { return frozen.NewSet(arr...) })
+				b.ReportMetric(float64(bytes)/float64(n), "heap-B/elem")
+			})
+			b.Run(prefix+"Has", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					s.Has(arr[i%n])
+				}
+			})
+			b.Run(prefix+"Union", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					s.Union(t)
+				}
+			})
+			b.Run(prefix+"Intersection", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					s.Intersection(t)
+				}
+			})
+			b.Run(prefix+"Difference", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					s.Difference(t)
+				}
+			})
+			b.Run(prefix+"Shift", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					s.Shift(1000)
+				}
+			})
+			b.Run(prefix+"ShiftByMap", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					s.Map(func(e int) int { return e + 1000 })
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package frozen
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/arr-ai/frozen/internal/pkg/patricia"
+)
+
+// IntSetBuilder[I] provides a more efficient way to build IntSets
+// incrementally. The zero value is an empty builder, ready to use.
+type IntSetBuilder[I integer] struct {
+	// chunks holds array and bitmap containers that the builder owns and
+	// updates in place. They are optimized by Finish.
+	chunks map[uint64]container
+	count  int
+}
+
+func NewIntSetBuilder[I integer](capacity int) *IntSetBuilder[I] {
+	return &IntSetBuilder[I]{chunks: make(map[uint64]container, capacity>>chunkShift)}
+}
+
+// Count returns the count of the IntSet that will be returned from Finish().
+func (b *IntSetBuilder[I]) Count() int {
+	return b.count
+}
+
+// Add adds i to the IntSet under construction.
+func (b *IntSetBuilder[I]) Add(i I) {
+	if b.chunks == nil {
+		b.chunks = map[uint64]container{}
+	}
+	key, low := locateChunk(i)
+	switch c := b.chunks[key].(type) {
+	case nil:
+		b.chunks[key] = arrayContainer{low}
+	case *bitmapContainer:
+		if !c.add(low) {
+			return
+		}
+	case arrayContainer:
+		j, has := c.search(low)
+		switch {
+		case has:
+			return
+		case len(c) == arrayMaxCount:
+			m := c.toBitmap()
+			m.add(low)
+			b.chunks[key] = m
+		default:
+			c = append(c, 0)
+			copy(c[j+1:], c[j:])
+			c[j] = low
+			b.chunks[key] = c
+		}
+	}
+	b.count++
+}
+
+// Remove removes i from the IntSet under construction.
+func (b *IntSetBuilder[I]) Remove(i I) {
+	key, low := locateChunk(i)
+	switch c := b.chunks[key].(type) {
+	case nil:
+		return
+	case *bitmapContainer:
+		if !c.remove(low) {
+			return
+		}
+	case arrayContainer:
+		j, has := c.search(low)
+		if !has {
+			return
+		}
+		if len(c) == 1 {
+			delete(b.chunks, key)
+		} else {
+			b.chunks[key] = append(c[:j], c[j+1:]...)
+		}
+	}
+	b.count--
+}
+
+// Has returns true iff i has been added to the IntSet under construction.
+func (b *IntSetBuilder[I]) Has(i I) bool {
+	key, low := locateChunk(i)
+	c := b.chunks[key]
+	return c != nil && c.has(low)
+}
+
+// Finish returns an IntSet containing all elements added since the
+// IntSetBuilder[I] was initialised or the last call to Finish.
+func (b *IntSetBuilder[I]) Finish() IntSet[I] {
+	s := b.borrow()
+	*b = IntSetBuilder[I]{}
+	return s
+}
+
+func (b *IntSetBuilder[I]) borrow() IntSet[I] {
+	entries := make([]patricia.Entry[container], 0, len(b.chunks))
+	for key, c := range b.chunks {
+		entries = append(entries, patricia.Entry[container]{Key: key, Value: optimize(c)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return IntSet[I]{chunks: patricia.FromSorted(entries), count: b.count}
+}
+
+func (b IntSetBuilder[I]) String() string {
+	return b.borrow().String()
+}
+
+func (b IntSetBuilder[I]) Format(f fmt.State, verb rune) {
+	b.borrow().Format(f, verb)
+}
@@ -0,0 +1,883 @@
+package frozen
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// IntSet elements are split into chunks of 2^16 values. Each chunk is stored
+// in whichever of three container types is smallest for its contents, as in
+// Roaring bitmaps: a sorted array of up to arrayMaxCount elements, a bitmap, or
+// a sorted list of runs. Containers are immutable once they are in an IntSet,
+// so IntSets share unchanged containers. Only IntSetBuilder mutates
+// containers, and only those it owns.
+const (
+	chunkShift    = 16
+	chunkSize     = 1 << chunkShift
+	bitmapWords   = chunkSize / 64
+	arrayMaxCount = 4096
+)
+
+type container interface {
+	cardinality() int
+	has(x uint16) bool
+
+	// with and without return the receiver if there is nothing to change.
+	with(x uint16) container
+	without(x uint16) container
+
+	min() uint16
+	max() uint16
+
+	// rank returns the number of elements less than x.
+	rank(x uint16) int
+
+	// selectAt returns the element with the given rank.
+	selectAt(k int) uint16
+
+	runCount() int
+	toArray() arrayContainer
+	toBitmap() *bitmapContainer
+	toRuns() runContainer
+
+	// iterate returns a function that yields the elements in [lo, hi] in
+	// ascending order, or descending if reverse is true. lo must not exceed hi.
+	iterate(lo, hi uint16, reverse bool) func() (uint16, bool)
+
+	sizeInBytes() int
+}
+
+// optimize returns the smallest representation of c, or nil if c is empty.
+func optimize(c container) container {
+	card := c.cardinality()
+	if card == 0 {
+		return nil
+	}
+	arrayBytes := 2 * card
+	if card > arrayMaxCount {
+		arrayBytes = bitmapWords * 8
+	}
+	switch {
+	case 4*c.runCount() < arrayBytes:
+		return c.toRuns()
+	case card <= arrayMaxCount:
+		return c.toArray()
+	default:
+		return c.toBitmap()
+	}
+}
+
+func containerEqual(a, b container) bool {
+	return a.cardinality() == b.cardinality() && containerIsSubset(a, b)
+}
+
+func containerIsSubset(a, b container) bool {
+	if a.cardinality() > b.cardinality() {
+		return false
+	}
+	if a, is := a.(arrayContainer); is {
+		for _, x := range a {
+			if !b.has(x) {
+				return false
+			}
+		}
+		return true
+	}
+	return containerDifference(a, b) == nil
+}
+
+func containerIntersects(a, b container) bool {
+	if _, is := b.(arrayContainer); is {
+		a, b = b, a
+	}
+	if a, is := a.(arrayContainer); is {
+		for _, x := range a {
+			if b.has(x) {
+				return true
+			}
+		}
+		return false
+	}
+	return containerIntersection(a, b) != nil
+}
+
+// The following operations return nil for empty results.
+
+func containerUnion(a, b container) container {
+	if a, is := a.(arrayContainer); is {
+		if b, is := b.(arrayContainer); is && len(a)+len(b) <= arrayMaxCount {
+			return mergeArrays(a, b, true, true, true)
+		}
+	}
+	if a, is := a.(runContainer); is {
+		if b, is := b.(runContainer); is {
+			return optimize(unionRuns(a, b))
+		}
+	}
+	if _, is := b.(*bitmapContainer); is {
+		a, b = b, a
+	}
+	m := mutableBitmap(a)
+	m.or(b)
+	return optimize(m)
+}
+
+func containerIntersection(a, b container) container {
+	if _, is := b.(arrayContainer); is {
+		a, b = b, a
+	}
+	if a, is := a.(arrayContainer); is {
+		return filterArray(a, func(x uint16) bool { return b.has(x) })
+	}
+	if a, is := a.(runContainer); is {
+		if b, is := b.(runContainer); is {
+			return optimize(intersectRuns(a, b))
+		}
+	}
+	m := mutableBitmap(a)
+	m.and(b)
+	return optimize(m)
+}
+
+func containerDifference(a, b container) container {
+	if a, is := a.(arrayContainer); is {
+		return filterArray(a, func(x uint16) bool { return !b.has(x) })
+	}
+	m := mutableBitmap(a)
+	m.andNot(b)
+	return optimize(m)
+}
+
+func containerSymmetricDifference(a, b container) container {
+	if a, is := a.(arrayContainer); is {
+		if b, is := b.(arrayContainer); is && len(a)+len(b) <= arrayMaxCount {
+			return mergeArrays(a, b, true, false, true)
+		}
+	}
+	m := mutableBitmap(a)
+	m.xor(b)
+	return optimize(m)
+}
+
+// mutableBitmap returns a bitmap copy of c that the caller owns.
+func mutableBitmap(c container) *bitmapContainer {
+	if m, is := c.(*bitmapContainer); is {
+		return m.clone()
+	}
+	return c.toBitmap()
+}
+
+// arrayContainer holds up to arrayMaxCount elements in ascending order.
+type arrayContainer []uint16
+
+func (a arrayContainer) search(x uint16) (int, bool) {
+	i := sort.Search(len(a), func(i int) bool { return a[i] >= x })
+	return i, i < len(a) && a[i] == x
+}
+
+func (a arrayContainer) cardinality() int {
+	return len(a)
+}
+
+func (a arrayContainer) has(x uint16) bool {
+	_, has := a.search(x)
+	return has
+}
+
+func (a arrayContainer) with(x uint16) container {
+	i, has := a.search(x)
+	switch {
+	case has:
+		return a
+	case len(a) == arrayMaxCount:
+		m := a.toBitmap()
+		m.add(x)
+		return m
+	}
+	result := make(arrayContainer, len(a)+1)
+	copy(result, a[:i])
+	result[i] = x
+	copy(result[i+1:], a[i:])
+	return result
+}
+
+func (a arrayContainer) without(x uint16) container {
+	i, has := a.search(x)
+	if !has {
+		return a
+	}
+	result := make(arrayContainer, 0, len(a)-1)
+	return append(append(result, a[:i]...), a[i+1:]...)
+}
+
+func (a arrayContainer) min() uint16 {
+	return a[0]
+}
+
+func (a arrayContainer) max() uint16 {
+	return a[len(a)-1]
+}
+
+func (a arrayContainer) rank(x uint16) int {
+	i, _ := a.search(x)
+	return i
+}
+
+func (a arrayContainer) selectAt(k int) uint16 {
+	return a[k]
+}
+
+func (a arrayContainer) runCount() int {
+	n := 0
+	for i, x := range a {
+		if i == 0 || a[i-1]+1 != x {
+			n++
+		}
+	}
+	return n
+}
+
+func (a arrayContainer) toArray() arrayContainer {
+	return a
+}
+
+func (a arrayContainer) toBitmap() *bitmapContainer {
+	m := &bitmapContainer{}
+	for _, x := range a {
+		m.words[x>>6] |= 1 << (x & 63)
+	}
+	m.card = len(a)
+	return m
+}
+
+func (a arrayContainer) toRuns() runContainer {
+	result := make(runContainer, 0, a.runCount())
+	for i, x := range a {
+		if i == 0 || a[i-1]+1 != x {
+			result = append(result, interval{start: x, last: x})
+		} else {
+			result[len(result)-1].last = x
+		}
+	}
+	return result
+}
+
+func (a arrayContainer) iterate(lo, hi uint16, reverse bool) func() (uint16, bool) {
+	start, _ := a.search(lo)
+	end, has := a.search(hi)
+	if has {
+		end++
+	}
+	if reverse {
+		i := end
+		return func() (uint16, bool) {
+			if i <= start {
+				return 0, false
+			}
+			i--
+			return a[i], true
+		}
+	}
+	i := start
+	return func() (uint16, bool) {
+		if i >= end {
+			return 0, false
+		}
+		i++
+		return a[i-1], true
+	}
+}
+
+func (a arrayContainer) sizeInBytes() int {
+	return 2 * len(a)
+}
+
+func filterArray(a arrayContainer, pred func(x uint16) bool) container {
+	var result arrayContainer
+	for _, x := range a {
+		if pred(x) {
+			result = append(result, x)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// mergeArrays merges a and b, keeping elements found only in a, in both, or
+// only in b, according to the flags.
+func mergeArrays(a, b arrayContainer, onlyA, both, onlyB bool) container {
+	result := make(arrayContainer, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			if onlyA {
+				result = append(result, a[i])
+			}
+			i++
+		case a[i] > b[j]:
+			if onlyB {
+				result = append(result, b[j])
+			}
+			j++
+		default:
+			if both {
+				result = append(result, a[i])
+			}
+			i++
+			j++
+		}
+	}
+	if onlyA {
+		result = append(result, a[i:]...)
+	}
+	if onlyB {
+		result = append(result, b[j:]...)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// bitmapContainer holds one bit for every value in a chunk.
+type bitmapContainer struct {
+	words [bitmapWords]uint64
+	card  int
+}
+
+func (m *bitmapContainer) clone() *bitmapContainer {
+	result := *m
+	return &result
+}
+
+func (m *bitmapContainer) add(x uint16) bool {
+	w, bit := &m.words[x>>6], uint64(1)<<(x&63)
+	if *w&bit != 0 {
+		return false
+	}
+	*w |= bit
+	m.card++
+	return true
+}
+
+func (m *bitmapContainer) remove(x uint16) bool {
+	w, bit := &m.words[x>>6], uint64(1)<<(x&63)
+	if *w&bit == 0 {
+		return false
+	}
+	*w &^= bit
+	m.card--
+	return true
+}
+
+func (m *bitmapContainer) recount() {
+	m.card = 0
+	for _, w := range m.words {
+		m.card += bits.OnesCount64(w)
+	}
+}
+
+func (m *bitmapContainer) or(c container) {
+	switch c := c.(type) {
+	case arrayContainer:
+		for _, x := range c {
+			m.words[x>>6] |= 1 << (x & 63)
+		}
+	default:
+		for i, w := range c.toBitmap().words {
+			m.words[i] |= w
+		}
+	}
+	m.recount()
+}
+
+func (m *bitmapContainer) and(c container) {
+	for i, w := range c.toBitmap().words {
+		m.words[i] &= w
+	}
+	m.recount()
+}
+
+func (m *bitmapContainer) andNot(c container) {
+	switch c := c.(type) {
+	case arrayContainer:
+		for _, x := range c {
+			m.words[x>>6] &^= 1 << (x & 63)
+		}
+	default:
+		for i, w := range c.toBitmap().words {
+			m.words[i] &^= w
+		}
+	}
+	m.recount()
+}
+
+func (m *bitmapContainer) xor(c container) {
+	switch c := c.(type) {
+	case arrayContainer:
+		for _, x := range c {
+			m.words[x>>6] ^= 1 << (x & 63)
+		}
+	default:
+		for i, w := range c.toBitmap().words {
+			m.words[i] ^= w
+		}
+	}
+	m.recount()
+}
+
+func (m *bitmapContainer) cardinality() int {
+	return m.card
+}
+
+func (m *bitmapContainer) has(x uint16) bool {
+	return m.words[x>>6]&(1<<(x&63)) != 0
+}
+
+func (m *bitmapContainer) with(x uint16) container {
+	if m.has(x) {
+		return m
+	}
+	result := m.clone()
+	result.add(x)
+	return result
+}
+
+func (m *bitmapContainer) without(x uint16) container {
+	if !m.has(x) {
+		return m
+	}
+	result := m.clone()
+	result.remove(x)
+	if result.card <= arrayMaxCount {
+		return result.toArray()
+	}
+	return result
+}
+
+func (m *bitmapContainer) min() uint16 {
+	for i, w := range m.words {
+		if w != 0 {
+			return uint16(i<<6 + bits.TrailingZeros64(w))
+		}
+	}
+	panic("empty container")
+}
+
+func (m *bitmapContainer) max() uint16 {
+	for i := len(m.words) - 1; i >= 0; i-- {
+		if w := m.words[i]; w != 0 {
+			return uint16(i<<6 + 63 - bits.LeadingZeros64(w))
+		}
+	}
+	panic("empty container")
+}
+
+func (m *bitmapContainer) rank(x uint16) int {
+	n := 0
+	for _, w := range m.words[:x>>6] {
+		n += bits.OnesCount64(w)
+	}
+	return n + bits.OnesCount64(m.words[x>>6]&(1<<(x&63)-1))
+}
+
+func (m *bitmapContainer) selectAt(k int) uint16 {
+	for i, w := range m.words {
+		if n := bits.OnesCount64(w); k >= n {
+			k -= n
+			continue
+		}
+		for ; k > 0; k-- {
+			w &= w - 1
+		}
+		return uint16(i<<6 + bits.TrailingZeros64(w))
+	}
+	panic("rank out of range")
+}
+
+func (m *bitmapContainer) runCount() int {
+	n := 0
+	var carry uint64
+	for _, w := range m.words {
+		n += bits.OnesCount64(w &^ (w<<1 | carry))
+		carry = w >> 63
+	}
+	return n
+}
+
+func (m *bitmapContainer) toArray() arrayContainer {
+	result := make(arrayContainer, 0, m.card)
+	for i, w := range m.words {
+		for ; w != 0; w &= w - 1 {
+			result = append(result, uint16(i<<6+bits.TrailingZeros64(w)))
+		}
+	}
+	return result
+}
+
+func (m *bitmapContainer) toBitmap() *bitmapContainer {
+	return m
+}
+
+func (m *bitmapContainer) toRuns() runContainer {
+	result := make(runContainer, 0, m.runCount())
+	for next := m.iterate(0, chunkSize-1, false); ; {
+		x, ok := next()
+		if !ok {
+			return result
+		}
+		if n := len(result); n > 0 && result[n-1].last+1 == x {
+			result[n-1].last = x
+		} else {
+			result = append(result, interval{start: x, last: x})
+		}
+	}
+}
+
+func (m *bitmapContainer) iterate(lo, hi uint16, reverse bool) func() (uint16, bool) {
+	loWord, hiWord := int(lo>>6), int(hi>>6)
+	loMask, hiMask := ^uint64(0)<<(lo&63), ^uint64(0)>>(63-hi&63)
+	word := func(i int) uint64 {
+		w := m.words[i]
+		if i == loWord {
+			w &= loMask
+		}
+		if i == hiWord {
+			w &= hiMask
+		}
+		return w
+	}
+	if reverse {
+		i := hiWord
+		w := word(i)
+		return func() (uint16, bool) {
+			for w == 0 {
+				if i--; i < loWord {
+					return 0, false
+				}
+				w = word(i)
+			}
+			b := 63 - bits.LeadingZeros64(w)
+			w &^= 1 << b
+			return uint16(i<<6 + b), true
+		}
+	}
+	i := loWord
+	w := word(i)
+	return func() (uint16, bool) {
+		for w == 0 {
+			if i++; i > hiWord {
+				return 0, false
+			}
+			w = word(i)
+		}
+		b := bits.TrailingZeros64(w)
+		w &= w - 1
+		return uint16(i<<6 + b), true
+	}
+}
+
+func (m *bitmapContainer) sizeInBytes() int {
+	return 8 * bitmapWords
+}
+
+// interval is the closed range [start, last].
+type interval struct {
+	start, last uint16
+}
+
+// runContainer holds disjoint, non-adjacent intervals in ascending order.
+type runContainer []interval
+
+// search returns the index of the first interval that doesn't end before x.
+func (r runContainer) search(x uint16) int {
+	return sort.Search(len(r), func(i int) bool { return r[i].last >= x })
+}
+
+func (r runContainer) cardinality() int {
+	n := 0
+	for _, iv := range r {
+		n += int(iv.last-iv.start) + 1
+	}
+	return n
+}
+
+func (r runContainer) has(x uint16) bool {
+	i := r.search(x)
+	return i < len(r) && r[i].start <= x
+}
+
+func (r runContainer) with(x uint16) container {
+	i := r.search(x)
+	if i < len(r) && r[i].start <= x {
+		return r
+	}
+	joinsPrev := i > 0 && r[i-1].last+1 == x
+	joinsNext := i < len(r) && r[i].start-1 == x
+	result := make(runContainer, 0, len(r)+1)
+	switch {
+	case joinsPrev && joinsNext:
+		result = append(append(result, r[:i-1]...), interval{start: r[i-1].start, last: r[i].last})
+		return append(result, r[i+1:]...)
+	case joinsPrev:
+		result = append(append(result, r[:i-1]...), interval{start: r[i-1].start, last: x})
+	case joinsNext:
+		result = append(append(result, r[:i]...), interval{start: x, last: r[i].last})
+		return append(result, r[i+1:]...)
+	default:
+		result = append(append(result, r[:i]...), interval{start: x, last: x})
+	}
+	return append(result, r[i:]...)
+}
+
+func (r runContainer) without(x uint16) container {
+	i := r.search(x)
+	if i == len(r) || r[i].start > x {
+		return r
+	}
+	iv := r[i]
+	result := append(make(runContainer, 0, len(r)+1), r[:i]...)
+	if iv.start < x {
+		result = append(result, interval{start: iv.start, last: x - 1})
+	}
+	if x < iv.last {
+		result = append(result, interval{start: x + 1, last: iv.last})
+	}
+	return append(result, r[i+1:]...)
+}
+
+func (r runContainer) min() uint16 {
+	return r[0].start
+}
+
+func (r runContainer) max() uint16 {
+	return r[len(r)-1].last
+}
+
+func (r runContainer) rank(x uint16) int {
+	n := 0
+	for _, iv := range r {
+		if iv.last >= x {
+			if iv.start < x {
+				n += int(x - iv.start)
+			}
+			break
+		}
+		n += int(iv.last-iv.start) + 1
+	}
+	return n
+}
+
+func (r runContainer) selectAt(k int) uint16 {
+	for _, iv := range r {
+		if n := int(iv.last-iv.start) + 1; k >= n {
+			k -= n
+			continue
+		}
+		return iv.start + uint16(k)
+	}
+	panic("rank out of range")
+}
+
+func (r runContainer) runCount() int {
+	return len(r)
+}
+
+func (r runContainer) toArray() arrayContainer {
+	result := make(arrayContainer, 0, r.cardinality())
+	for _, iv := range r {
+		for x := int(iv.start); x <= int(iv.last); x++ {
+			result = append(result, uint16(x))
+		}
+	}
+	return result
+}
+
+func (r runContainer) toBitmap() *bitmapContainer {
+	m := &bitmapContainer{}
+	for _, iv := range r {
+		m.setRange(iv.start, iv.last)
+	}
+	m.recount()
+	return m
+}
+
+func (r runContainer) toRuns() runContainer {
+	return r
+}
+
+func (r runContainer) iterate(lo, hi uint16, reverse bool) func() (uint16, bool) {
+	if reverse {
+		// Start from the last interval that starts at or before hi.
+		i := r.search(hi)
+		if i == len(r) || r[i].start > hi {
+			i--
+		}
+		next := int(hi)
+		return func() (uint16, bool) {
+			for ; i >= 0; i-- {
+				if next > int(r[i].last) {
+					next = int(r[i].last)
+				}
+				if next < int(lo) {
+					return 0, false
+				}
+				if next >= int(r[i].start) {
+					next--
+					return uint16(next + 1), true
+				}
+			}
+			return 0, false
+		}
+	}
+	i := r.search(lo)
+	next := int(lo)
+	return func() (uint16, bool) {
+		for ; i < len(r); i++ {
+			if next < int(r[i].start) {
+				next = int(r[i].start)
+			}
+			if next > int(hi) {
+				return 0, false
+			}
+			if next <= int(r[i].last) {
+				next++
+				return uint16(next - 1), true
+			}
+		}
+		return 0, false
+	}
+}
+
+func (r runContainer) sizeInBytes() int {
+	return 4 * len(r)
+}
+
+// setRange sets the bits in [lo, hi] without updating card.
+func (m *bitmapContainer) setRange(lo, hi uint16) {
+	loWord, hiWord := lo>>6, hi>>6
+	loMask, hiMask := ^uint64(0)<<(lo&63), ^uint64(0)>>(63-hi&63)
+	if loWord == hiWord {
+		m.words[loWord] |= loMask & hiMask
+		return
+	}
+	m.words[loWord] |= loMask
+	for i := loWord + 1; i < hiWord; i++ {
+		m.words[i] = ^uint64(0)
+	}
+	m.words[hiWord] |= hiMask
+}
+
+func unionRuns(a, b runContainer) runContainer {
+	result := make(runContainer, 0, len(a)+len(b))
+	add := func(iv interval) {
+		if n := len(result); n > 0 && int(result[n-1].last)+1 >= int(iv.start) {
+			if iv.last > result[n-1].last {
+				result[n-1].last = iv.last
+			}
+			return
+		}
+		result = append(result, iv)
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if j == len(b) || i < len(a) && a[i].start < b[j].start {
+			add(a[i])
+			i++
+		} else {
+			add(b[j])
+			j++
+		}
+	}
+	return result
+}
+
+func intersectRuns(a, b runContainer) runContainer {
+	var result runContainer
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, last := a[i].start, a[i].last
+		if b[j].start > start {
+			start = b[j].start
+		}
+		if b[j].last < last {
+			last = b[j].last
+		}
+		if start <= last {
+			result = append(result, interval{start: start, last: last})
+		}
+		if a[i].last < b[j].last {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+func containerString(c container) string {
+	switch c.(type) {
+	case arrayContainer:
+		return fmt.Sprintf("array(%d)", c.cardinality())
+	case *bitmapContainer:
+		return fmt.Sprintf("bitmap(%d)", c.cardinality())
+	default:
+		return fmt.Sprintf("runs(%d/%d)", c.cardinality(), c.runCount())
+	}
+}
+
+// shiftContainer adds r to every element of c. Elements that stay within the
+// chunk are returned in lo and the ones that overflow it, reduced by the chunk
+// size, in hi. Bitmaps are shifted a word at a time.
+func shiftContainer(c container, r uint16) (lo, hi container) {
+	if r == 0 {
+		return c, nil
+	}
+	overflow := uint16(chunkSize - int(r))
+	switch c := c.(type) {
+	case arrayContainer:
+		shifted := make(arrayContainer, len(c))
+		for i, x := range c {
+			shifted[i] = x + r
+		}
+		split, _ := c.search(overflow)
+		return optimize(shifted[:split:split]), optimize(shifted[split:])
+	case runContainer:
+		var a, b runContainer
+		for _, iv := range c {
+			switch {
+			case iv.last < overflow:
+				a = append(a, interval{start: iv.start + r, last: iv.last + r})
+			case iv.start >= overflow:
+				b = append(b, interval{start: iv.start + r, last: iv.last + r})
+			default:
+				a = append(a, interval{start: iv.start + r, last: chunkSize - 1})
+				b = append(b, interval{start: 0, last: iv.last + r})
+			}
+		}
+		return optimize(a), optimize(b)
+	default:
+		var a, b bitmapContainer
+		dst := func(i int) *uint64 {
+			if i < bitmapWords {
+				return &a.words[i]
+			}
+			return &b.words[i-bitmapWords]
+		}
+		w, s := int(r>>6), r&63
+		for i, x := range c.toBitmap().words {
+			if x != 0 {
+				*dst(i + w) |= x << s
+				if s != 0 {
+					*dst(i + w + 1) |= x >> (64 - s)
+				}
+			}
+		}
+		a.recount()
+		b.recount()
+		return optimize(&a), optimize(&b)
+	}
+}
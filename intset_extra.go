@@ -0,0 +1,200 @@
+package frozen
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// ErrInvalidIntSet is returned when text doesn't parse as an IntSet.
+var ErrInvalidIntSet = errors.New("invalid IntSet")
+
+// ParseIntSet parses the range form of an IntSet, a comma-separated list of
+// elements and inclusive ranges such as "1-5,9,12-20". Negative bounds are
+// written with a leading minus, as in "-5--1". Items may overlap and appear in
+// any order, and spaces around them are ignored. The empty string parses as the
+// empty set.
+func ParseIntSet[I integer](text string) (IntSet[I], error) {
+	if strings.TrimSpace(text) == "" {
+		return IntSet[I]{}, nil
+	}
+	var runs []intRun[I]
+	for _, item := range strings.Split(text, ",") {
+		item = strings.TrimSpace(item)
+		first, last := item, item
+		// Skip the first character so that a leading minus isn't taken as the
+		// separator.
+		if len(item) > 1 {
+			if i := strings.IndexByte(item[1:], '-'); i >= 0 {
+				first, last = item[:i+1], item[i+2:]
+			}
+		}
+		lo, err := parseInt[I](strings.TrimSpace(first))
+		if err != nil {
+			return IntSet[I]{}, fmt.Errorf("%w: %q: %v", ErrInvalidIntSet, item, err) //nolint:errorlint
+		}
+		hi, err := parseInt[I](strings.TrimSpace(last))
+		if err != nil {
+			return IntSet[I]{}, fmt.Errorf("%w: %q: %v", ErrInvalidIntSet, item, err) //nolint:errorlint
+		}
+		if lo > hi {
+			return IntSet[I]{}, fmt.Errorf("%w: %q: range is backwards", ErrInvalidIntSet, item)
+		}
+		runs = append(runs, intRun[I]{lo, hi})
+	}
+
+	// Sort and coalesce the runs so the IntSet can be built in one pass.
+	sort.Slice(runs, func(i, j int) bool { return runs[i].first < runs[j].first })
+	_, maxI := intBounds[I]()
+	n := 0
+	for _, r := range runs {
+		if n > 0 && (runs[n-1].last == maxI || r.first <= runs[n-1].last+1) {
+			if r.last > runs[n-1].last {
+				runs[n-1].last = r.last
+			}
+			continue
+		}
+		runs[n] = r
+		n++
+	}
+	return intSetFromRuns(runs[:n]), nil
+}
+
+func appendInt[I integer](b []byte, i I) []byte {
+	if signBit[I]() != 0 {
+		return strconv.AppendInt(b, int64(i), 10)
+	}
+	return strconv.AppendUint(b, uint64(i), 10)
+}
+
+func parseInt[I integer](text string) (I, error) {
+	size := 8 * int(unsafe.Sizeof(I(0)))
+	if signBit[I]() != 0 {
+		i, err := strconv.ParseInt(text, 10, size)
+		return I(i), err
+	}
+	i, err := strconv.ParseUint(text, 10, size)
+	return I(i), err
+}
+
+// runs calls f with the first and last elements of each run of consecutive
+// elements of s, in ascending order.
+func (s IntSet[I]) runs(f func(first, last I)) {
+	var first, last I
+	pending := false
+	for i := s.chunks.Range(); i.Next(); {
+		for _, iv := range i.Value().toRuns() {
+			start, end := chunkElem[I](i.Key(), iv.start), chunkElem[I](i.Key(), iv.last)
+			if pending && start == last+1 {
+				last = end
+				continue
+			}
+			if pending {
+				f(first, last)
+			}
+			first, last, pending = start, end, true
+		}
+	}
+	if pending {
+		f(first, last)
+	}
+}
+
+// RangeString returns the range form of s, e.g., "1-5,9,12-20", which
+// ParseIntSet parses.
+func (s IntSet[I]) RangeString() string {
+	var b strings.Builder
+	s.runs(func(first, last I) {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprint(&b, first)
+		if last != first {
+			fmt.Fprintf(&b, "-%v", last)
+		}
+	})
+	return b.String()
+}
+
+// MarshalText implements encoding.TextMarshaler using the range form.
+func (s IntSet[I]) MarshalText() ([]byte, error) {
+	return []byte(s.RangeString()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using the range form.
+func (s *IntSet[I]) UnmarshalText(text []byte) error {
+	t, err := ParseIntSet[I](string(text))
+	if err != nil {
+		return err
+	}
+	*s = t
+	return nil
+}
+
+// Set implements flag.Value. It adds the elements parsed from text to s, so a
+// flag may be given more than once.
+func (s *IntSet[I]) Set(text string) error {
+	t, err := ParseIntSet[I](text)
+	if err != nil {
+		return err
+	}
+	*s = s.Union(t)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. IntSets are encoded as arrays. Use
+// IntSetRanges to encode them in the range form.
+func (s IntSet[I]) MarshalJSON() ([]byte, error) {
+	// The array is written by hand because encoding/json would encode the
+	// elements of an IntSet[uint8] as a base64 string.
+	b := make([]byte, 0, 2+4*s.Count())
+	b = append(b, '[')
+	for i := s.Range(); i.Next(); {
+		if len(b) > 1 {
+			b = append(b, ',')
+		}
+		b = appendInt(b, i.Value())
+	}
+	return append(b, ']'), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts an array of elements
+// or a string in the range form.
+func (s *IntSet[I]) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return s.UnmarshalText([]byte(text))
+	}
+	var elems []I
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+	*s = NewIntSet(elems...)
+	return nil
+}
+
+// IntSetRanges is an IntSet that is encoded in JSON as a string in the range
+// form, e.g., "1-5,9,12-20", instead of an array.
+type IntSetRanges[I integer] struct {
+	IntSet[I]
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s IntSetRanges[I]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.RangeString())
+}
+
+var (
+	_ encoding.TextMarshaler   = IntSet[int]{}
+	_ encoding.TextUnmarshaler = &IntSet[int]{}
+	_ flag.Value               = &IntSet[int]{}
+	_ json.Marshaler           = IntSet[int]{}
+	_ json.Unmarshaler         = &IntSet[int]{}
+	_ json.Marshaler           = IntSetRanges[int]{}
+)
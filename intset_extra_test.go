@@ -0,0 +1,140 @@
+package frozen_test
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+)
+
+func TestIntSetRangeString(t *testing.T) {
+	t.Parallel()
+
+	test.Equal(t, "", frozen.IntSet[int]{}.RangeString())
+	test.Equal(t, "1-5,9,12-20", frozen.NewIntSet(1, 2, 3, 4, 5, 9).AddRange(12, 21).RangeString())
+	test.Equal(t, "-5--1,3", frozen.NewIntSet(-5, -4, -3, -2, -1, 3).RangeString())
+	test.Equal(t, "-3-2", frozen.NewIntSet(-3, -2, -1, 0, 1, 2).RangeString())
+	test.Equal(t, "65530-65600", frozen.IntSet[int]{}.AddRange(65530, 65601).RangeString())
+	test.Equal(t, "-128-127", frozen.IntSet[int8]{}.Complement(math.MinInt8, math.MaxInt8).With(math.MaxInt8).RangeString())
+	test.Equal(t, "18446744073709551615", frozen.NewIntSet[uint64](math.MaxUint64).RangeString())
+}
+
+func TestParseIntSet(t *testing.T) {
+	t.Parallel()
+
+	for text, expected := range map[string]frozen.IntSet[int]{
+		"":                frozen.IntSet[int]{},
+		"  ":              frozen.IntSet[int]{},
+		"7":               frozen.NewIntSet(7),
+		"1-5,9,12-20":     frozen.NewIntSet(1, 2, 3, 4, 5, 9).AddRange(12, 21),
+		" 12 - 14 , 1 ":   frozen.NewIntSet(1, 12, 13, 14),
+		"-5--3,-1":        frozen.NewIntSet(-5, -4, -3, -1),
+		"-1-1":            frozen.NewIntSet(-1, 0, 1),
+		"5-8,1-6,3":       frozen.IntSet[int]{}.AddRange(1, 9),
+		"0-99999,100000":  frozen.IntSet[int]{}.AddRange(0, 100_001),
+		"-100000-100000":  frozen.IntSet[int]{}.AddRange(-100_000, 100_001),
+		"9,8,7,6,5,4,3,2": frozen.IntSet[int]{}.AddRange(2, 10),
+	} {
+		s, err := frozen.ParseIntSet[int](text)
+		if test.NoError(t, err, "%q", text) {
+			test.True(t, expected.Equal(s), "%q: %v != %v", text, expected, s)
+		}
+	}
+
+	for _, text := range []string{"x", "1,", ",1", "1-", "-", "5-1", "1-2-3", "1--", "300", "-1-200"} {
+		_, err := frozen.ParseIntSet[int8](text)
+		test.True(t, errors.Is(err, frozen.ErrInvalidIntSet), "%q: %v", text, err)
+	}
+	_, err := frozen.ParseIntSet[uint]("-1")
+	test.True(t, errors.Is(err, frozen.ErrInvalidIntSet))
+}
+
+func TestIntSetRangeStringRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	for round := 0; round < 100; round++ {
+		var b frozen.IntSetBuilder[int]
+		for n := r.Intn(5); n > 0; n-- {
+			start := r.Intn(1<<20) - 1<<19
+			for k := r.Intn(100_000); k > 0; k-- {
+				b.Add(start + r.Intn(k+1))
+			}
+		}
+		s := b.Finish()
+		parsed, err := frozen.ParseIntSet[int](s.RangeString())
+		if !test.NoError(t, err) || !test.True(t, s.Equal(parsed)) {
+			break
+		}
+	}
+}
+
+func TestIntSetText(t *testing.T) {
+	t.Parallel()
+
+	var s frozen.IntSet[int]
+	test.RequireNoError(t, s.UnmarshalText([]byte("3-5")))
+	text, err := s.MarshalText()
+	test.RequireNoError(t, err)
+	test.Equal(t, "3-5", string(text))
+	test.True(t, s.UnmarshalText([]byte("3-")) != nil)
+	test.Equal(t, "[3, 4, 5]", s.String())
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var ids frozen.IntSet[int]
+	fs.Var(&ids, "ids", "ids to process")
+	test.RequireNoError(t, fs.Parse([]string{"-ids", "1-3", "-ids", "10,2"}))
+	test.Equal(t, "1-3,10", ids.RangeString())
+	test.True(t, fs.Parse([]string{"-ids", "a"}) != nil)
+}
+
+func TestIntSetJSON(t *testing.T) {
+	t.Parallel()
+
+	s := frozen.NewIntSet(1, 2, 3, 7)
+	j, err := json.Marshal(s)
+	test.RequireNoError(t, err)
+	test.Equal(t, "[1,2,3,7]", string(j))
+	j, err = json.Marshal(frozen.IntSetRanges[int]{IntSet: s})
+	test.RequireNoError(t, err)
+	test.Equal(t, `"1-3,7"`, string(j))
+
+	var v struct {
+		A frozen.IntSet[int]
+		B frozen.IntSet[int]
+		C frozen.IntSetRanges[uint8]
+	}
+	test.RequireNoError(t, json.Unmarshal([]byte(`{"A": [7, 1, 3, 2], "B": "1-3,7", "C": "250-255"}`), &v))
+	test.True(t, s.Equal(v.A))
+	test.True(t, s.Equal(v.B))
+	test.Equal(t, "250-255", v.C.RangeString())
+
+	j, err = json.Marshal(v)
+	test.RequireNoError(t, err)
+	test.Equal(t, `{"A":[1,2,3,7],"B":[1,2,3,7],"C":"250-255"}`, string(j))
+
+	test.True(t, json.Unmarshal([]byte(`{"A": "1-"}`), &v) != nil)
+	test.True(t, json.Unmarshal([]byte(`{"A": {}}`), &v) != nil)
+	test.True(t, json.Unmarshal([]byte(`{"C": [256]}`), &v) != nil)
+
+	// uint8 elements aren't encoded as base64, as []uint8 would be.
+	bytes := frozen.NewIntSet[uint8](1, 2, 3, 255)
+	j, err = json.Marshal(bytes)
+	test.RequireNoError(t, err)
+	test.Equal(t, "[1,2,3,255]", string(j))
+	var u frozen.IntSet[uint8]
+	test.RequireNoError(t, json.Unmarshal(j, &u))
+	test.True(t, bytes.Equal(u))
+
+	j, err = json.Marshal(frozen.NewIntSet(-3, math.MaxInt64))
+	test.RequireNoError(t, err)
+	test.Equal(t, "[-3,9223372036854775807]", string(j))
+	j, err = json.Marshal(frozen.IntSet[int]{})
+	test.RequireNoError(t, err)
+	test.Equal(t, "[]", string(j))
+}
@@ -1,26 +1,53 @@
 package frozen
 
+import "github.com/arr-ai/frozen/internal/pkg/patricia"
+
 type integer interface {
 	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
 }
 
-type intSetIterator[T integer] struct {
-	cellIter       MapIterator[T, cellMask]
-	cell           cellMask
-	firstIntInCell T
+// intSetIterator iterates over the elements of an IntSet from lo to hi
+// inclusive, in ascending order or descending if reverse is true.
+type intSetIterator[I integer] struct {
+	chunks       *patricia.Iterator[container]
+	loKey, hiKey uint64
+	lo, hi       uint16
+	reverse      bool
+	next         func() (uint16, bool)
+	value        I
+}
+
+func newIntSetIterator[I integer](s IntSet[I], lo, hi I, reverse bool) *intSetIterator[I] {
+	i := &intSetIterator[I]{reverse: reverse}
+	i.loKey, i.lo = locateChunk(lo)
+	i.hiKey, i.hi = locateChunk(hi)
+	i.chunks = s.chunks.Between(i.loKey, i.hiKey, reverse)
+	return i
 }
 
-func (i *intSetIterator[T]) Next() bool {
-	if i.cell = i.cell.next(); i.cell == 0 {
-		if !i.cellIter.Next() {
+func (i *intSetIterator[I]) Next() bool {
+	for {
+		if i.next != nil {
+			if low, ok := i.next(); ok {
+				i.value = chunkElem[I](i.chunks.Key(), low)
+				return true
+			}
+		}
+		if !i.chunks.Next() {
+			i.next = nil
 			return false
 		}
-		i.cell = i.cellIter.Value()
-		i.firstIntInCell = i.cellIter.Key()
+		key, lo, hi := i.chunks.Key(), uint16(0), uint16(chunkSize-1)
+		if key == i.loKey {
+			lo = i.lo
+		}
+		if key == i.hiKey {
+			hi = i.hi
+		}
+		i.next = i.chunks.Value().iterate(lo, hi, i.reverse)
 	}
-	return true
 }
 
-func (i *intSetIterator[T]) Value() T {
-	return i.firstIntInCell<<cellShift + T(i.cell.index())
+func (i *intSetIterator[I]) Value() I {
+	return i.value
 }
@@ -0,0 +1,157 @@
+package frozen
+
+import "github.com/arr-ai/frozen/internal/pkg/patricia"
+
+// intSetRange returns an IntSet with all values in [lo, hi].
+func intSetRange[I integer](lo, hi I) IntSet[I] {
+	return intSetFromRuns([]intRun[I]{{lo, hi}})
+}
+
+// intRun is the closed range [first, last].
+type intRun[I integer] struct {
+	first, last I
+}
+
+// intSetFromRuns returns an IntSet with all values in runs, which must be
+// disjoint, non-adjacent and in ascending order. Every chunk is built as a run
+// container, so the result takes time and space linear in the number of chunks
+// the runs span.
+func intSetFromRuns[I integer](runs []intRun[I]) IntSet[I] {
+	var entries []patricia.Entry[container]
+	count := 0
+	for _, r := range runs {
+		loKey, loLow := locateChunk(r.first)
+		hiKey, hiLow := locateChunk(r.last)
+		for key := loKey; ; key++ {
+			iv := interval{start: 0, last: chunkSize - 1}
+			if key == loKey {
+				iv.start = loLow
+			}
+			if key == hiKey {
+				iv.last = hiLow
+			}
+			count += int(iv.last-iv.start) + 1
+			if n := len(entries); n > 0 && entries[n-1].Key == key {
+				entries[n-1].Value = append(entries[n-1].Value.(runContainer), iv)
+			} else {
+				entries = append(entries, patricia.Entry[container]{Key: key, Value: runContainer{iv}})
+			}
+			if key == hiKey {
+				break
+			}
+		}
+	}
+	for i, e := range entries {
+		entries[i].Value = optimize(e.Value)
+	}
+	return IntSet[I]{chunks: patricia.FromSorted(entries), count: count}
+}
+
+// Shift returns an IntSet with delta added to every element of s. Elements
+// that would fall outside the range of I are dropped. Whole containers are
+// shifted at once, so this is much faster than s.Map(...).
+func (s IntSet[I]) Shift(delta int) IntSet[I] {
+	if delta == 0 || s.IsEmpty() {
+		return s
+	}
+	lo, hi := intBounds[I]()
+	minKey, minLow := locateChunk(lo)
+	maxKey, maxLow := locateChunk(hi)
+	shiftKey := func(key uint64, q int64) (uint64, bool) {
+		if q >= 0 {
+			return key + uint64(q), maxKey-key >= uint64(q)
+		}
+		return key - uint64(-q), key-minKey >= uint64(-q)
+	}
+
+	var entries []patricia.Entry[container]
+	count := 0
+	add := func(key uint64, c container) {
+		// Chunks at the ends of I's range may be partial.
+		if c != nil && key == minKey && minLow > 0 {
+			c = containerIntersection(c, runContainer{{start: minLow, last: chunkSize - 1}})
+		}
+		if c != nil && key == maxKey && maxLow < chunkSize-1 {
+			c = containerIntersection(c, runContainer{{start: 0, last: maxLow}})
+		}
+		if c == nil {
+			return
+		}
+		count += c.cardinality()
+		if n := len(entries); n > 0 && entries[n-1].Key == key {
+			count -= entries[n-1].Value.cardinality() + c.cardinality()
+			entries[n-1].Value = containerUnion(entries[n-1].Value, c)
+			count += entries[n-1].Value.cardinality()
+			return
+		}
+		entries = append(entries, patricia.Entry[container]{Key: key, Value: c})
+	}
+
+	q, r := int64(delta)>>chunkShift, uint16(delta)
+	for i := s.chunks.Range(); i.Next(); {
+		a, b := shiftContainer(i.Value(), r)
+		if key, ok := shiftKey(i.Key(), q); ok && a != nil {
+			add(key, a)
+		}
+		if key, ok := shiftKey(i.Key(), q+1); ok && b != nil {
+			add(key, b)
+		}
+	}
+	return IntSet[I]{chunks: patricia.FromSorted(entries), count: count}
+}
+
+// AddRange returns s with all values in [lo, hi) added.
+func (s IntSet[I]) AddRange(lo, hi I) IntSet[I] {
+	if lo >= hi {
+		return s
+	}
+	return s.Union(intSetRange(lo, hi-1))
+}
+
+// RemoveRange returns s without the values in [lo, hi). It takes time
+// proportional to the number of chunks of s in the range, however wide the
+// range is.
+func (s IntSet[I]) RemoveRange(lo, hi I) IntSet[I] {
+	if lo >= hi || s.IsEmpty() {
+		return s
+	}
+	loKey, loLow := locateChunk(lo)
+	hiKey, hiLow := locateChunk(hi - 1)
+	below, rest := s.chunks.Split(loKey)
+	mid, above := rest.Split(hiKey + 1)
+	count := s.count
+	chunks := below.Union(above, nil)
+	for i := mid.Range(); i.Next(); {
+		key, c := i.Key(), i.Value()
+		count -= c.cardinality()
+		iv := interval{start: 0, last: chunkSize - 1}
+		if key == loKey {
+			iv.start = loLow
+		}
+		if key == hiKey {
+			iv.last = hiLow
+		}
+		if c = containerDifference(c, runContainer{iv}); c != nil {
+			count += c.cardinality()
+			chunks = chunks.With(key, c)
+		}
+	}
+	return IntSet[I]{chunks: chunks, count: count}
+}
+
+// FlipRange returns s with the values in [lo, hi) that are in s removed and
+// the rest added.
+func (s IntSet[I]) FlipRange(lo, hi I) IntSet[I] {
+	if lo >= hi {
+		return s
+	}
+	return s.SymmetricDifference(intSetRange(lo, hi-1))
+}
+
+// Complement returns the values in [lo, hi) that are not in s.
+func (s IntSet[I]) Complement(lo, hi I) IntSet[I] {
+	if lo >= hi {
+		return IntSet[I]{}
+	}
+	return intSetRange(lo, hi-1).Difference(s)
+}
@@ -1,7 +1,9 @@
 package frozen_test
 
 import (
+	"fmt"
 	"math"
+	"math/big"
 	"math/rand"
 	"sort"
 	"testing"
@@ -315,3 +317,402 @@ func getDistinctInts(x []int) []int {
 	}
 	return distinct
 }
+
+func TestIntSetNegative(t *testing.T) {
+	t.Parallel()
+
+	s := frozen.NewIntSet(-1, -64, -65, math.MinInt64, math.MaxInt64, 0)
+	test.Equal(t, 6, s.Count())
+	for _, i := range []int{-1, -64, -65, math.MinInt64, math.MaxInt64, 0} {
+		test.True(t, s.Has(i), i)
+	}
+	for _, i := range []int{-2, -63, -66, math.MinInt64 + 1, math.MaxInt64 - 1, 1} {
+		test.False(t, s.Has(i), i)
+	}
+	test.ElementsMatch(t, []int{-1, -64, -65, math.MinInt64, math.MaxInt64, 0}, s.Elements())
+	test.True(t, s.Without(-1).Without(math.MinInt64).Equal(frozen.NewIntSet(-64, -65, math.MaxInt64, 0)))
+}
+
+func TestIntSetDifference(t *testing.T) {
+	t.Parallel()
+
+	arr, fullSet := generateIntArrayAndSet(hugeCollectionSize())
+	firstHalf := frozen.NewIntSet(arr[:len(arr)/2]...)
+	secondHalf := frozen.NewIntSet(arr[len(arr)/2:]...)
+
+	test.True(t, fullSet.Difference(firstHalf).Equal(secondHalf))
+	test.True(t, fullSet.Difference(secondHalf).Equal(firstHalf))
+	test.True(t, firstHalf.Difference(fullSet).IsEmpty())
+	test.True(t, firstHalf.Difference(secondHalf).Equal(firstHalf))
+	test.True(t, firstHalf.SymmetricDifference(secondHalf).Equal(fullSet))
+	test.True(t, firstHalf.IsDisjoint(secondHalf))
+	test.False(t, firstHalf.IsDisjoint(fullSet))
+}
+
+func TestIntSetRankSelect(t *testing.T) {
+	t.Parallel()
+
+	s := frozen.NewIntSet(-70_000, -3, 5, 100_000).Union(frozen.NewIntSet(frozen.Iota3(0, 30_000, 3).Elements()...))
+	test.Equal(t, -70_000, s.Min())
+	test.Equal(t, 100_000, s.Max())
+	test.Equal(t, s.Count(), s.Cardinality())
+	test.Equal(t, 0, s.Rank(-70_000))
+	test.Equal(t, 2, s.Rank(0))
+	test.Equal(t, 4, s.Rank(4))
+	test.Equal(t, 5, s.Rank(6))
+	test.Equal(t, s.Count(), s.Rank(math.MaxInt))
+	test.Equal(t, -3, s.Select(1))
+	test.Equal(t, 5, s.Select(4))
+	test.Equal(t, 100_000, s.Select(s.Count()-1))
+	test.Panic(t, func() { s.Select(s.Count()) })
+	test.Panic(t, func() { frozen.NewIntSet[int]().Min() })
+	test.Equal(t, s.Min(), s.Any())
+	test.Panic(t, func() { frozen.NewIntSet[int]().Any() })
+}
+
+func TestIntSetDense(t *testing.T) {
+	t.Parallel()
+
+	const n = 1 << 20
+	var b frozen.IntSetBuilder[int]
+	for i := 0; i < n; i++ {
+		b.Add(i)
+	}
+	dense := b.Finish()
+	evens := dense.Where(func(i int) bool { return i%2 == 0 })
+	test.Equal(t, n, dense.Count())
+	test.Equal(t, n/2, evens.Count())
+	test.Equal(t, n/2, dense.Difference(evens).Count())
+	test.True(t, dense.Difference(evens).IsDisjoint(evens))
+	test.True(t, evens.Union(dense.Difference(evens)).Equal(dense))
+	test.True(t, dense.Without(12345).With(12345).Equal(dense))
+	test.Equal(t, n/2, dense.SymmetricDifference(evens).Count())
+	test.Equal(t, 500, evens.Rank(1000))
+	test.Equal(t, 1000, evens.Select(500))
+}
+
+func TestIntSetBuilder(t *testing.T) {
+	t.Parallel()
+
+	var b frozen.IntSetBuilder[int]
+	test.True(t, b.Finish().IsEmpty())
+	for i := -100; i < 100; i++ {
+		b.Add(i)
+	}
+	for i := -100; i < 100; i += 3 {
+		b.Remove(i)
+	}
+	test.True(t, b.Has(-99))
+	test.False(t, b.Has(-100))
+	test.Equal(t, 133, b.Count())
+	s := b.Finish()
+	test.Equal(t, 133, s.Count())
+	test.True(t, s.Equal(frozen.NewIntSet[int]().Union(s)))
+	test.Equal(t, 0, b.Count())
+}
+
+func TestIntSetAlgebraProperties(t *testing.T) {
+	t.Parallel()
+
+	t.Run("int", func(t *testing.T) { t.Parallel(); assertIntSetAlgebra[int](t, math.MinInt, math.MaxInt) })
+	t.Run("int8", func(t *testing.T) { t.Parallel(); assertIntSetAlgebra[int8](t, math.MinInt8, math.MaxInt8) })
+	t.Run("int16", func(t *testing.T) { t.Parallel(); assertIntSetAlgebra[int16](t, math.MinInt16, math.MaxInt16) })
+	t.Run("int32", func(t *testing.T) { t.Parallel(); assertIntSetAlgebra[int32](t, math.MinInt32, math.MaxInt32) })
+	t.Run("int64", func(t *testing.T) { t.Parallel(); assertIntSetAlgebra[int64](t, math.MinInt64, math.MaxInt64) })
+	t.Run("uint8", func(t *testing.T) { t.Parallel(); assertIntSetAlgebra[uint8](t, 0, math.MaxUint8) })
+	t.Run("uint16", func(t *testing.T) { t.Parallel(); assertIntSetAlgebra[uint16](t, 0, math.MaxUint16) })
+	t.Run("uint32", func(t *testing.T) { t.Parallel(); assertIntSetAlgebra[uint32](t, 0, math.MaxUint32) })
+	t.Run("uint64", func(t *testing.T) { t.Parallel(); assertIntSetAlgebra[uint64](t, 0, math.MaxUint64) })
+	t.Run("uintptr", func(t *testing.T) { t.Parallel(); assertIntSetAlgebra[uintptr](t, 0, ^uintptr(0)) })
+}
+
+type testInteger interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// assertIntSetAlgebra checks IntSet operations against Set on random sets
+// drawn from near the ends and the middle of [lo, hi].
+func assertIntSetAlgebra[I testInteger](t *testing.T, lo, hi I) {
+	t.Helper()
+
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	randomElem := func() I {
+		switch r.Intn(3) {
+		case 0:
+			return lo + I(r.Intn(130))
+		case 1:
+			return hi - I(r.Intn(130))
+		default:
+			return lo/2 + hi/2 + I(r.Intn(130))
+		}
+	}
+	randomSets := func() (frozen.IntSet[I], frozen.Set[I]) {
+		var b frozen.IntSetBuilder[I]
+		var sb frozen.SetBuilder[I]
+		for n := r.Intn(100); n > 0; n-- {
+			i := randomElem()
+			b.Add(i)
+			sb.Add(i)
+		}
+		// Add some long runs to exercise bitmap and run containers.
+		for n := r.Intn(3); n > 0; n-- {
+			i := randomElem()
+			for k := r.Intn(6000); k > 0; k-- {
+				b.Add(i)
+				sb.Add(i)
+				i++
+			}
+		}
+		return b.Finish(), sb.Finish()
+	}
+	assertSame := func(expected frozen.Set[I], actual frozen.IntSet[I], op string) bool {
+		t.Helper()
+		return test.Equal(t, expected.Count(), actual.Count(), op) &&
+			test.True(t, expected.Equal(frozen.NewSet(actual.Elements()...)), "%s: %v != %v", op, expected, actual)
+	}
+
+	rounds := 200
+	if testing.Short() {
+		rounds = 20
+	}
+	for round := 0; round < rounds; round++ {
+		a, sa := randomSets()
+		b, sb := randomSets()
+		if !assertSame(sa, a, "build") ||
+			!assertSame(sa.Union(sb), a.Union(b), "union") ||
+			!assertSame(sa.Intersection(sb), a.Intersection(b), "intersection") ||
+			!assertSame(sa.Difference(sb), a.Difference(b), "difference") ||
+			!assertSame(sa.Difference(sb), a.Difference(b.Union(a.Intersection(b))), "difference") ||
+			!assertSame(sa.SymmetricDifference(sb), a.SymmetricDifference(b), "symmetric difference") {
+			break
+		}
+		test.Equal(t, sa.Intersection(sb).IsEmpty(), a.IsDisjoint(b))
+		test.Equal(t, sa.IsSubsetOf(sb), a.IsSubsetOf(b))
+		test.True(t, a.Intersection(b).IsSubsetOf(a))
+
+		i := randomElem()
+		assertSame(sa.With(i), a.With(i), "with")
+		assertSame(sa.Without(i), a.Without(i), "without")
+		test.Equal(t, sa.Has(i), a.Has(i))
+
+		if !a.IsEmpty() {
+			elems := a.Elements()
+			test.Equal(t, elems[0], a.Min())
+			test.Equal(t, elems[len(elems)-1], a.Max())
+			k := r.Intn(len(elems))
+			test.Equal(t, elems[k], a.Select(k))
+			test.Equal(t, k, a.Rank(elems[k]))
+			test.True(t, sort.SliceIsSorted(elems, func(i, j int) bool { return elems[i] < elems[j] }))
+		}
+	}
+}
+
+func TestIntSetOrdered(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	rounds := 100
+	if testing.Short() {
+		rounds = 10
+	}
+	for round := 0; round < rounds; round++ {
+		var b frozen.IntSetBuilder[int]
+		for n := r.Intn(3); n >= 0; n-- {
+			start := r.Intn(1<<20) - 1<<19
+			for k := r.Intn(10_000); k > 0; k-- {
+				b.Add(start + r.Intn(1+k))
+			}
+		}
+		s := b.Finish()
+		elems := s.Elements()
+		test.True(t, sort.IntsAreSorted(elems))
+
+		var desc []int
+		for i := s.Descending(); i.Next(); {
+			desc = append(desc, i.Value())
+		}
+		test.Equal(t, len(elems), len(desc))
+		for i, e := range desc {
+			if !test.Equal(t, elems[len(elems)-1-i], e) {
+				break
+			}
+		}
+
+		for q := 0; q < 20; q++ {
+			lo := r.Intn(1<<21) - 1<<20
+			if len(elems) > 0 && q%2 == 0 {
+				lo = elems[r.Intn(len(elems))]
+			}
+			hi := lo + r.Intn(1<<18)
+			first := sort.SearchInts(elems, lo)
+			last := sort.SearchInts(elems, hi)
+			var between []int
+			for i := s.RangeBetween(lo, hi); i.Next(); {
+				between = append(between, i.Value())
+			}
+			test.Equal(t, last-first, len(between), "lo=%d hi=%d", lo, hi)
+			if len(between) > 0 {
+				test.Equal(t, elems[first:last], between)
+			}
+			test.Equal(t, last-first, s.CountBetween(lo, hi), "lo=%d hi=%d", lo, hi)
+
+			next, ok := s.Next(lo)
+			i := sort.SearchInts(elems, lo+1)
+			if test.Equal(t, i < len(elems), ok) && ok {
+				test.Equal(t, elems[i], next)
+			}
+			prev, ok := s.Prev(lo)
+			if test.Equal(t, first > 0, ok) && ok {
+				test.Equal(t, elems[first-1], prev)
+			}
+		}
+	}
+}
+
+func TestIntSetOrderedLimits(t *testing.T) {
+	t.Parallel()
+
+	s := frozen.NewIntSet[int8](math.MinInt8, -1, 0, math.MaxInt8)
+	_, ok := s.Next(math.MaxInt8)
+	test.False(t, ok)
+	_, ok = s.Prev(math.MinInt8)
+	test.False(t, ok)
+	next, _ := s.Next(-1)
+	test.Equal(t, int8(0), next)
+	prev, _ := s.Prev(0)
+	test.Equal(t, int8(-1), prev)
+	test.Equal(t, 3, s.CountBetween(math.MinInt8, math.MaxInt8))
+	test.Equal(t, 0, s.CountBetween(5, -5))
+	test.False(t, s.RangeBetween(5, 5).Next())
+
+	var e frozen.IntSet[uint64]
+	test.False(t, e.Descending().Next())
+	_, ok = e.Next(0)
+	test.False(t, ok)
+}
+
+func TestIntSetIntervals(t *testing.T) {
+	t.Parallel()
+
+	t.Run("int", func(t *testing.T) { t.Parallel(); assertIntSetIntervals[int](t, math.MinInt, math.MaxInt) })
+	t.Run("int8", func(t *testing.T) { t.Parallel(); assertIntSetIntervals[int8](t, math.MinInt8, math.MaxInt8) })
+	t.Run("int16", func(t *testing.T) { t.Parallel(); assertIntSetIntervals[int16](t, math.MinInt16, math.MaxInt16) })
+	t.Run("int32", func(t *testing.T) { t.Parallel(); assertIntSetIntervals[int32](t, math.MinInt32, math.MaxInt32) })
+	t.Run("uint8", func(t *testing.T) { t.Parallel(); assertIntSetIntervals[uint8](t, 0, math.MaxUint8) })
+	t.Run("uint16", func(t *testing.T) { t.Parallel(); assertIntSetIntervals[uint16](t, 0, math.MaxUint16) })
+	t.Run("uint64", func(t *testing.T) { t.Parallel(); assertIntSetIntervals[uint64](t, 0, math.MaxUint64) })
+}
+
+// assertIntSetIntervals checks the interval operations of IntSet against
+// element-wise results, using big.Int to decide which shifted elements fit in
+// I.
+func assertIntSetIntervals[I testInteger](t *testing.T, lo, hi I) {
+	t.Helper()
+
+	toBig := func(i I) *big.Int {
+		if I(0)-1 < 0 {
+			return big.NewInt(int64(i))
+		}
+		return new(big.Int).SetUint64(uint64(i))
+	}
+	bigLo, bigHi := toBig(lo), toBig(hi)
+
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	randomElem := func() I {
+		switch r.Intn(3) {
+		case 0:
+			return lo + I(r.Intn(70_000))
+		case 1:
+			return hi - I(r.Intn(70_000))
+		default:
+			return lo/2 + hi/2 + I(r.Intn(70_000))
+		}
+	}
+	randomSet := func() frozen.IntSet[I] {
+		var b frozen.IntSetBuilder[I]
+		for n := r.Intn(200); n > 0; n-- {
+			b.Add(randomElem())
+		}
+		for n := r.Intn(3); n > 0; n-- {
+			i := randomElem()
+			for k := r.Intn(10_000); k > 0 && i < hi; k-- {
+				if r.Intn(4) > 0 {
+					b.Add(i)
+				}
+				i++
+			}
+		}
+		return b.Finish()
+	}
+	assertSame := func(expected, actual frozen.IntSet[I], op string) bool {
+		t.Helper()
+		return test.Equal(t, expected.Count(), actual.Count(), op) &&
+			test.True(t, expected.Equal(actual), "%s: %v != %v", op, expected, actual)
+	}
+
+	rounds := 50
+	if testing.Short() {
+		rounds = 5
+	}
+	for round := 0; round < rounds; round++ {
+		s := randomSet()
+
+		delta := r.Intn(200_000) - 100_000
+		switch r.Intn(3) {
+		case 0:
+			delta = (r.Intn(20) - 10) << 16
+		case 1:
+			delta = int(r.Int63n(1<<40)) - 1<<39
+		}
+		var b frozen.IntSetBuilder[I]
+		for i := s.Range(); i.Next(); {
+			shifted := new(big.Int).Add(toBig(i.Value()), big.NewInt(int64(delta)))
+			if shifted.Cmp(bigLo) >= 0 && shifted.Cmp(bigHi) <= 0 {
+				b.Add(i.Value() + I(delta))
+			}
+		}
+		if !assertSame(b.Finish(), s.Shift(delta), fmt.Sprintf("shift %d", delta)) {
+			break
+		}
+
+		a := randomElem()
+		z := a + I(r.Intn(150_000))
+		if z < a {
+			z = hi
+		}
+		inRange := func(i I) bool { return a <= i && i < z }
+		var added, flipped, complement frozen.IntSetBuilder[I]
+		for i := s.Range(); i.Next(); {
+			added.Add(i.Value())
+			if !inRange(i.Value()) {
+				flipped.Add(i.Value())
+			}
+		}
+		for i := a; i < z; i++ {
+			added.Add(i)
+			if !s.Has(i) {
+				flipped.Add(i)
+				complement.Add(i)
+			}
+		}
+		if !assertSame(added.Finish(), s.AddRange(a, z), "add range") ||
+			!assertSame(s.Where(func(i I) bool { return !inRange(i) }), s.RemoveRange(a, z), "remove range") ||
+			!assertSame(flipped.Finish(), s.FlipRange(a, z), "flip range") ||
+			!assertSame(complement.Finish(), s.Complement(a, z), "complement") {
+			break
+		}
+		test.True(t, s.FlipRange(a, z).FlipRange(a, z).Equal(s))
+	}
+}
+
+func TestIntSetRemoveRangeWide(t *testing.T) {
+	t.Parallel()
+
+	s := frozen.NewIntSet(math.MinInt, -1<<40, 0, 1<<40, math.MaxInt)
+	test.Equal(t, "[-9223372036854775808, 9223372036854775807]", s.RemoveRange(math.MinInt+1, math.MaxInt).String())
+	u := frozen.NewIntSet[uint64]().AddRange(math.MaxUint64-1, math.MaxUint64)
+	test.Equal(t, "[18446744073709551615]", u.Shift(1).String())
+	test.True(t, u.Shift(2).IsEmpty())
+	test.True(t, s.Complement(5, 5).IsEmpty())
+	test.Equal(t, s, s.AddRange(5, 5))
+}
@@ -0,0 +1,377 @@
+package lazy
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/value"
+)
+
+// Map represents a map from keys of type K to values of type V. Like Set,
+// operations on a Map build new Maps lazily. A Map is backed by a Set of its
+// entries, with distinct keys, so it shares the Set's memoization: nothing is
+// copied until the Map is iterated, and then only once.
+type Map[K, V any] interface {
+	// IsEmpty returns true iff there are no entries in this Map.
+	IsEmpty() bool
+
+	// FastIsEmpty returns IsEmpty() in O(1) time. Otherwise, ok=false.
+	FastIsEmpty() (empty, ok bool)
+
+	// Count returns the number of entries in this Map.
+	Count() int
+
+	// FastCount returns Count() in O(1) time. Otherwise, ok=false.
+	FastCount() (count int, ok bool)
+
+	// Has returns true iff key is in this Map.
+	Has(key K) bool
+
+	// Get returns the value associated with key and true iff the key is found.
+	Get(key K) (val V, has bool)
+
+	// FastGet returns Get() in O(1) time, which is usually possible for Maps
+	// derived from a frozen.Map. Otherwise, ok=false.
+	FastGet(key K) (val V, has, ok bool)
+
+	// Keys returns a Set of the keys in this Map.
+	Keys() SetOf[K]
+
+	// Entries returns a Set of the entries in this Map.
+	Entries() SetOf[frozen.KeyValue[K, V]]
+
+	// Freeze returns a frozen.Map with all the entries in this Map.
+	Freeze() frozen.Map[K, V]
+
+	// Range returns an iterator over the entries in this Map.
+	Range() MapIterator[K, V]
+
+	// Where returns a Map with only the entries for which pred returns true.
+	Where(pred func(key K, val V) bool) Map[K, V]
+
+	// Project returns a Map with only the entries for keys.
+	Project(keys ...K) Map[K, V]
+
+	// Merge returns a Map with the entries of this Map and n. The values of
+	// keys in both are combined with resolve.
+	Merge(n Map[K, V], resolve func(key K, a, b V) V) Map[K, V]
+
+	// Update returns a Map with the entries of n added to or replacing those of
+	// this Map.
+	Update(n Map[K, V]) Map[K, V]
+
+	// Explain returns a description of the plan for computing this Map's
+	// entries, as an indented tree of operations.
+	Explain() string
+}
+
+// MapIterator provides for iterating over a Map.
+type MapIterator[K, V any] interface {
+	// Next moves to the next entry or returns false if there are no more.
+	Next() bool
+
+	// Key returns the key for the current entry.
+	Key() K
+
+	// Value returns the value for the current entry.
+	Value() V
+}
+
+type lazyMap[K, V any] struct {
+	entries SetOf[frozen.KeyValue[K, V]]
+
+	// get implements FastGet, or is nil if the entries must be searched.
+	get func(key K) (val V, has, ok bool)
+
+	// index holds the frozen entries once Freeze has been called, including
+	// by a Get without a fast path.
+	index atomic.Pointer[frozen.Map[K, V]]
+}
+
+// FromMap returns a Map with the entries of m.
+func FromMap[K, V any](m frozen.Map[K, V]) Map[K, V] {
+	return &lazyMap[K, V]{
+		entries: fromFrozenMap(m),
+		get: func(key K) (val V, has, ok bool) {
+			val, has = m.Get(key)
+			return val, has, true
+		},
+	}
+}
+
+// MapFromEntries returns a Map with the entries of s, which must have distinct
+// keys. The entries are memoized, so s is only evaluated once.
+func MapFromEntries[K, V any](s SetOf[frozen.KeyValue[K, V]]) Map[K, V] {
+	return &lazyMap[K, V]{entries: memo(s)}
+}
+
+// MapValues returns a Map with the keys of m and the results of calling f for
+// their values.
+func MapValues[K, V, U any](m Map[K, V], f func(key K, val V) U) Map[K, U] {
+	return &lazyMap[K, U]{
+		entries: SetMap(m.Entries(), func(kv frozen.KeyValue[K, V]) frozen.KeyValue[K, U] {
+			return frozen.KV(kv.Key, f(kv.Key, kv.Value))
+		}),
+		get: func(key K) (val U, has, ok bool) {
+			v, has, ok := m.FastGet(key)
+			if has {
+				val = f(key, v)
+			}
+			return val, has, ok
+		},
+	}
+}
+
+func (m *lazyMap[K, V]) IsEmpty() bool                   { return m.entries.IsEmpty() }
+func (m *lazyMap[K, V]) FastIsEmpty() (empty, ok bool)   { return m.entries.FastIsEmpty() }
+func (m *lazyMap[K, V]) Count() int                      { return m.entries.Count() }
+func (m *lazyMap[K, V]) FastCount() (count int, ok bool) { return m.entries.FastCount() }
+func (m *lazyMap[K, V]) Entries() SetOf[frozen.KeyValue[K, V]] {
+	return m.entries
+}
+
+func (m *lazyMap[K, V]) Explain() string {
+	return m.entries.Explain()
+}
+
+func (m *lazyMap[K, V]) Has(key K) bool {
+	_, has := m.Get(key)
+	return has
+}
+
+// Get freezes m the first time it is called for a key that FastGet can't
+// find, so that later lookups are fast.
+func (m *lazyMap[K, V]) Get(key K) (val V, has bool) {
+	if val, has, ok := m.FastGet(key); ok {
+		return val, has
+	}
+	index := m.Freeze()
+	return index.Get(key)
+}
+
+func (m *lazyMap[K, V]) FastGet(key K) (val V, has, ok bool) {
+	if m.get != nil {
+		if val, has, ok = m.get(key); ok {
+			return val, has, ok
+		}
+	}
+	if index := m.index.Load(); index != nil {
+		val, has = index.Get(key)
+		return val, has, true
+	}
+	if empty, ok := m.entries.FastIsEmpty(); ok && empty {
+		return val, false, true
+	}
+	return val, false, false
+}
+
+func (m *lazyMap[K, V]) Keys() SetOf[K] {
+	s := &keySet[K, V]{m: m}
+	s.baseSet.set = s
+	return s
+}
+
+func (m *lazyMap[K, V]) Freeze() frozen.Map[K, V] {
+	if f, ok := m.entries.(*frozenMapEntries[K, V]); ok {
+		return f.m
+	}
+	if index := m.index.Load(); index != nil {
+		return *index
+	}
+	var b frozen.MapBuilder[K, V]
+	for i := m.entries.Range(); i.Next(); {
+		b.Put(i.Value().Key, i.Value().Value)
+	}
+	index := b.Finish()
+	m.index.Store(&index)
+	return index
+}
+
+func (m *lazyMap[K, V]) Range() MapIterator[K, V] {
+	return &mapIterator[K, V]{i: m.entries.Range()}
+}
+
+func (m *lazyMap[K, V]) Where(pred func(key K, val V) bool) Map[K, V] {
+	return &lazyMap[K, V]{
+		entries: m.entries.Where(func(kv frozen.KeyValue[K, V]) bool { return pred(kv.Key, kv.Value) }),
+		get: func(key K) (val V, has, ok bool) {
+			val, has, ok = m.FastGet(key)
+			if has && !pred(key, val) {
+				return val, false, true
+			}
+			return val, has, ok
+		},
+	}
+}
+
+func (m *lazyMap[K, V]) Project(keys ...K) Map[K, V] {
+	set := frozen.NewSet(keys...)
+	if m.get == nil {
+		return m.Where(func(key K, _ V) bool { return set.Has(key) })
+	}
+	// Look the keys up instead of scanning the entries.
+	entries := SetMap(From(set).Where(m.Has), func(key K) frozen.KeyValue[K, V] {
+		val, _ := m.Get(key)
+		return frozen.KV(key, val)
+	})
+	return &lazyMap[K, V]{
+		entries: entries,
+		get: func(key K) (val V, has, ok bool) {
+			if !set.Has(key) {
+				return val, false, true
+			}
+			return m.FastGet(key)
+		},
+	}
+}
+
+func (m *lazyMap[K, V]) Merge(n Map[K, V], resolve func(key K, a, b V) V) Map[K, V] {
+	left := SetMap(m.entries, func(kv frozen.KeyValue[K, V]) frozen.KeyValue[K, V] {
+		if val, has := n.Get(kv.Key); has {
+			return frozen.KV(kv.Key, resolve(kv.Key, kv.Value, val))
+		}
+		return kv
+	})
+	right := n.Entries().Where(func(kv frozen.KeyValue[K, V]) bool { return !m.Has(kv.Key) })
+	return &lazyMap[K, V]{
+		entries: left.Union(right),
+		get: func(key K) (val V, has, ok bool) {
+			a, aHas, aOk := m.FastGet(key)
+			b, bHas, bOk := n.FastGet(key)
+			switch {
+			case !aOk || !bOk:
+				return val, false, false
+			case aHas && bHas:
+				return resolve(key, a, b), true, true
+			case aHas:
+				return a, true, true
+			default:
+				return b, bHas, true
+			}
+		},
+	}
+}
+
+func (m *lazyMap[K, V]) Update(n Map[K, V]) Map[K, V] {
+	return m.Merge(n, func(_ K, _, b V) V { return b })
+}
+
+type mapIterator[K, V any] struct {
+	i frozen.Iterator[frozen.KeyValue[K, V]]
+}
+
+func (i *mapIterator[K, V]) Next() bool {
+	return i.i.Next()
+}
+
+func (i *mapIterator[K, V]) Key() K {
+	return i.i.Value().Key
+}
+
+func (i *mapIterator[K, V]) Value() V {
+	return i.i.Value().Value
+}
+
+// keySet is the Set of keys of a Map.
+type keySet[K, V any] struct {
+	baseSet[K]
+	m *lazyMap[K, V]
+}
+
+func (s *keySet[K, V]) extent() extent {
+	return extentOf(s.m.entries)
+}
+
+func (s *keySet[K, V]) plan() (string, []any) {
+	return "keys", []any{s.m.entries}
+}
+
+func (s *keySet[K, V]) FastIsEmpty() (empty, ok bool) {
+	return s.m.entries.FastIsEmpty()
+}
+
+func (s *keySet[K, V]) FastCount() (count int, ok bool) {
+	return s.m.entries.FastCount()
+}
+
+func (s *keySet[K, V]) FastCountUpTo(limit int) (count int, ok bool) {
+	return s.m.entries.FastCountUpTo(limit)
+}
+
+func (s *keySet[K, V]) Has(key K) bool {
+	return s.m.Has(key)
+}
+
+func (s *keySet[K, V]) FastHas(key K) (has, ok bool) {
+	_, has, ok = s.m.FastGet(key)
+	return has, ok
+}
+
+func (s *keySet[K, V]) Range() frozen.Iterator[K] {
+	return &keySetIterator[K, V]{i: s.m.entries.Range()}
+}
+
+type keySetIterator[K, V any] struct {
+	i frozen.Iterator[frozen.KeyValue[K, V]]
+}
+
+func (i *keySetIterator[K, V]) Next() bool {
+	return i.i.Next()
+}
+
+func (i *keySetIterator[K, V]) Value() K {
+	return i.i.Value().Key
+}
+
+// frozenMapEntries is the Set of entries of a frozen.Map.
+type frozenMapEntries[K, V any] struct {
+	baseSet[frozen.KeyValue[K, V]]
+	m frozen.Map[K, V]
+}
+
+func fromFrozenMap[K, V any](m frozen.Map[K, V]) SetOf[frozen.KeyValue[K, V]] {
+	s := &frozenMapEntries[K, V]{m: m}
+	s.baseSet.set = s
+	return s
+}
+
+func (s *frozenMapEntries[K, V]) plan() (string, []any) {
+	return fmt.Sprintf("frozen map (%d entries)", s.m.Count()), nil
+}
+
+func (s *frozenMapEntries[K, V]) FastIsEmpty() (empty, ok bool) {
+	return s.m.IsEmpty(), true
+}
+
+func (s *frozenMapEntries[K, V]) FastCount() (count int, ok bool) {
+	return s.m.Count(), true
+}
+
+func (s *frozenMapEntries[K, V]) FastCountUpTo(limit int) (count int, ok bool) {
+	if n := s.m.Count(); n < limit {
+		return n, true
+	}
+	return limit, true
+}
+
+func (s *frozenMapEntries[K, V]) FastHas(kv frozen.KeyValue[K, V]) (has, ok bool) {
+	val, has := s.m.Get(kv.Key)
+	return has && value.Equal(val, kv.Value), true
+}
+
+func (s *frozenMapEntries[K, V]) Range() frozen.Iterator[frozen.KeyValue[K, V]] {
+	return &frozenMapEntriesIterator[K, V]{i: s.m.Range()}
+}
+
+type frozenMapEntriesIterator[K, V any] struct {
+	i frozen.MapIterator[K, V]
+}
+
+func (i *frozenMapEntriesIterator[K, V]) Next() bool {
+	return i.i.Next()
+}
+
+func (i *frozenMapEntriesIterator[K, V]) Value() frozen.KeyValue[K, V] {
+	return frozen.KV(i.i.Entry())
+}
@@ -0,0 +1,183 @@
+package lazy_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+func squaresMap(n int) frozen.Map[int, int] {
+	return frozen.NewMapFromKeys(frozen.Iota(n), func(k int) int { return k * k })
+}
+
+func TestMapFromMap(t *testing.T) {
+	t.Parallel()
+
+	f := squaresMap(10)
+	m := lazy.FromMap(f)
+	assertFastCountEqual(t, 10, m.Keys())
+	count, ok := m.FastCount()
+	test.True(t, ok)
+	test.Equal(t, 10, count)
+	test.Equal(t, 10, m.Count())
+	test.False(t, m.IsEmpty())
+
+	val, has, ok := m.FastGet(3)
+	test.True(t, has && ok)
+	test.Equal(t, 9, val)
+	_, has, ok = m.FastGet(10)
+	test.True(t, !has && ok)
+	test.True(t, m.Has(9))
+
+	test.True(t, m.Freeze().Equal(f))
+	test.True(t, m.Keys().Freeze().Equal(f.Keys()))
+	assertFastHas(t, m.Keys(), 0)
+	assertFastNotHas(t, m.Keys(), -1)
+	test.Equal(t, "frozen map (10 entries)", m.Explain())
+
+	n := 0
+	for i := m.Range(); i.Next(); n++ {
+		test.Equal(t, i.Key()*i.Key(), i.Value())
+	}
+	test.Equal(t, 10, n)
+}
+
+func TestMapWhere(t *testing.T) {
+	t.Parallel()
+
+	f := squaresMap(100)
+	var calls int64
+	even := func(k, _ int) bool {
+		atomic.AddInt64(&calls, 1)
+		return k%2 == 0
+	}
+	m := lazy.FromMap(f).Where(even).Where(func(k, _ int) bool { return k < 50 })
+	test.Equal(t, "where (2 predicates fused)\n  frozen map (100 entries)", m.Explain())
+
+	// Lookups don't iterate.
+	val, has, ok := m.FastGet(4)
+	test.True(t, has && ok)
+	test.Equal(t, 16, val)
+	_, has, ok = m.FastGet(5)
+	test.True(t, !has && ok)
+	_, has, ok = m.FastGet(60)
+	test.True(t, !has && ok)
+	test.Equal(t, int64(3), atomic.LoadInt64(&calls))
+
+	expected := f.Where(func(k, _ int) bool { return k%2 == 0 && k < 50 })
+	test.True(t, m.Freeze().Equal(expected))
+	test.True(t, m.Freeze().Equal(expected))
+	test.Equal(t, 25, m.Count())
+	test.Equal(t, int64(103), atomic.LoadInt64(&calls))
+}
+
+func TestMapValues(t *testing.T) {
+	t.Parallel()
+
+	f := squaresMap(10)
+	m := lazy.MapValues(lazy.FromMap(f), func(k, v int) string { return frozen.NewSet(k, v).String() })
+	val, has, ok := m.FastGet(2)
+	test.True(t, has && ok)
+	test.Equal(t, frozen.NewSet(2, 4).String(), val)
+	test.True(t, m.Freeze().Equal(frozen.MapMap(f, func(k, v int) string { return frozen.NewSet(k, v).String() })))
+}
+
+func TestMapProject(t *testing.T) {
+	t.Parallel()
+
+	f := squaresMap(10)
+	m := lazy.FromMap(f).Project(2, 3, 42)
+	test.True(t, m.Freeze().Equal(f.Project(2, 3, 42)))
+	_, has, ok := m.FastGet(4)
+	test.True(t, !has && ok)
+	_, has, ok = m.FastGet(42)
+	test.True(t, !has && ok)
+
+	// Without a fast path, Project filters the entries.
+	slow := lazy.MapFromEntries(lazy.FromMap(f).Entries().Where(func(frozen.KeyValue[int, int]) bool { return true }))
+	test.True(t, slow.Project(2, 3, 42).Freeze().Equal(f.Project(2, 3, 42)))
+}
+
+func TestMapMerge(t *testing.T) {
+	t.Parallel()
+
+	a := squaresMap(10)
+	b := frozen.NewMapFromKeys(frozen.Iota3(5, 15, 1), func(k int) int { return -k })
+	sum := func(_, x, y int) int { return x + y }
+	m := lazy.FromMap(a).Merge(lazy.FromMap(b), sum)
+	test.True(t, m.Freeze().Equal(a.Merge(b, sum)))
+	test.Equal(t, 15, m.Count())
+	val, has, ok := m.FastGet(6)
+	test.True(t, has && ok)
+	test.Equal(t, 30, val)
+	val, has, ok = m.FastGet(12)
+	test.True(t, has && ok)
+	test.Equal(t, -12, val)
+
+	u := lazy.FromMap(a).Update(lazy.FromMap(b))
+	test.True(t, u.Freeze().Equal(a.Update(b)))
+}
+
+func TestMapFromEntries(t *testing.T) {
+	t.Parallel()
+
+	var calls int64
+	n := 0
+	entries := lazy.FromFunc(func() (frozen.KeyValue[string, int], bool) {
+		atomic.AddInt64(&calls, 1)
+		n++
+		return frozen.KV(string(rune('a'+n-1)), n), n <= 5
+	})
+	m := lazy.MapFromEntries(entries)
+	_, _, ok := m.FastGet("a")
+	test.False(t, ok)
+	test.Equal(t, int64(0), atomic.LoadInt64(&calls))
+
+	// The first slow lookup builds an index for the rest.
+	val, has := m.Get("c")
+	test.True(t, has)
+	test.Equal(t, 3, val)
+	val, has, ok = m.FastGet("e")
+	test.True(t, has && ok)
+	test.Equal(t, 5, val)
+	test.False(t, m.Has("z"))
+	test.Equal(t, 5, m.Count())
+	test.Equal(t, int64(6), atomic.LoadInt64(&calls))
+}
+
+// rangeCounter counts the calls to its Set's Range.
+type rangeCounter[T any] struct {
+	lazy.SetOf[T]
+	ranges *int64
+}
+
+func (s rangeCounter[T]) Range() frozen.Iterator[T] {
+	atomic.AddInt64(s.ranges, 1)
+	return s.SetOf.Range()
+}
+
+func TestMapFromEntriesMemoized(t *testing.T) {
+	t.Parallel()
+
+	f := frozen.NewMap(frozen.KV("a", 1), frozen.KV("b", 2))
+	var ranges int64
+	kvs := lazy.SetMap(lazy.From(f.Keys()), func(k string) frozen.KeyValue[string, int] {
+		return frozen.KV(k, f.MustGet(k))
+	})
+	entries := rangeCounter[frozen.KeyValue[string, int]]{SetOf: kvs, ranges: &ranges}
+	m := lazy.MapFromEntries[string, int](entries)
+	for n := 0; n < 3; n++ {
+		count := 0
+		for i := m.Range(); i.Next(); {
+			count++
+		}
+		test.Equal(t, 2, count)
+		test.True(t, m.Freeze().Equal(f))
+	}
+	test.Equal(t, int64(1), atomic.LoadInt64(&ranges))
+	_, _, ok := m.FastGet("a")
+	test.True(t, ok)
+}
@@ -1,15 +1,12 @@
 package lazy
 
-// Predicate represents a function that returns true iff el satisfies some
-// condition. The function must be pure. That is: a == b => p(a) == p(b).
-type Predicate func(el any) bool
+import "github.com/arr-ai/frozen"
 
-// Mapper represents a function that transforms el. The function must be
-// pure. That is: a == b => f(a) == f(b).
-type Mapper func(el any) any
-
-// Set represents a set of elements.
-type Set interface {
+// SetOf represents a set of elements of type T. Operations on a Set build new
+// Sets lazily, computing elements only as they are needed.
+//
+// Set is the untyped API that predates generics. It wraps a SetOf[any].
+type SetOf[T any] interface {
 	// IsEmpty returns true iff there are no elements in this Set.
 	IsEmpty() bool
 
@@ -29,12 +26,14 @@ type Set interface {
 	// FastCountUpTo returns CountUpTo() in O(1) time. Otherwise, ok=false.
 	FastCountUpTo(limit int) (count int, ok bool)
 
-	// Freeze returns a frozen.Set with all the elements in this Set.
-	Freeze() Set
+	// Freeze returns a frozen.Set with all the elements in this Set. Sets
+	// derived from large frozen Sets are frozen in parallel, so predicates and
+	// mappers may be called concurrently.
+	Freeze() frozen.Set[T]
 
 	// Range returns an iterator over this Set. Traversal order is indeterminate
 	// and may differ from one invocation of Range to the next.
-	Range() SetIterator
+	Range() frozen.Iterator[T]
 
 	// Hash returns a hash derived from the elements of the set.
 	Hash(seed uintptr) uintptr
@@ -44,55 +43,45 @@ type Set interface {
 	Equal(set any) bool
 
 	// EqualSet returns true iff this Set and set have all the same elements.
-	EqualSet(set Set) bool
+	EqualSet(set SetOf[T]) bool
 
 	// IsSubset returns true iff every element of this Set is in set.
-	IsSubsetOf(set Set) bool
+	IsSubsetOf(set SetOf[T]) bool
 
 	// Has returns true iff el is in this Set.
-	Has(el any) bool
+	Has(el T) bool
 
 	// FastHas returns Has(el) in <= O(log n) time. Otherwise, ok=false.
-	FastHas(el any) (has, ok bool)
+	FastHas(el T) (has, ok bool)
 
-	// With returns a Set containing all the elements from this Set and all the
-	// elements of els.
-	With(v any) Set
+	// With returns a Set containing all the elements from this Set and v.
+	With(v T) SetOf[T]
 
-	// With returns a Set containing all the elements from this Set except the
-	// elements of els.
-	Without(v any) Set
+	// Without returns a Set containing all the elements from this Set except
+	// v.
+	Without(v T) SetOf[T]
 
 	// Where returns a Set containing all the elements from this Set that
 	// satisfy pred.
-	Where(pred Predicate) Set
-
-	// Map returns a Set containing the results of calling m for all the
-	// elements of this Set. Note that the result Set might have fewer elements
-	// than this Set.
-	Map(m Mapper) Set
+	Where(pred func(el T) bool) SetOf[T]
 
 	// Union returns a Set containing all values that are in either this Set or
 	// set.
-	Union(set Set) Set
+	Union(set SetOf[T]) SetOf[T]
 
-	// Union returns a Set containing all values that are in both this Set and
-	// set.
-	Intersection(set Set) Set
+	// Intersection returns a Set containing all values that are in both this
+	// Set and set.
+	Intersection(set SetOf[T]) SetOf[T]
 
 	// Difference returns a Set containing all values that are in this Set but
 	// not in set.
-	Difference(set Set) Set
+	Difference(set SetOf[T]) SetOf[T]
 
-	// Difference returns a Set containing all values that are in this Set but
-	// not in set.
-	SymmetricDifference(set Set) Set
-
-	// Powerset returns the set of all subsets of this Set.
-	Powerset() Set
-}
+	// SymmetricDifference returns a Set containing all values that are in
+	// either this Set or set, but not both.
+	SymmetricDifference(set SetOf[T]) SetOf[T]
 
-type SetIterator interface {
-	Next() bool
-	Value() any
+	// Explain returns a description of the plan for computing this Set, after
+	// rewrites, as an indented tree of operations.
+	Explain() string
 }
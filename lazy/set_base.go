@@ -12,33 +12,34 @@ const (
 	hashSeed = uintptr(uint64(624409645898692063) & uint64(^uintptr(0)))
 )
 
-type baseSet struct {
-	set Set
+type baseSet[T any] struct {
+	set SetOf[T]
 }
 
-func (s *baseSet) IsEmpty() bool {
+func (s *baseSet[T]) IsEmpty() bool {
 	if empty, ok := s.set.FastIsEmpty(); ok {
 		return empty
 	}
 	return !s.set.Range().Next()
 }
 
-func (s *baseSet) FastIsEmpty() (has, ok bool) {
+func (s *baseSet[T]) FastIsEmpty() (has, ok bool) {
 	return false, false
 }
 
-func (s *baseSet) Count() int {
+func (s *baseSet[T]) Count() int {
 	if count, ok := s.set.FastCount(); ok {
 		return count
 	}
+	mustBeFinite(s.set, "Count()")
 	return s.set.CountUpTo(maxInt)
 }
 
-func (s *baseSet) FastCount() (count int, ok bool) {
+func (s *baseSet[T]) FastCount() (count int, ok bool) {
 	return 0, false
 }
 
-func (s *baseSet) CountUpTo(limit int) int {
+func (s *baseSet[T]) CountUpTo(limit int) int {
 	if count, ok := s.set.FastCountUpTo(limit); ok {
 		return count
 	}
@@ -49,31 +50,35 @@ func (s *baseSet) CountUpTo(limit int) int {
 	return n
 }
 
-func (s *baseSet) FastCountUpTo(int) (count int, ok bool) {
+func (s *baseSet[T]) FastCountUpTo(int) (count int, ok bool) {
 	return 0, false
 }
 
-func (s *baseSet) Freeze() Set {
-	if m, ok := memo(s.set).(*memoSet); ok {
-		for i := m.Range(); i.Next(); { //nolint:revive
-		}
-		return m.getSet()
+func (s *baseSet[T]) Freeze() frozen.Set[T] {
+	mustBeFinite(s.set, "Freeze()")
+	if f, ok := parallelFreeze(s.set); ok {
+		return f
+	}
+	var b frozen.SetBuilder[T]
+	for i := s.set.Range(); i.Next(); {
+		b.Add(i.Value())
 	}
-	return s.set
+	return b.Finish()
 }
 
-func (s *baseSet) Equal(set any) bool {
-	if set, ok := set.(Set); ok {
+func (s *baseSet[T]) Equal(set any) bool {
+	if set, ok := set.(SetOf[T]); ok {
 		return s.set.EqualSet(set)
 	}
 	return false
 }
 
-func (s *baseSet) EqualSet(t Set) bool {
-	return s.Freeze().Equal(t.Freeze())
+func (s *baseSet[T]) EqualSet(t SetOf[T]) bool {
+	return s.set.Freeze().Equal(t.Freeze())
 }
 
-func (s *baseSet) Hash(seed uintptr) uintptr {
+func (s *baseSet[T]) Hash(seed uintptr) uintptr {
+	mustBeFinite(s.set, "Hash()")
 	h := hash.Uintptr(hashSeed, seed)
 	for i := s.set.Range(); i.Next(); {
 		h = hash.Any(i.Value(), h)
@@ -81,10 +86,11 @@ func (s *baseSet) Hash(seed uintptr) uintptr {
 	return h
 }
 
-func (s *baseSet) Has(el any) bool {
+func (s *baseSet[T]) Has(el T) bool {
 	if has, ok := s.set.FastHas(el); ok {
 		return has
 	}
+	mustBeFinite(s.set, "Has()")
 	for i := s.set.Range(); i.Next(); {
 		if value.Equal(el, i.Value()) {
 			return true
@@ -93,46 +99,42 @@ func (s *baseSet) Has(el any) bool {
 	return false
 }
 
-func (s *baseSet) FastHas(any) (has, ok bool) {
+func (s *baseSet[T]) FastHas(T) (has, ok bool) {
 	return false, false
 }
 
-func (s *baseSet) IsSubsetOf(t Set) bool {
-	return s.Freeze().IsSubsetOf(t.Freeze())
+func (s *baseSet[T]) IsSubsetOf(t SetOf[T]) bool {
+	return s.set.Freeze().IsSubsetOf(t.Freeze())
 }
 
-func (s *baseSet) Where(pred Predicate) Set {
-	return where(s.set, pred)
+func (s *baseSet[T]) Explain() string {
+	return explain(s.set)
 }
 
-func (s *baseSet) With(v any) Set {
-	return union(s.set, Frozen(frozen.NewSet(v)))
+func (s *baseSet[T]) Where(pred func(el T) bool) SetOf[T] {
+	return where(s.set, pred)
 }
 
-func (s *baseSet) Without(v any) Set {
-	return difference(s.set, Frozen(frozen.NewSet(v)))
+func (s *baseSet[T]) With(v T) SetOf[T] {
+	return union(s.set, From(frozen.NewSet(v)))
 }
 
-func (s *baseSet) Map(m Mapper) Set {
-	return mapper(s.set, m)
+func (s *baseSet[T]) Without(v T) SetOf[T] {
+	return difference(s.set, From(frozen.NewSet(v)))
 }
 
-func (s *baseSet) Union(t Set) Set {
+func (s *baseSet[T]) Union(t SetOf[T]) SetOf[T] {
 	return union(s.set, t)
 }
 
-func (s *baseSet) Intersection(t Set) Set {
+func (s *baseSet[T]) Intersection(t SetOf[T]) SetOf[T] {
 	return intersection(s.set, t)
 }
 
-func (s *baseSet) Difference(t Set) Set {
+func (s *baseSet[T]) Difference(t SetOf[T]) SetOf[T] {
 	return difference(s.set, t)
 }
 
-func (s *baseSet) SymmetricDifference(t Set) Set {
+func (s *baseSet[T]) SymmetricDifference(t SetOf[T]) SetOf[T] {
 	return symmetricDifference(s.set, t)
 }
-
-func (s *baseSet) Powerset() Set {
-	return powerset(s.set)
-}
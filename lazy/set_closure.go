@@ -0,0 +1,192 @@
+package lazy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/arr-ai/frozen"
+)
+
+// closureSet yields the elements of seed followed by those found by each round
+// of semi-naive evaluation, so it can be iterated before, or without, reaching
+// a fixpoint.
+type closureSet[T any] struct {
+	baseSet[T]
+	ctx   context.Context //nolint:containedctx
+	seed  SetOf[T]
+	step  func(delta SetOf[T]) SetOf[T]
+	limit int
+}
+
+// Closure returns the smallest superset of seed that is closed under step, as
+// frozen.Closure does. Rounds are evaluated as elements are needed, so the
+// result may be infinite, in which case Count and Freeze never return; use
+// CountUpTo instead.
+func Closure[T any](seed SetOf[T], step func(delta SetOf[T]) SetOf[T]) SetOf[T] {
+	return ClosureContext(context.Background(), seed, step, 0)
+}
+
+// ClosureContext is Closure with cancellation and an iteration limit. Starting
+// a round after limit rounds, if limit is positive, or after ctx is done panics
+// with an error wrapping frozen.ErrIterationLimit or ctx.Err().
+func ClosureContext[T any](
+	ctx context.Context,
+	seed SetOf[T],
+	step func(delta SetOf[T]) SetOf[T],
+	limit int,
+) SetOf[T] {
+	if empty, ok := seed.FastIsEmpty(); ok && empty {
+		return Empty[T]()
+	}
+	s := &closureSet[T]{ctx: ctx, seed: seed, step: step, limit: limit}
+	s.baseSet.set = s
+	return memo[T](s)
+}
+
+func (s *closureSet[T]) extent() extent {
+	return extentOf(s.seed)
+}
+
+func (s *closureSet[T]) plan() (string, []any) {
+	if s.limit > 0 {
+		return fmt.Sprintf("closure (limit %d rounds)", s.limit), []any{s.seed}
+	}
+	return "closure", []any{s.seed}
+}
+
+func (s *closureSet[T]) Range() frozen.Iterator[T] {
+	return &closureSetIterator[T]{s: s, i: s.seed.Range()}
+}
+
+type closureSetIterator[T any] struct {
+	s     *closureSet[T]
+	i     frozen.Iterator[T]
+	round int
+	seen  frozen.SetBuilder[T]
+	delta frozen.SetBuilder[T]
+	value T
+	err   error
+}
+
+func (i *closureSetIterator[T]) Next() bool {
+	for {
+		if i.err != nil {
+			panic(i.err)
+		}
+		if i.i.Next() {
+			if v := i.i.Value(); !i.seen.Has(v) {
+				i.seen.Add(v)
+				i.delta.Add(v)
+				i.value = v
+				return true
+			}
+			continue
+		}
+		delta := i.delta.Finish()
+		if delta.IsEmpty() {
+			return false
+		}
+		if err := i.s.ctx.Err(); err != nil {
+			i.err = fmt.Errorf("Closure(): %w", err)
+			continue
+		}
+		if i.round++; i.s.limit > 0 && i.round > i.s.limit {
+			i.err = fmt.Errorf("Closure(): %w", frozen.ErrIterationLimit)
+			continue
+		}
+		i.delta = frozen.SetBuilder[T]{}
+		i.i = i.s.step(From(delta)).Range()
+	}
+}
+
+func (i *closureSetIterator[T]) Value() T {
+	return i.value
+}
+
+// TransitiveClosure returns the transitive closure of edges, i.e., a Pair for
+// every pair of nodes with a path of one or more edges from First to Second.
+// edges must be finite.
+func TransitiveClosure[K any](edges SetOf[Pair[K, K]]) SetOf[Pair[K, K]] {
+	var once sync.Once
+	var from frozen.Map[K, frozen.Set[Pair[K, K]]]
+	step := func(delta SetOf[Pair[K, K]]) SetOf[Pair[K, K]] {
+		once.Do(func() {
+			from = frozen.SetGroupBy(edges.Freeze(), func(e Pair[K, K]) K { return e.First })
+		})
+		var b frozen.SetBuilder[Pair[K, K]]
+		for i := delta.Range(); i.Next(); {
+			path := i.Value()
+			for j := from.GetElse(path.Second, frozen.Set[Pair[K, K]]{}).Range(); j.Next(); {
+				b.Add(Pair[K, K]{path.First, j.Value().Second})
+			}
+		}
+		return From(b.Finish())
+	}
+	return Closure(edges, step)
+}
+
+// TransitiveClosureContext is TransitiveClosure with cancellation and an
+// iteration limit, as in frozen.TransitiveClosureContext. Unlike
+// TransitiveClosure, it evaluates the closure before returning, so that it can
+// return an error instead of panicking later. On error, it returns the paths
+// found so far.
+func TransitiveClosureContext[K any](
+	ctx context.Context,
+	edges SetOf[Pair[K, K]],
+	limit int,
+) (SetOf[Pair[K, K]], error) {
+	from := frozen.SetGroupBy(edges.Freeze(), func(e Pair[K, K]) K { return e.First })
+	m := frozen.MapMap(from, func(_ K, g frozen.Set[Pair[K, K]]) frozen.Set[K] {
+		return frozen.SetMap(g, func(e Pair[K, K]) K { return e.Second })
+	})
+	reach, err := frozen.TransitiveClosureContext(ctx, m, limit)
+	var paths frozen.SetBuilder[Pair[K, K]]
+	for i := reach.Range(); i.Next(); {
+		for j := i.Value().Range(); j.Next(); {
+			paths.Add(Pair[K, K]{i.Key(), j.Value()})
+		}
+	}
+	return From(paths.Finish()), err
+}
+
+// TransitiveClosureMap is TransitiveClosure for graphs with edges from each
+// key of m to the elements of its value. The result maps each node to all the
+// nodes reachable from it. Nodes with no outgoing edges are omitted.
+func TransitiveClosureMap[K any](m Map[K, SetOf[K]]) Map[K, SetOf[K]] {
+	var i MapIterator[K, SetOf[K]]
+	var j frozen.Iterator[K]
+	edges := generate(func() (Pair[K, K], bool) {
+		if i == nil {
+			i = m.Range()
+		}
+		for j == nil || !j.Next() {
+			if !i.Next() {
+				return Pair[K, K]{}, false
+			}
+			j = i.Value().Range()
+		}
+		return Pair[K, K]{i.Key(), j.Value()}, true
+	}, finite)
+	paths := TransitiveClosure(edges)
+	groups := GroupBy(paths, func(p Pair[K, K]) K { return p.First })
+	return MapValues(groups, func(_ K, g SetOf[Pair[K, K]]) SetOf[K] {
+		return SetMap(g, func(p Pair[K, K]) K { return p.Second })
+	})
+}
+
+// TransitiveClosureMapContext is TransitiveClosureMap with cancellation and an
+// iteration limit. Like TransitiveClosureContext, it evaluates the closure
+// before returning, and on error returns the reachability found so far.
+func TransitiveClosureMapContext[K any](
+	ctx context.Context,
+	m Map[K, SetOf[K]],
+	limit int,
+) (Map[K, SetOf[K]], error) {
+	var b frozen.MapBuilder[K, frozen.Set[K]]
+	for i := m.Range(); i.Next(); {
+		b.Put(i.Key(), i.Value().Freeze())
+	}
+	reach, err := frozen.TransitiveClosureContext(ctx, b.Finish(), limit)
+	return FromMap(frozen.MapMap(reach, func(_ K, v frozen.Set[K]) SetOf[K] { return From(v) })), err
+}
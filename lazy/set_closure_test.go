@@ -0,0 +1,116 @@
+package lazy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+func double(delta lazy.SetOf[int]) lazy.SetOf[int] {
+	return lazy.SetMap(delta, func(el int) int { return 2 * el })
+}
+
+func TestClosure(t *testing.T) {
+	t.Parallel()
+
+	belowHundred := func(delta lazy.SetOf[int]) lazy.SetOf[int] {
+		return double(delta).Where(func(el int) bool { return el < 100 })
+	}
+	c := lazy.Closure(lazy.From(frozen.NewSet(1)), belowHundred)
+	test.Equal(t, "closure\n  frozen (1 elements)", c.Explain())
+	test.True(t, c.Freeze().Equal(frozen.NewSet(1, 2, 4, 8, 16, 32, 64)))
+	assertFastIsEmpty(t, lazy.Closure(lazy.Empty[int](), belowHundred))
+
+	// An unbounded closure can still be iterated.
+	test.Equal(t, 5, lazy.Closure(lazy.From(frozen.NewSet(1)), double).CountUpTo(5))
+	test.True(t, lazy.Closure(lazy.From(frozen.NewSet(3)), double).Has(48))
+}
+
+func TestClosureContext(t *testing.T) {
+	t.Parallel()
+
+	c := lazy.ClosureContext(context.Background(), lazy.From(frozen.NewSet(1)), double, 3)
+	test.Equal(t, "closure (limit 3 rounds)\n  frozen (1 elements)", c.Explain())
+	test.Equal(t, 4, c.CountUpTo(4))
+	test.Panic(t, func() { c.Count() })
+
+	defer func() {
+		err, ok := recover().(error)
+		test.True(t, ok && errors.Is(err, frozen.ErrIterationLimit))
+	}()
+	c.Count()
+}
+
+func TestClosureContextCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := lazy.ClosureContext(ctx, lazy.From(frozen.NewSet(1)), double, 0)
+	defer func() {
+		err, ok := recover().(error)
+		test.True(t, ok && errors.Is(err, context.Canceled))
+	}()
+	c.Count()
+}
+
+func TestTransitiveClosure(t *testing.T) {
+	t.Parallel()
+
+	type edge = lazy.Pair[int, int]
+	edges := lazy.From(frozen.NewSet(edge{1, 2}, edge{2, 3}, edge{3, 4}, edge{4, 2}))
+	tc := lazy.TransitiveClosure(edges)
+	test.True(t, tc.Freeze().Equal(frozen.NewSet(
+		edge{1, 2}, edge{1, 3}, edge{1, 4},
+		edge{2, 2}, edge{2, 3}, edge{2, 4},
+		edge{3, 2}, edge{3, 3}, edge{3, 4},
+		edge{4, 2}, edge{4, 3}, edge{4, 4},
+	)))
+
+	partial, err := lazy.TransitiveClosureContext(context.Background(), edges, 1)
+	test.True(t, errors.Is(err, frozen.ErrIterationLimit))
+	test.True(t, partial.Freeze().IsSubsetOf(tc.Freeze()))
+
+	full, err := lazy.TransitiveClosureContext(context.Background(), edges, 0)
+	test.NoError(t, err)
+	test.True(t, full.Freeze().Equal(tc.Freeze()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = lazy.TransitiveClosureContext(ctx, edges, 0)
+	test.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestTransitiveClosureMap(t *testing.T) {
+	t.Parallel()
+
+	single := func(el int) lazy.SetOf[int] { return lazy.From(frozen.NewSet(el)) }
+	m := lazy.FromMap(frozen.NewMap(
+		frozen.KV(1, single(2)),
+		frozen.KV(2, single(3)),
+		frozen.KV(3, single(4)),
+		frozen.KV(4, lazy.Empty[int]()),
+	))
+	tc := lazy.TransitiveClosureMap(m)
+	reach, has := tc.Get(1)
+	test.True(t, has)
+	test.True(t, reach.Freeze().Equal(frozen.NewSet(2, 3, 4)))
+	reach, has = tc.Get(3)
+	test.True(t, has)
+	test.True(t, reach.Freeze().Equal(frozen.NewSet(4)))
+	test.False(t, tc.Has(4))
+
+	tc, err := lazy.TransitiveClosureMapContext(context.Background(), m, 0)
+	test.NoError(t, err)
+	reach, has = tc.Get(1)
+	test.True(t, has)
+	test.True(t, reach.Freeze().Equal(frozen.NewSet(2, 3, 4)))
+	test.False(t, tc.Has(4))
+
+	_, err = lazy.TransitiveClosureMapContext(context.Background(), m, 1)
+	test.True(t, errors.Is(err, frozen.ErrIterationLimit))
+}
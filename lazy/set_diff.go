@@ -1,53 +1,79 @@
 package lazy
 
-type differenceSet struct {
-	baseSet
-	a, b Set
+import "github.com/arr-ai/frozen"
+
+type differenceSet[T any] struct {
+	baseSet[T]
+	a, b SetOf[T]
 }
 
-func difference(a, b Set) Set {
-	if empty, ok := a.FastIsEmpty(); ok && empty {
-		return a
-	}
-	if empty, ok := b.FastIsEmpty(); ok && empty {
+func difference[T any](a, b SetOf[T]) SetOf[T] {
+	if fastIsEmpty(a) || fastIsEmpty(b) {
 		return a
 	}
-	s := &differenceSet{a: a, b: b}
+	s := &differenceSet[T]{a: a, b: b}
 	s.baseSet.set = s
-	return memo(s)
+	return memo[T](s)
+}
+
+func (s *differenceSet[T]) extent() extent {
+	return extentOf(s.a)
+}
+
+func (s *differenceSet[T]) plan() (string, []any) {
+	return "difference", []any{s.a, s.b}
 }
 
-func (s *differenceSet) Has(el any) bool {
-	return s.a.Has(el) || s.b.Has(el)
+func (s *differenceSet[T]) FastIsEmpty() (empty, ok bool) {
+	if fastIsEmpty(s.a) {
+		return true, true
+	}
+	return false, false
+}
+
+func (s *differenceSet[T]) Has(el T) bool {
+	if has, ok := s.FastHas(el); ok {
+		return has
+	}
+	return s.a.Has(el) && !s.b.Has(el)
 }
 
-func (s *differenceSet) FastHas(el any) (has, ok bool) {
-	if has, ok = s.a.FastHas(el); ok {
-		return
+func (s *differenceSet[T]) hasFromInputs(el T) bool {
+	return s.Has(el)
+}
+
+func (s *differenceSet[T]) FastHas(el T) (has, ok bool) {
+	aHas, aOk := s.a.FastHas(el)
+	if aOk && !aHas {
+		return false, true
+	}
+	bHas, bOk := s.b.FastHas(el)
+	if bOk && bHas {
+		return false, true
 	}
-	return s.a.FastHas(el)
+	return aHas && !bHas, aOk && bOk
 }
 
-func (s *differenceSet) Range() SetIterator {
-	return &differenceSetIterator{i: s.a.Range(), b: s.b}
+func (s *differenceSet[T]) Range() frozen.Iterator[T] {
+	return &differenceSetIterator[T]{i: s.a.Range(), b: s.b}
 }
 
-type differenceSetIterator struct {
-	i SetIterator
-	b Set
+type differenceSetIterator[T any] struct {
+	i frozen.Iterator[T]
+	b SetOf[T]
 }
 
-func (i *differenceSetIterator) Next() bool {
+func (i *differenceSetIterator[T]) Next() bool {
 	for {
 		if !i.i.Next() {
 			return false
 		}
-		if !i.b.Has(i.i.Value()) {
+		if !probe(i.b, i.i.Value()) {
 			return true
 		}
 	}
 }
 
-func (i *differenceSetIterator) Value() any {
+func (i *differenceSetIterator[T]) Value() T {
 	return i.i.Value()
 }
@@ -0,0 +1,25 @@
+package lazy_test
+
+import (
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+func TestSetDifferenceHas(t *testing.T) {
+	t.Parallel()
+
+	s := lazy.From(frozen.NewSet(1, 2, 3, 4, 5))
+	odd := s.Where(func(el int) bool { return el%2 == 1 })
+	other := lazy.From(frozen.NewSet(4, 5, 6, 7))
+
+	diff := odd.Difference(other)
+	test.True(t, diff.Has(1))
+	test.False(t, diff.Has(5))
+	test.False(t, diff.Has(6))
+	assertFastNotHas(t, s.Difference(other), 5)
+	assertFastNotHas(t, s.Difference(other), 6)
+	assertFastHas(t, s.Difference(other), 1)
+}
@@ -6,109 +6,114 @@ import (
 	"github.com/arr-ai/frozen"
 )
 
-type EmptySet struct{}
+// Empty returns the empty SetOf[T].
+func Empty[T any]() SetOf[T] {
+	return emptySet[T]{}
+}
+
+type emptySet[T any] struct{}
 
-func (EmptySet) IsEmpty() bool {
+func (emptySet[T]) IsEmpty() bool {
 	return true
 }
 
-func (EmptySet) FastIsEmpty() (empty, ok bool) {
+func (emptySet[T]) FastIsEmpty() (empty, ok bool) {
 	return true, true
 }
 
-func (EmptySet) Count() int {
+func (emptySet[T]) Count() int {
 	return 0
 }
 
-func (EmptySet) FastCount() (count int, ok bool) {
+func (emptySet[T]) FastCount() (count int, ok bool) {
 	return 0, true
 }
 
-func (EmptySet) CountUpTo(int) int {
+func (emptySet[T]) CountUpTo(int) int {
 	return 0
 }
 
-func (EmptySet) FastCountUpTo(int) (count int, ok bool) {
+func (emptySet[T]) FastCountUpTo(int) (count int, ok bool) {
 	return 0, true
 }
 
-func (EmptySet) Freeze() Set {
-	return Frozen(frozen.Set[any]{})
+func (emptySet[T]) Freeze() frozen.Set[T] {
+	return frozen.Set[T]{}
 }
 
-func (EmptySet) Equal(set any) bool {
-	if set, ok := set.(Set); ok {
-		return set.EqualSet(set)
+func (emptySet[T]) Equal(set any) bool {
+	if set, ok := set.(SetOf[T]); ok {
+		return set.IsEmpty()
 	}
 	return false
 }
 
-func (EmptySet) EqualSet(set Set) bool {
+func (emptySet[T]) EqualSet(set SetOf[T]) bool {
 	return set.IsEmpty()
 }
 
-func (EmptySet) Hash(seed uintptr) uintptr {
+func (emptySet[T]) Hash(seed uintptr) uintptr {
 	return hash.Uintptr(hashSeed, seed)
 }
 
-func (EmptySet) Has(any) bool {
+func (emptySet[T]) Has(T) bool {
 	return false
 }
 
-func (EmptySet) FastHas(any) (has, ok bool) {
+func (emptySet[T]) FastHas(T) (has, ok bool) {
 	return false, true
 }
 
-func (EmptySet) IsSubsetOf(Set) bool {
+func (emptySet[T]) IsSubsetOf(SetOf[T]) bool {
 	return true
 }
 
-func (EmptySet) Range() SetIterator {
-	return emptySetIterator{}
-}
-
-func (EmptySet) Where(Predicate) Set {
-	return EmptySet{}
+func (emptySet[T]) Range() frozen.Iterator[T] {
+	return emptySetIterator[T]{}
 }
 
-func (EmptySet) With(v any) Set {
-	return Frozen(frozen.NewSet(v))
+func (emptySet[T]) Where(func(el T) bool) SetOf[T] {
+	return emptySet[T]{}
 }
 
-func (EmptySet) Without(any) Set {
-	return EmptySet{}
+func (emptySet[T]) With(v T) SetOf[T] {
+	return From(frozen.NewSet(v))
 }
 
-func (EmptySet) Map(Mapper) Set {
-	return EmptySet{}
+func (emptySet[T]) Without(T) SetOf[T] {
+	return emptySet[T]{}
 }
 
-func (EmptySet) Union(s Set) Set {
+func (emptySet[T]) Union(s SetOf[T]) SetOf[T] {
 	return s
 }
 
-func (EmptySet) Intersection(Set) Set {
-	return EmptySet{}
+func (emptySet[T]) Intersection(SetOf[T]) SetOf[T] {
+	return emptySet[T]{}
 }
 
-func (EmptySet) Difference(Set) Set {
-	return EmptySet{}
+func (emptySet[T]) Difference(SetOf[T]) SetOf[T] {
+	return emptySet[T]{}
 }
 
-func (EmptySet) SymmetricDifference(s Set) Set {
+func (emptySet[T]) SymmetricDifference(s SetOf[T]) SetOf[T] {
 	return s
 }
 
-func (EmptySet) Powerset() Set {
-	return Frozen(frozen.NewSet[any](EmptySet{}))
+func (emptySet[T]) Explain() string {
+	return "empty"
+}
+
+func (emptySet[T]) plan() (string, []any) {
+	return "empty", nil
 }
 
-type emptySetIterator struct{}
+type emptySetIterator[T any] struct{}
 
-func (emptySetIterator) Next() bool {
+func (emptySetIterator[T]) Next() bool {
 	return false
 }
 
-func (emptySetIterator) Value() any {
+func (emptySetIterator[T]) Value() T {
 	panic("emptySetIterator.Value(): empty set")
 }
@@ -4,13 +4,14 @@ import (
 	"testing"
 
 	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
 	"github.com/arr-ai/frozen/lazy"
 )
 
 func TestSetEmpty(t *testing.T) {
 	t.Parallel()
 
-	s := lazy.EmptySet{}
+	s := lazy.Empty[any]()
 
 	assertSetOps(t, frozen.Set[any]{}, s)
 
@@ -23,8 +24,19 @@ func TestSetEmpty(t *testing.T) {
 	assertFastNotIsEmpty(t, s.With(2))
 	assertFastIsEmpty(t, s.Without(2))
 	assertFastIsEmpty(t, s.With(2).Without(2))
-	assertFastIsEmpty(t, s.Map(func(any) any { return 42 }))
-	assertFastIsEmpty(t, s.Intersection(lazy.Frozen(frozen.NewSet[any](1, 2, 3))))
-	assertFastIsEmpty(t, s.Intersection(lazy.Frozen(frozen.NewSet[any](1, 2, 3))))
-	assertFastNotIsEmpty(t, s.Powerset())
+	assertFastIsEmpty(t, lazy.SetMap(s, func(any) any { return 42 }))
+	assertFastIsEmpty(t, s.Intersection(lazy.From(frozen.NewSet[any](1, 2, 3))))
+	assertFastIsEmpty(t, s.Intersection(lazy.From(frozen.NewSet[any](1, 2, 3))))
+	assertFastNotIsEmpty(t, lazy.Powerset(s))
+}
+
+func TestSetEmptyEqual(t *testing.T) {
+	t.Parallel()
+
+	e := lazy.Empty[string]()
+	test.True(t, e.Equal(lazy.From(frozen.Set[string]{})))
+	test.False(t, e.Equal(lazy.From(frozen.NewSet("x"))))
+	test.False(t, e.Equal(lazy.Empty[int]()))
+	test.True(t, lazy.EmptySet{}.Equal(lazy.Frozen(frozen.Set[any]{})))
+	test.False(t, lazy.EmptySet{}.Equal(lazy.Frozen(frozen.NewSet[any](1))))
 }
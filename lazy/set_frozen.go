@@ -1,44 +1,69 @@
 package lazy
 
-import "github.com/arr-ai/frozen"
+import (
+	"fmt"
 
-type frozenSet struct {
-	baseSet
-	set frozen.Set[any]
+	"github.com/arr-ai/frozen"
+)
+
+type frozenSet[T any] struct {
+	baseSet[T]
+	set frozen.Set[T]
 }
 
-func Frozen(set frozen.Set[any]) Set {
-	s := &frozenSet{set: set}
+// From returns a Set with the elements of set.
+func From[T any](set frozen.Set[T]) SetOf[T] {
+	s := &frozenSet[T]{set: set}
 	s.baseSet.set = s
 	return s
 }
 
-func (s *frozenSet) String() string {
+// frozenOf returns the frozen.Set behind s, if s is or has been memoized as
+// one.
+func frozenOf[T any](s SetOf[T]) (frozen.Set[T], bool) {
+	if m, ok := s.(*memoSet[T]); ok {
+		s = m.getSet()
+	}
+	if f, ok := s.(*frozenSet[T]); ok {
+		return f.set, true
+	}
+	return frozen.Set[T]{}, false
+}
+
+func (s *frozenSet[T]) String() string {
 	return s.set.String()
 }
 
-func (s *frozenSet) FastIsEmpty() (empty, ok bool) {
+func (s *frozenSet[T]) plan() (string, []any) {
+	return fmt.Sprintf("frozen (%d elements)", s.set.Count()), nil
+}
+
+func (s *frozenSet[T]) FastIsEmpty() (empty, ok bool) {
 	return s.set.IsEmpty(), true
 }
 
-func (s *frozenSet) FastCount() (count int, ok bool) {
+func (s *frozenSet[T]) FastCount() (count int, ok bool) {
 	return s.set.Count(), true
 }
 
-func (s *frozenSet) FastCountUpTo(limit int) (count int, ok bool) {
+func (s *frozenSet[T]) FastCountUpTo(limit int) (count int, ok bool) {
 	if n := s.set.Count(); n < limit {
 		return n, true
 	}
 	return limit, true
 }
 
-func (s *frozenSet) FastHas(el any) (has, ok bool) {
+func (s *frozenSet[T]) FastHas(el T) (has, ok bool) {
 	return s.set.Has(el), true
 }
 
-func (s *frozenSet) EqualSet(set Set) bool {
-	if f, ok := set.(*frozenSet); ok {
-		return s.set.Equal(f.set)
+func (s *frozenSet[T]) Freeze() frozen.Set[T] {
+	return s.set
+}
+
+func (s *frozenSet[T]) EqualSet(set SetOf[T]) bool {
+	if f, ok := frozenOf(set); ok {
+		return s.set.Equal(f)
 	}
 	n := s.set.Count()
 	i := set.Range()
@@ -50,11 +75,9 @@ func (s *frozenSet) EqualSet(set Set) bool {
 	return n == 0 && !i.Next()
 }
 
-func (s *frozenSet) IsSubsetOf(set Set) bool {
-	if m, ok := set.(*memoSet); ok {
-		if f, ok := m.getSet().(*frozenSet); ok {
-			return s.IsSubsetOf(f)
-		}
+func (s *frozenSet[T]) IsSubsetOf(set SetOf[T]) bool {
+	if f, ok := frozenOf(set); ok {
+		return s.set.IsSubsetOf(f)
 	}
 
 	n := s.set.Count()
@@ -66,18 +89,14 @@ func (s *frozenSet) IsSubsetOf(set Set) bool {
 	return n == 0
 }
 
-func (s *frozenSet) Range() SetIterator {
+func (s *frozenSet[T]) Range() frozen.Iterator[T] {
 	return s.set.Range()
 }
 
-func (s *frozenSet) With(v any) Set {
-	return Frozen(s.set.With(v))
-}
-
-func (s *frozenSet) Without(v any) Set {
-	return Frozen(s.set.Without(v))
+func (s *frozenSet[T]) With(v T) SetOf[T] {
+	return From(s.set.With(v))
 }
 
-func (s *frozenSet) Map(m Mapper) Set {
-	return Frozen(frozen.SetMap(s.set, m))
+func (s *frozenSet[T]) Without(v T) SetOf[T] {
+	return From(s.set.Without(v))
 }
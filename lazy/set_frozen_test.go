@@ -11,7 +11,7 @@ func TestSetFrozenEmpty(t *testing.T) {
 	t.Parallel()
 
 	f := frozen.Set[any]{}
-	s := lazy.Frozen(f)
+	s := lazy.From(f)
 
 	assertSetOps(t, f, s)
 
@@ -24,17 +24,17 @@ func TestSetFrozenEmpty(t *testing.T) {
 	assertFastNotIsEmpty(t, s.With(2))
 	assertFastIsEmpty(t, s.Without(2))
 	assertFastIsEmpty(t, s.With(2).Without(2))
-	assertFastIsEmpty(t, s.Map(func(any) any { return 42 }))
-	assertFastIsEmpty(t, s.Intersection(lazy.Frozen(frozen.NewSet[any](1, 2, 3))))
-	assertFastIsEmpty(t, s.Intersection(lazy.Frozen(frozen.NewSet[any](1, 2, 3))))
-	assertFastIsEmpty(t, s.Powerset())
+	assertFastIsEmpty(t, lazy.SetMap(s, func(any) any { return 42 }))
+	assertFastIsEmpty(t, s.Intersection(lazy.From(frozen.NewSet[any](1, 2, 3))))
+	assertFastIsEmpty(t, s.Intersection(lazy.From(frozen.NewSet[any](1, 2, 3))))
+	assertFastNotIsEmpty(t, lazy.Powerset(s))
 }
 
 func TestSetFrozenSmall(t *testing.T) {
 	t.Parallel()
 
 	f := frozen.NewSet[any](1, 2, 3)
-	s := lazy.Frozen(f)
+	s := lazy.From(f)
 
 	assertSetOps(t, f, s)
 
@@ -48,5 +48,5 @@ func TestSetFrozenSmall(t *testing.T) {
 	assertFastNotIsEmpty(t, s.With(2))
 	assertFastNotIsEmpty(t, s.Without(1).Without(2).Without(4))
 	assertFastIsEmpty(t, s.Without(1).Without(2).Without(3))
-	assertFastNotIsEmpty(t, s.Map(func(any) any { return 42 }))
+	assertFastNotIsEmpty(t, lazy.SetMap(s, func(any) any { return 42 }))
 }
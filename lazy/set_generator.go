@@ -0,0 +1,83 @@
+package lazy
+
+import "github.com/arr-ai/frozen"
+
+// generatorSet yields the values returned by next. It can only be iterated
+// once, so it is always memoized, and the memoSet buffers the elements for
+// replay.
+type generatorSet[T any] struct {
+	baseSet[T]
+	next func() (T, bool)
+	e    extent
+}
+
+// FromFunc returns a Set of the elements returned by next, which is called as
+// elements are needed until it returns false. Since next might never do so,
+// operations that need every element, such as Count, Freeze and Has for an
+// element not yet generated, panic with ErrInfiniteSet until the Set has been
+// iterated to its end. CountUpTo and Range are always safe.
+func FromFunc[T any](next func() (T, bool)) SetOf[T] {
+	return generate(next, unbounded)
+}
+
+// FromFiniteFunc is like FromFunc, for a next that is known to return false
+// eventually. Count, Freeze and Has run it to the end if they need to.
+func FromFiniteFunc[T any](next func() (T, bool)) SetOf[T] {
+	return generate(next, finite)
+}
+
+// generate returns a Set of the elements returned by next, with extent e.
+func generate[T any](next func() (T, bool), e extent) SetOf[T] {
+	s := &generatorSet[T]{next: next, e: e}
+	s.baseSet.set = s
+	return memo[T](s)
+}
+
+// FromChannel returns a Set of the elements received from ch until it is
+// closed. As with FromFunc, elements are received as they are needed, and
+// operations that need every element panic until ch has been drained.
+func FromChannel[T any](ch <-chan T) SetOf[T] {
+	return FromFunc(func() (T, bool) {
+		v, ok := <-ch
+		return v, ok
+	})
+}
+
+func (s *generatorSet[T]) extent() extent {
+	return s.e
+}
+
+func (s *generatorSet[T]) plan() (string, []any) {
+	return "generator", nil
+}
+
+func (s *generatorSet[T]) Range() frozen.Iterator[T] {
+	next := s.next
+	if next == nil {
+		panic("generatorSet.Range(): already iterated")
+	}
+	s.next = nil
+	return &generatorSetIterator[T]{next: next}
+}
+
+type generatorSetIterator[T any] struct {
+	next  func() (T, bool)
+	value T
+}
+
+func (i *generatorSetIterator[T]) Next() bool {
+	if i.next == nil {
+		return false
+	}
+	v, ok := i.next()
+	if !ok {
+		i.next = nil
+		return false
+	}
+	i.value = v
+	return true
+}
+
+func (i *generatorSetIterator[T]) Value() T {
+	return i.value
+}
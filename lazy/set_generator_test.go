@@ -0,0 +1,100 @@
+package lazy_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+func TestFromFunc(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	s := lazy.FromFunc(func() (int, bool) {
+		calls++
+		return calls % 4, calls <= 10
+	})
+	test.Equal(t, 0, calls)
+	test.Equal(t, []int{1, 2}, firstN(s, 2))
+	test.Equal(t, 2, calls)
+	test.True(t, s.Has(2))
+	test.Equal(t, 2, calls)
+
+	// Until the generator ends, it might not, so counting and freezing fail.
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { s.Count() })
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { s.Freeze() })
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { s.Has(0) })
+	test.Equal(t, 2, calls)
+
+	test.Equal(t, 4, s.CountUpTo(100))
+	test.Equal(t, 11, calls)
+	test.Equal(t, 4, s.Count())
+	test.True(t, s.Freeze().Equal(frozen.NewSet(0, 1, 2, 3)))
+	assertFastCountEqual(t, 4, s)
+	test.Equal(t, 11, calls)
+}
+
+func TestFromFiniteFunc(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	s := lazy.FromFiniteFunc(func() (int, bool) {
+		calls++
+		return calls % 4, calls <= 10
+	})
+	test.Equal(t, 0, calls)
+	test.Equal(t, 4, s.Count())
+	test.Equal(t, 11, calls)
+	test.True(t, s.Freeze().Equal(frozen.NewSet(0, 1, 2, 3)))
+	test.False(t, s.Has(4))
+}
+
+func TestFromFuncInfinite(t *testing.T) {
+	t.Parallel()
+
+	i := 0
+	s := lazy.FromFunc(func() (int, bool) {
+		i++
+		return i * i, true
+	})
+	test.Equal(t, []int{1, 4, 9}, firstN(s, 3))
+	test.Equal(t, 100, s.CountUpTo(100))
+	test.True(t, s.Has(10000))
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { s.Has(10001) })
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { s.Count() })
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { s.Freeze() })
+	test.True(t, s.Intersection(lazy.From(frozen.NewSet(16, 17))).Has(16))
+}
+
+func TestFromChannel(t *testing.T) {
+	t.Parallel()
+
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for _, s := range []string{"a", "b", "a", "c"} {
+			ch <- s
+		}
+	}()
+	s := lazy.FromChannel(ch)
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { s.Count() })
+
+	// Concurrent iterations all see every element.
+	var wg sync.WaitGroup
+	for n := 0; n < 4; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var b frozen.SetBuilder[string]
+			for i := s.Range(); i.Next(); {
+				b.Add(i.Value())
+			}
+			test.True(t, b.Finish().Equal(frozen.NewSet("a", "b", "c")))
+		}()
+	}
+	wg.Wait()
+	test.Equal(t, 3, s.Count())
+}
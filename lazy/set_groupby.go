@@ -0,0 +1,176 @@
+package lazy
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/value"
+)
+
+type groupBySet[T, K any] struct {
+	baseSet[frozen.KeyValue[K, SetOf[T]]]
+	src SetOf[T]
+	key func(el T) K
+
+	once    sync.Once
+	grouped atomic.Bool
+	groups  frozen.Map[K, frozen.Set[T]]
+}
+
+// GroupBy returns a Map from the keys of the elements of s to the groups of
+// elements that have each key. The groups are lazy: membership tests use key
+// and s directly, and s is only grouped, once, when the groups or the keys are
+// iterated or counted.
+func GroupBy[T, K any](s SetOf[T], key func(el T) K) Map[K, SetOf[T]] {
+	if fastIsEmpty(s) {
+		return MapFromEntries(Empty[frozen.KeyValue[K, SetOf[T]]]())
+	}
+	g := &groupBySet[T, K]{src: s, key: key}
+	g.baseSet.set = g
+	return &lazyMap[K, SetOf[T]]{entries: g, get: g.get}
+}
+
+func (s *groupBySet[T, K]) get(k K) (group SetOf[T], has, ok bool) {
+	if !s.grouped.Load() {
+		return nil, false, false
+	}
+	if _, has := s.groups.Get(k); has {
+		return s.groupSet(k), true, true
+	}
+	return nil, false, true
+}
+
+func (s *groupBySet[T, K]) groupSet(k K) SetOf[T] {
+	g := &groupSet[T, K]{parent: s, k: k}
+	g.baseSet.set = g
+	return g
+}
+
+func (s *groupBySet[T, K]) group() frozen.Map[K, frozen.Set[T]] {
+	s.once.Do(func() {
+		s.groups = frozen.SetGroupBy(s.src.Freeze(), s.key)
+		s.grouped.Store(true)
+	})
+	return s.groups
+}
+
+func (s *groupBySet[T, K]) extent() extent {
+	return extentOf(s.src)
+}
+
+func (s *groupBySet[T, K]) plan() (string, []any) {
+	if s.grouped.Load() {
+		return "group by (grouped)", []any{s.src}
+	}
+	return "group by", []any{s.src}
+}
+
+func (s *groupBySet[T, K]) FastIsEmpty() (empty, ok bool) {
+	return s.src.FastIsEmpty()
+}
+
+func (s *groupBySet[T, K]) FastCount() (count int, ok bool) {
+	if s.grouped.Load() {
+		return s.groups.Count(), true
+	}
+	return 0, false
+}
+
+func (s *groupBySet[T, K]) FastCountUpTo(limit int) (count int, ok bool) {
+	if count, ok := s.FastCount(); ok {
+		if count < limit {
+			return count, true
+		}
+		return limit, true
+	}
+	return 0, false
+}
+
+func (s *groupBySet[T, K]) Range() frozen.Iterator[frozen.KeyValue[K, SetOf[T]]] {
+	return &groupBySetIterator[T, K]{s: s, i: s.group().Range()}
+}
+
+type groupBySetIterator[T, K any] struct {
+	s *groupBySet[T, K]
+	i frozen.MapIterator[K, frozen.Set[T]]
+}
+
+func (i *groupBySetIterator[T, K]) Next() bool {
+	return i.i.Next()
+}
+
+func (i *groupBySetIterator[T, K]) Value() frozen.KeyValue[K, SetOf[T]] {
+	return frozen.KV(i.i.Key(), i.s.groupSet(i.i.Key()))
+}
+
+// groupSet is the group of elements of a groupBySet with key k.
+type groupSet[T, K any] struct {
+	baseSet[T]
+	parent *groupBySet[T, K]
+	k      K
+}
+
+func (s *groupSet[T, K]) extent() extent {
+	return extentOf(s.parent.src)
+}
+
+func (s *groupSet[T, K]) plan() (string, []any) {
+	return "group", []any{s.parent}
+}
+
+func (s *groupSet[T, K]) frozen() (frozen.Set[T], bool) {
+	if s.parent.grouped.Load() {
+		g, _ := s.parent.groups.Get(s.k)
+		return g, true
+	}
+	return frozen.Set[T]{}, false
+}
+
+func (s *groupSet[T, K]) FastIsEmpty() (empty, ok bool) {
+	if g, ok := s.frozen(); ok {
+		return g.IsEmpty(), true
+	}
+	return false, false
+}
+
+func (s *groupSet[T, K]) FastCount() (count int, ok bool) {
+	if g, ok := s.frozen(); ok {
+		return g.Count(), true
+	}
+	return 0, false
+}
+
+func (s *groupSet[T, K]) FastCountUpTo(limit int) (count int, ok bool) {
+	if count, ok := s.FastCount(); ok {
+		if count < limit {
+			return count, true
+		}
+		return limit, true
+	}
+	return 0, false
+}
+
+func (s *groupSet[T, K]) Has(el T) bool {
+	return value.Equal(s.parent.key(el), s.k) && s.parent.src.Has(el)
+}
+
+func (s *groupSet[T, K]) FastHas(el T) (has, ok bool) {
+	if !value.Equal(s.parent.key(el), s.k) {
+		return false, true
+	}
+	if g, ok := s.frozen(); ok {
+		return g.Has(el), true
+	}
+	return s.parent.src.FastHas(el)
+}
+
+func (s *groupSet[T, K]) Freeze() frozen.Set[T] {
+	s.parent.group()
+	g, _ := s.frozen()
+	return g
+}
+
+func (s *groupSet[T, K]) Range() frozen.Iterator[T] {
+	return s.Freeze().Range()
+}
@@ -0,0 +1,56 @@
+package lazy_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+func TestGroupBy(t *testing.T) {
+	t.Parallel()
+
+	var keys int64
+	mod3 := func(el int) int {
+		atomic.AddInt64(&keys, 1)
+		return el % 3
+	}
+	g := lazy.GroupBy(lazy.From(frozen.Iota(10)), mod3)
+	_, ok := g.Entries().FastCount()
+	test.False(t, ok)
+	test.Equal(t, "group by\n  frozen (10 elements)", g.Explain())
+	test.Equal(t, int64(0), atomic.LoadInt64(&keys))
+
+	groups := map[int]lazy.SetOf[int]{}
+	for i := g.Range(); i.Next(); {
+		groups[i.Key()] = i.Value()
+	}
+	test.Equal(t, int64(10), atomic.LoadInt64(&keys))
+	assertFastCountEqual(t, 3, g.Entries())
+	test.Equal(t, 3, len(groups))
+	test.True(t, groups[0].Freeze().Equal(frozen.NewSet(0, 3, 6, 9)))
+	test.True(t, groups[1].Freeze().Equal(frozen.NewSet(1, 4, 7)))
+	assertFastCountEqual(t, 3, groups[2])
+
+	// Iterating again doesn't regroup.
+	total := 0
+	for i := g.Range(); i.Next(); {
+		total += i.Value().Count()
+	}
+	test.Equal(t, 10, total)
+	test.Equal(t, int64(10), atomic.LoadInt64(&keys))
+
+	assertFastHas(t, groups[2], 5)
+	assertFastNotHas(t, groups[2], 4)
+	assertFastNotHas(t, groups[2], 11)
+
+	group, has, ok := g.FastGet(1)
+	test.True(t, has && ok)
+	test.True(t, group.Freeze().Equal(frozen.NewSet(1, 4, 7)))
+	_, has, ok = g.FastGet(3)
+	test.True(t, !has && ok)
+
+	assertFastIsEmpty(t, lazy.GroupBy(lazy.Empty[int](), mod3).Entries())
+}
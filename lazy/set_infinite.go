@@ -0,0 +1,176 @@
+package lazy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/arr-ai/frozen"
+)
+
+var (
+	// ErrInfiniteSet is panicked by operations such as Count and Freeze that
+	// would never return on a Set that might be infinite. CountUpTo is safe.
+	ErrInfiniteSet = errors.New("lazy: set might be infinite")
+
+	// ErrNotEnumerable is panicked by Range and its dependents on Sets that
+	// only support membership tests, such as those from FromPredicate.
+	ErrNotEnumerable = errors.New("lazy: set is not enumerable")
+)
+
+// extent describes how far a Set can be enumerated. Extents are ordered, so
+// the extent of a union is the greater of its inputs' and that of an
+// intersection is the lesser.
+type extent int
+
+const (
+	// finite Sets can be iterated to the end.
+	finite extent = iota
+
+	// unbounded Sets can be iterated, but might never end. This includes
+	// Sets from FromFunc and FromChannel, until they are iterated to the end.
+	unbounded
+
+	// unenumerable Sets only support membership tests.
+	unenumerable
+)
+
+type extenter interface {
+	extent() extent
+}
+
+func extentOf[T any](s SetOf[T]) extent {
+	if e, ok := s.(extenter); ok {
+		return e.extent()
+	}
+	return finite
+}
+
+// mustBeFinite panics if s can't be iterated to the end, which op needs.
+func mustBeFinite[T any](s SetOf[T], op string) {
+	switch extentOf(s) {
+	case unbounded:
+		panic(fmt.Errorf("%s: %w", op, ErrInfiniteSet))
+	case unenumerable:
+		panic(fmt.Errorf("%s: %w", op, ErrNotEnumerable))
+	}
+}
+
+type rangeSet struct {
+	baseSet[int]
+	start, step int
+}
+
+// Naturals returns the infinite Set {0, 1, 2, ...}.
+func Naturals() SetOf[int] {
+	return Range(0, 1)
+}
+
+// Range returns the infinite Set {start, start+step, start+2*step, ...}. It
+// panics if step is zero. Iteration stops before the elements overflow int.
+func Range(start, step int) SetOf[int] {
+	if step == 0 {
+		panic("Range(): zero step")
+	}
+	s := &rangeSet{start: start, step: step}
+	s.baseSet.set = s
+	return s
+}
+
+func (s *rangeSet) extent() extent {
+	return unbounded
+}
+
+func (s *rangeSet) plan() (string, []any) {
+	return fmt.Sprintf("range from %d step %d", s.start, s.step), nil
+}
+
+func (s *rangeSet) IsEmpty() bool {
+	return false
+}
+
+func (s *rangeSet) FastIsEmpty() (empty, ok bool) {
+	return false, true
+}
+
+func (s *rangeSet) FastCountUpTo(limit int) (count int, ok bool) {
+	return limit, true
+}
+
+func (s *rangeSet) Has(el int) bool {
+	has, _ := s.FastHas(el)
+	return has
+}
+
+func (s *rangeSet) FastHas(el int) (has, ok bool) {
+	// Differences are computed in uint64, where they can't overflow.
+	step := uint64(s.step)
+	diff := uint64(el) - uint64(s.start)
+	if s.step < 0 {
+		if el > s.start {
+			return false, true
+		}
+		step, diff = -step, -diff
+	} else if el < s.start {
+		return false, true
+	}
+	return diff%step == 0, true
+}
+
+func (s *rangeSet) Range() frozen.Iterator[int] {
+	return &rangeSetIterator{next: s.start, step: s.step}
+}
+
+type rangeSetIterator struct {
+	value, next, step int
+	done              bool
+}
+
+func (i *rangeSetIterator) Next() bool {
+	if i.done {
+		return false
+	}
+	i.value = i.next
+	i.next += i.step
+	// Stop before wrapping around.
+	i.done = (i.step > 0) != (i.next > i.value)
+	return true
+}
+
+func (i *rangeSetIterator) Value() int {
+	return i.value
+}
+
+type predicateSet[T any] struct {
+	baseSet[T]
+	has func(el T) bool
+}
+
+// FromPredicate returns a Set of the elements for which has returns true. The
+// Set only supports membership tests and operations that build on them, such
+// as intersections with enumerable Sets. Range panics with ErrNotEnumerable.
+// The function must be pure.
+func FromPredicate[T any](has func(el T) bool) SetOf[T] {
+	s := &predicateSet[T]{has: has}
+	s.baseSet.set = s
+	return s
+}
+
+func (s *predicateSet[T]) extent() extent {
+	return unenumerable
+}
+
+func (s *predicateSet[T]) plan() (string, []any) {
+	return "predicate", nil
+}
+
+func (s *predicateSet[T]) Has(el T) bool {
+	return s.has(el)
+}
+
+func (s *predicateSet[T]) FastHas(el T) (has, ok bool) {
+	return s.has(el), true
+}
+
+func (s *predicateSet[T]) Range() frozen.Iterator[T] {
+	panic(fmt.Errorf("Range(): %w", ErrNotEnumerable))
+}
@@ -0,0 +1,117 @@
+package lazy_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+func assertPanicsWith(t *testing.T, expected error, f func()) bool {
+	t.Helper()
+
+	var r any
+	func() {
+		defer func() { r = recover() }()
+		f()
+	}()
+	err, ok := r.(error)
+	return test.True(t, ok, "%v", r) && test.True(t, errors.Is(err, expected), "%v", err)
+}
+
+func firstN[T any](s lazy.SetOf[T], n int) []T {
+	var result []T
+	for i := s.Range(); len(result) < n && i.Next(); {
+		result = append(result, i.Value())
+	}
+	return result
+}
+
+func TestNaturals(t *testing.T) {
+	t.Parallel()
+
+	n := lazy.Naturals()
+	test.Equal(t, []int{0, 1, 2, 3, 4}, firstN(n, 5))
+	test.False(t, n.IsEmpty())
+	assertFastNotIsEmpty(t, n)
+	test.Equal(t, 1000, n.CountUpTo(1000))
+	assertFastCountUpToEqual(t, 1000, n, 1000)
+	test.True(t, n.Has(1_000_000))
+	assertFastHas(t, n, 0)
+	assertFastNotHas(t, n, -1)
+
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { n.Count() })
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { n.Freeze() })
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { n.Hash(0) })
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { n.EqualSet(n) })
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { n.Union(lazy.From(frozen.NewSet(-1))).Count() })
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { n.Where(func(el int) bool { return el < 10 }).Freeze() })
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { lazy.SetMap(n, func(el int) int { return el / 2 }).Has(-1) })
+}
+
+func TestNaturalsDerived(t *testing.T) {
+	t.Parallel()
+
+	n := lazy.Naturals()
+	evens := n.Where(func(el int) bool { return el%2 == 0 })
+	test.Equal(t, []int{0, 2, 4, 6}, firstN(evens, 4))
+	test.Equal(t, []int{0, 4, 8}, firstN(evens.Intersection(lazy.Range(0, 4)), 3))
+	test.Equal(t, []int{1, 3, 5}, firstN(n.Difference(evens), 3))
+
+	// The finite side of an intersection drives, so the result is finite.
+	small := lazy.From(frozen.NewSet(-3, 4, 7))
+	test.Equal(t, 2, small.Intersection(n).Count())
+	test.True(t, n.Intersection(small).Freeze().Equal(frozen.NewSet(4, 7)))
+	test.Equal(t, 1, small.Difference(n).Count())
+	test.Equal(t, 2, lazy.Powerset(lazy.From(frozen.NewSet(3)).Intersection(n)).Count())
+}
+
+func TestRange(t *testing.T) {
+	t.Parallel()
+
+	r := lazy.Range(10, 3)
+	test.Equal(t, []int{10, 13, 16}, firstN(r, 3))
+	assertFastHas(t, r, 22)
+	assertFastNotHas(t, r, 21)
+	assertFastNotHas(t, r, 7)
+
+	down := lazy.Range(5, -5)
+	test.Equal(t, []int{5, 0, -5}, firstN(down, 3))
+	assertFastHas(t, down, -100)
+	assertFastNotHas(t, down, 10)
+	assertFastNotHas(t, down, -101)
+
+	wide := lazy.Range(math.MinInt, math.MaxInt)
+	test.Equal(t, []int{math.MinInt, -1, math.MaxInt - 1}, firstN(wide, 5))
+	assertFastHas(t, wide, math.MaxInt-1)
+	assertFastNotHas(t, wide, math.MaxInt)
+
+	test.Panic(t, func() { lazy.Range(0, 0) })
+}
+
+func TestFromPredicate(t *testing.T) {
+	t.Parallel()
+
+	odd := lazy.FromPredicate(func(el int) bool { return el%2 != 0 })
+	test.True(t, odd.Has(3))
+	assertFastHas(t, odd, -3)
+	assertFastNotHas(t, odd, 4)
+	test.True(t, odd.With(4).Has(4))
+	test.False(t, odd.Without(3).Has(3))
+	test.True(t, odd.Where(func(el int) bool { return el > 0 }).Has(5))
+	test.False(t, odd.Where(func(el int) bool { return el > 0 }).Has(-5))
+
+	assertPanicsWith(t, lazy.ErrNotEnumerable, func() { odd.Range() })
+	assertPanicsWith(t, lazy.ErrNotEnumerable, func() { odd.Count() })
+	assertPanicsWith(t, lazy.ErrNotEnumerable, func() { odd.Freeze() })
+	assertPanicsWith(t, lazy.ErrNotEnumerable, func() { odd.Union(lazy.Naturals()).Count() })
+
+	// Intersections iterate the enumerable side.
+	test.True(t, lazy.From(frozen.Iota(10)).Intersection(odd).Freeze().Equal(frozen.NewSet(1, 3, 5, 7, 9)))
+	test.True(t, odd.Intersection(lazy.From(frozen.Iota(6))).Freeze().Equal(frozen.NewSet(1, 3, 5)))
+	test.Equal(t, []int{1, 3, 5}, firstN(odd.Intersection(lazy.Naturals()), 3))
+	test.True(t, lazy.From(frozen.Iota(6)).Difference(odd).Freeze().Equal(frozen.NewSet(0, 2, 4)))
+}
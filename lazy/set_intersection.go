@@ -1,27 +1,75 @@
 package lazy
 
-type intersectionSet struct {
-	baseSet
-	a, b Set
+import "github.com/arr-ai/frozen"
+
+type intersectionSet[T any] struct {
+	baseSet[T]
+	a, b SetOf[T]
 }
 
-func intersection(a, b Set) Set {
-	if isEmpty, ok := a.FastIsEmpty(); ok && isEmpty {
-		return EmptySet{}
-	}
-	if isEmpty, ok := b.FastIsEmpty(); ok && isEmpty {
-		return EmptySet{}
+func intersection[T any](a, b SetOf[T]) SetOf[T] {
+	if fastIsEmpty(a) || fastIsEmpty(b) {
+		return Empty[T]()
 	}
-	s := &intersectionSet{a: a, b: b}
+	s := &intersectionSet[T]{a: a, b: b}
 	s.baseSet.set = s
-	return memo(s)
+	return memo[T](s)
+}
+
+// order returns the side to iterate over and the side to probe for each
+// element. A side that can be iterated to the end is preferred. Otherwise, if
+// both counts are known, the smaller side drives. If only one is known, that
+// side is usually frozen, with fast lookups, so it is probed. The choice is
+// made afresh for each Range, since memoized inputs gain fast counts once they
+// have been iterated.
+func (s *intersectionSet[T]) order() (outer, inner SetOf[T]) {
+	// Never iterate a side that might be infinite if the other can't be.
+	if a, b := extentOf(s.a), extentOf(s.b); a != b {
+		if a < b {
+			return s.a, s.b
+		}
+		return s.b, s.a
+	}
+	aCount, aOk := s.a.FastCount()
+	bCount, bOk := s.b.FastCount()
+	if aOk && (!bOk || bCount < aCount) {
+		return s.b, s.a
+	}
+	return s.a, s.b
+}
+
+func (s *intersectionSet[T]) extent() extent {
+	a, b := extentOf(s.a), extentOf(s.b)
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (s *intersectionSet[T]) plan() (string, []any) {
+	outer, inner := s.order()
+	return "intersection (iterate first, probe second)", []any{outer, inner}
 }
 
-func (s *intersectionSet) Has(el any) bool {
+func (s *intersectionSet[T]) FastIsEmpty() (empty, ok bool) {
+	if fastIsEmpty(s.a) || fastIsEmpty(s.b) {
+		return true, true
+	}
+	return false, false
+}
+
+func (s *intersectionSet[T]) Has(el T) bool {
+	if has, ok := s.FastHas(el); ok {
+		return has
+	}
 	return s.a.Has(el) && s.b.Has(el)
 }
 
-func (s *intersectionSet) FastHas(el any) (has, ok bool) {
+func (s *intersectionSet[T]) hasFromInputs(el T) bool {
+	return s.Has(el)
+}
+
+func (s *intersectionSet[T]) FastHas(el T) (has, ok bool) {
 	aHas, aOk := s.a.FastHas(el)
 	if aOk && !aHas {
 		return false, true
@@ -33,26 +81,27 @@ func (s *intersectionSet) FastHas(el any) (has, ok bool) {
 	return aHas && bHas, aOk && bOk
 }
 
-func (s *intersectionSet) Range() SetIterator {
-	return &intersectionSetIterator{i: s.a.Range(), b: s.b}
+func (s *intersectionSet[T]) Range() frozen.Iterator[T] {
+	outer, inner := s.order()
+	return &intersectionSetIterator[T]{i: outer.Range(), b: inner}
 }
 
-type intersectionSetIterator struct {
-	i SetIterator
-	b Set
+type intersectionSetIterator[T any] struct {
+	i frozen.Iterator[T]
+	b SetOf[T]
 }
 
-func (i *intersectionSetIterator) Next() bool {
+func (i *intersectionSetIterator[T]) Next() bool {
 	for {
 		if !i.i.Next() {
 			return false
 		}
-		if i.b.Has(i.i.Value()) {
+		if probe(i.b, i.i.Value()) {
 			return true
 		}
 	}
 }
 
-func (i *intersectionSetIterator) Value() any {
+func (i *intersectionSetIterator[T]) Value() T {
 	return i.i.Value()
 }
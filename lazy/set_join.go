@@ -0,0 +1,163 @@
+package lazy
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/value"
+)
+
+type joinSet[A, B, K any] struct {
+	baseSet[Pair[A, B]]
+	a    SetOf[A]
+	b    SetOf[B]
+	keyA func(el A) K
+	keyB func(el B) K
+
+	once   sync.Once
+	built  atomic.Bool
+	builtA bool
+	tableA frozen.Map[K, frozen.Set[A]]
+	tableB frozen.Map[K, frozen.Set[B]]
+}
+
+// Join returns the Pairs of elements of a and b with equal keys. It is a hash
+// join: the first Range materializes the side that is known to be smaller,
+// grouped by key, and every Range iterates the other side, probing the groups.
+// If neither side's size is known, b is materialized.
+func Join[A, B, K any](a SetOf[A], b SetOf[B], keyA func(el A) K, keyB func(el B) K) SetOf[Pair[A, B]] {
+	if fastIsEmpty(a) || fastIsEmpty(b) {
+		return Empty[Pair[A, B]]()
+	}
+	s := &joinSet[A, B, K]{a: a, b: b, keyA: keyA, keyB: keyB}
+	s.baseSet.set = s
+	return s
+}
+
+// buildA returns true if a is the side to materialize. A side that can be
+// iterated to the end is preferred, then the smaller known count.
+func (s *joinSet[A, B, K]) buildA() bool {
+	if a, b := extentOf(s.a), extentOf(s.b); a != b {
+		return a < b
+	}
+	aCount, aOk := s.a.FastCount()
+	bCount, bOk := s.b.FastCount()
+	return aOk && (!bOk || aCount < bCount)
+}
+
+func (s *joinSet[A, B, K]) build() {
+	s.once.Do(func() {
+		if s.builtA = s.buildA(); s.builtA {
+			s.tableA = frozen.SetGroupBy(s.a.Freeze(), s.keyA)
+		} else {
+			s.tableB = frozen.SetGroupBy(s.b.Freeze(), s.keyB)
+		}
+		s.built.Store(true)
+	})
+}
+
+func (s *joinSet[A, B, K]) extent() extent {
+	a, b := extentOf(s.a), extentOf(s.b)
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (s *joinSet[A, B, K]) plan() (string, []any) {
+	buildA := s.buildA()
+	if s.built.Load() {
+		buildA = s.builtA
+	}
+	if buildA {
+		return "hash join (build first, probe second)", []any{s.a, s.b}
+	}
+	return "hash join (probe first, build second)", []any{s.a, s.b}
+}
+
+func (s *joinSet[A, B, K]) FastIsEmpty() (empty, ok bool) {
+	if fastIsEmpty(s.a) || fastIsEmpty(s.b) {
+		return true, true
+	}
+	return false, false
+}
+
+func (s *joinSet[A, B, K]) Has(p Pair[A, B]) bool {
+	return value.Equal(s.keyA(p.First), s.keyB(p.Second)) && s.a.Has(p.First) && s.b.Has(p.Second)
+}
+
+func (s *joinSet[A, B, K]) FastHas(p Pair[A, B]) (has, ok bool) {
+	if !value.Equal(s.keyA(p.First), s.keyB(p.Second)) {
+		return false, true
+	}
+	aHas, aOk := s.a.FastHas(p.First)
+	if aOk && !aHas {
+		return false, true
+	}
+	bHas, bOk := s.b.FastHas(p.Second)
+	if bOk && !bHas {
+		return false, true
+	}
+	return aHas && bHas, aOk && bOk
+}
+
+func (s *joinSet[A, B, K]) Range() frozen.Iterator[Pair[A, B]] {
+	s.build()
+	if s.builtA {
+		return &joinProbeBIterator[A, B, K]{i: s.b.Range(), table: s.tableA, key: s.keyB}
+	}
+	return &joinProbeAIterator[A, B, K]{i: s.a.Range(), table: s.tableB, key: s.keyA}
+}
+
+// joinProbeAIterator iterates over a, pairing each element with its group in
+// the table built from b.
+type joinProbeAIterator[A, B, K any] struct {
+	i       frozen.Iterator[A]
+	table   frozen.Map[K, frozen.Set[B]]
+	key     func(el A) K
+	matches frozen.Iterator[B]
+}
+
+func (i *joinProbeAIterator[A, B, K]) Next() bool {
+	for i.matches == nil || !i.matches.Next() {
+		if !i.i.Next() {
+			return false
+		}
+		i.matches = nil
+		if group, has := i.table.Get(i.key(i.i.Value())); has {
+			i.matches = group.Range()
+		}
+	}
+	return true
+}
+
+func (i *joinProbeAIterator[A, B, K]) Value() Pair[A, B] {
+	return Pair[A, B]{First: i.i.Value(), Second: i.matches.Value()}
+}
+
+// joinProbeBIterator iterates over b, pairing each element with its group in
+// the table built from a.
+type joinProbeBIterator[A, B, K any] struct {
+	i       frozen.Iterator[B]
+	table   frozen.Map[K, frozen.Set[A]]
+	key     func(el B) K
+	matches frozen.Iterator[A]
+}
+
+func (i *joinProbeBIterator[A, B, K]) Next() bool {
+	for i.matches == nil || !i.matches.Next() {
+		if !i.i.Next() {
+			return false
+		}
+		i.matches = nil
+		if group, has := i.table.Get(i.key(i.i.Value())); has {
+			i.matches = group.Range()
+		}
+	}
+	return true
+}
+
+func (i *joinProbeBIterator[A, B, K]) Value() Pair[A, B] {
+	return Pair[A, B]{First: i.matches.Value(), Second: i.i.Value()}
+}
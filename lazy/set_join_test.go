@@ -0,0 +1,69 @@
+package lazy_test
+
+import (
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+type person struct {
+	Name string
+	City string
+}
+
+type city struct {
+	Name    string
+	Country string
+}
+
+func TestJoin(t *testing.T) {
+	t.Parallel()
+
+	people := lazy.From(frozen.NewSet(
+		person{"alice", "sydney"},
+		person{"bob", "melbourne"},
+		person{"carol", "sydney"},
+		person{"dave", "atlantis"},
+	))
+	cities := lazy.From(frozen.NewSet(
+		city{"sydney", "au"},
+		city{"melbourne", "au"},
+		city{"paris", "fr"},
+	))
+	personCity := func(p person) string { return p.City }
+	cityName := func(c city) string { return c.Name }
+
+	j := lazy.Join(people, cities, personCity, cityName)
+	test.Equal(t, "hash join (probe first, build second)\n"+
+		"  frozen (4 elements)\n"+
+		"  frozen (3 elements)", j.Explain())
+	expected := frozen.NewSet(
+		lazy.Pair[person, city]{person{"alice", "sydney"}, city{"sydney", "au"}},
+		lazy.Pair[person, city]{person{"bob", "melbourne"}, city{"melbourne", "au"}},
+		lazy.Pair[person, city]{person{"carol", "sydney"}, city{"sydney", "au"}},
+	)
+	test.True(t, j.Freeze().Equal(expected))
+	test.Equal(t, 3, j.Count())
+	assertFastHas(t, j, lazy.Pair[person, city]{person{"bob", "melbourne"}, city{"melbourne", "au"}})
+	assertFastNotHas(t, j, lazy.Pair[person, city]{person{"bob", "melbourne"}, city{"sydney", "au"}})
+
+	// The smaller side is built whichever way round the join is written.
+	k := lazy.Join(cities, people, cityName, personCity)
+	test.Equal(t, "hash join (build first, probe second)\n"+
+		"  frozen (3 elements)\n"+
+		"  frozen (4 elements)", k.Explain())
+	test.Equal(t, 3, k.Count())
+
+	assertFastIsEmpty(t, lazy.Join(people, lazy.Empty[city](), personCity, cityName))
+}
+
+func TestJoinInfiniteProbe(t *testing.T) {
+	t.Parallel()
+
+	squares := lazy.From(frozen.NewSet(4, 9, 10))
+	j := lazy.Join(lazy.Naturals(), squares, func(n int) int { return n * n }, func(sq int) int { return sq })
+	test.Equal(t, []lazy.Pair[int, int]{{2, 4}, {3, 9}}, firstN(j, 2))
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { j.Count() })
+}
@@ -1,86 +1,313 @@
 package lazy
 
 import (
+	"fmt"
+	"sync"
 	"sync/atomic"
-	"unsafe"
 
 	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/value"
 )
 
-type memoSet struct {
-	set *Set
+// memoSet delegates to a Set until it has been fully iterated, after which it
+// delegates to a frozen Set of the elements found.
+//
+// Iterators over a memoSet share a single evaluation of the underlying Set.
+// The distinct elements found so far are buffered, so that concurrent and
+// later iterators replay them before asking for more, and an iterator that
+// stops early leaves its work for the next one.
+type memoSet[T any] struct {
+	set atomic.Pointer[SetOf[T]]
+
+	// eval is held while advancing iter, so that only one goroutine at a time
+	// evaluates the underlying Set.
+	eval sync.Mutex
+	iter frozen.Iterator[T] // nil until the evaluation starts
+
+	// mu guards the buffer, so that reading it doesn't wait for evaluation.
+	mu    sync.Mutex
+	elems []T
+	seen  frozen.SetBuilder[T]
 }
 
-func memo(src Set) Set {
+func memo[T any](src SetOf[T]) SetOf[T] {
 	switch src.(type) {
-	case *memoSet, *frozenSet:
+	case *memoSet[T], *frozenSet[T], emptySet[T]:
 		return src
 	default:
-		result := &memoSet{}
-		result.set = &src
+		result := &memoSet[T]{}
+		result.set.Store(&src)
 		return result
 	}
 }
 
-func (s *memoSet) pointer() *unsafe.Pointer {
-	return (*unsafe.Pointer)(unsafe.Pointer(&s.set))
+func (s *memoSet[T]) getSet() SetOf[T] {
+	return *s.set.Load()
+}
+
+// frozen returns the frozen Set that s delegates to, if it has been fully
+// iterated.
+func (s *memoSet[T]) frozen() (*frozenSet[T], bool) {
+	f, ok := s.getSet().(*frozenSet[T])
+	return f, ok
+}
+
+// materialize fully iterates s and returns the frozen Set it then delegates
+// to.
+func (s *memoSet[T]) materialize(op string) *frozenSet[T] {
+	if f, ok := s.frozen(); ok {
+		return f
+	}
+	mustBeFinite[T](s, op)
+	if f, ok := s.freezeParallel(); ok {
+		return f
+	}
+	for i := s.Range(); i.Next(); { //nolint:revive
+	}
+	f, _ := s.frozen()
+	return f
+}
+
+// freezeParallel freezes s with parallelFreeze, unless an evaluation has
+// already started, in which case finishing it is cheaper.
+func (s *memoSet[T]) freezeParallel() (*frozenSet[T], bool) {
+	s.eval.Lock()
+	defer s.eval.Unlock()
+	if f, ok := s.frozen(); ok {
+		return f, true
+	}
+	if s.iter != nil {
+		return nil, false
+	}
+	set, ok := parallelFreeze(s.getSet())
+	if !ok {
+		return nil, false
+	}
+	f := From(set)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set.Store(&f)
+	return f.(*frozenSet[T]), true
+}
+
+// elemsFrom returns the buffered elements, evaluating more until there are
+// more than i of them or the evaluation ends. The result is a prefix of every
+// later result, so callers may keep it and index into it without locking.
+func (s *memoSet[T]) elemsFrom(i int) []T {
+	s.eval.Lock()
+	defer s.eval.Unlock()
+	if elems := s.buffered(); i < len(elems) {
+		return elems
+	}
+	if _, ok := s.frozen(); ok {
+		return s.buffered()
+	}
+	if s.iter == nil {
+		s.iter = s.getSet().Range()
+	}
+	for {
+		if !s.iter.Next() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			// Keep the buffer for iterators that are still replaying it.
+			f := From(s.seen.Finish())
+			s.set.Store(&f)
+			s.iter = nil
+			return s.elems
+		}
+		v := s.iter.Value()
+		s.mu.Lock()
+		if !s.seen.Has(v) {
+			s.seen.Add(v)
+			s.elems = append(s.elems, v)
+		}
+		elems := s.elems
+		s.mu.Unlock()
+		if i < len(elems) {
+			return elems
+		}
+	}
 }
 
-func (s *memoSet) getSet() Set {
-	return *(*Set)(atomic.LoadPointer(s.pointer()))
+func (s *memoSet[T]) buffered() []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.elems
 }
 
-func (s *memoSet) Range() SetIterator {
-	if f, ok := s.getSet().(*frozenSet); ok {
+func (s *memoSet[T]) Range() frozen.Iterator[T] {
+	if f, ok := s.frozen(); ok {
 		return f.set.Range()
 	}
-	return &cachingIterator{iter: s.getSet().Range(), s: s}
+	return &memoSetIterator[T]{s: s, i: -1}
+}
+
+func (s *memoSet[T]) Explain() string {
+	return explain(s)
+}
+
+// plan is transparent until s has been fully iterated.
+func (s *memoSet[T]) plan() (string, []any) {
+	if f, ok := s.frozen(); ok {
+		return fmt.Sprintf("memoized (%d elements)", f.set.Count()), nil
+	}
+	return s.getSet().(planner).plan()
+}
+
+func (s *memoSet[T]) extent() extent {
+	return extentOf(s.getSet())
+}
+
+func (s *memoSet[T]) Freeze() frozen.Set[T] {
+	return s.materialize("Freeze()").set
+}
+
+type memoSetIterator[T any] struct {
+	s     *memoSet[T]
+	elems []T
+	i     int
+
+	// frozen takes over if s is frozen by freezeParallel before this
+	// iterator starts, leaving no buffer to replay.
+	frozen frozen.Iterator[T]
+}
+
+func (i *memoSetIterator[T]) Next() bool {
+	if i.frozen != nil {
+		return i.frozen.Next()
+	}
+	i.i++
+	if i.i >= len(i.elems) {
+		i.elems = i.s.elemsFrom(i.i)
+		if i.i == 0 && len(i.elems) == 0 {
+			if f, ok := i.s.frozen(); ok {
+				i.frozen = f.set.Range()
+				return i.frozen.Next()
+			}
+		}
+	}
+	return i.i < len(i.elems)
+}
+
+func (i *memoSetIterator[T]) Value() T {
+	if i.frozen != nil {
+		return i.frozen.Value()
+	}
+	return i.elems[i.i]
+}
+
+func (s *memoSet[T]) IsEmpty() bool {
+	if empty, ok := s.FastIsEmpty(); ok {
+		return empty
+	}
+	return !s.Range().Next()
 }
 
-type cachingIterator struct {
-	iter SetIterator
-	s    *memoSet
-	seen frozen.SetBuilder[any]
+func (s *memoSet[T]) FastIsEmpty() (empty, ok bool) {
+	if len(s.buffered()) > 0 {
+		return false, true
+	}
+	return s.getSet().FastIsEmpty()
 }
 
-func (i *cachingIterator) Next() bool {
-	for i.iter.Next() {
-		if val := i.iter.Value(); !i.seen.Has(val) {
-			i.seen.Add(val)
+func (s *memoSet[T]) Count() int {
+	if count, ok := s.FastCount(); ok {
+		return count
+	}
+	return s.materialize("Count()").set.Count()
+}
+
+func (s *memoSet[T]) FastCount() (count int, ok bool) {
+	return s.getSet().FastCount()
+}
+
+func (s *memoSet[T]) CountUpTo(limit int) int {
+	if count, ok := s.FastCountUpTo(limit); ok {
+		return count
+	}
+	n := 0
+	for i := s.Range(); n < limit && i.Next(); {
+		n++
+	}
+	return n
+}
+
+func (s *memoSet[T]) FastCountUpTo(limit int) (count int, ok bool) {
+	if len(s.buffered()) >= limit {
+		return limit, true
+	}
+	return s.getSet().FastCountUpTo(limit)
+}
+
+// inputTester is implemented by Sets that can test membership by testing
+// their inputs, which is usually cheaper than iterating the Set.
+type inputTester[T any] interface {
+	hasFromInputs(el T) bool
+}
+
+func (s *memoSet[T]) Has(el T) bool {
+	if has, ok := s.FastHas(el); ok {
+		return has
+	}
+	if t, ok := s.getSet().(inputTester[T]); ok {
+		return t.hasFromInputs(el)
+	}
+	mustBeFinite[T](s, "Has()")
+	for i := s.Range(); i.Next(); {
+		if value.Equal(el, i.Value()) {
 			return true
 		}
 	}
-	seen := Frozen(i.seen.Finish())
-	atomic.StorePointer(i.s.pointer(), unsafe.Pointer(&seen))
 	return false
 }
 
-func (i *cachingIterator) Value() any {
-	return i.iter.Value()
-}
-
-func (s *memoSet) IsEmpty() bool                   { return s.getSet().IsEmpty() }
-func (s *memoSet) FastIsEmpty() (empty, ok bool)   { return s.getSet().FastIsEmpty() }
-func (s *memoSet) Count() int                      { return s.getSet().Count() }
-func (s *memoSet) FastCount() (count int, ok bool) { return s.getSet().FastCount() }
-func (s *memoSet) CountUpTo(limit int) int         { return s.getSet().CountUpTo(limit) }
-func (s *memoSet) Freeze() Set                     { return s.getSet().Freeze() }
-func (s *memoSet) Hash(seed uintptr) uintptr       { return s.getSet().Hash(seed) }
-func (s *memoSet) Equal(set any) bool              { return s.getSet().Equal(set) }
-func (s *memoSet) EqualSet(set Set) bool           { return s.getSet().EqualSet(set) }
-func (s *memoSet) IsSubsetOf(set Set) bool         { return s.getSet().IsSubsetOf(set) }
-func (s *memoSet) Has(el any) bool                 { return s.getSet().Has(el) }
-func (s *memoSet) FastHas(el any) (has, ok bool)   { return s.getSet().FastHas(el) }
-func (s *memoSet) With(v any) Set                  { return s.getSet().With(v) }
-func (s *memoSet) Without(v any) Set               { return s.getSet().Without(v) }
-func (s *memoSet) Where(pred Predicate) Set        { return s.getSet().Where(pred) }
-func (s *memoSet) Map(m Mapper) Set                { return s.getSet().Map(m) }
-func (s *memoSet) Union(set Set) Set               { return s.getSet().Union(set) }
-func (s *memoSet) Intersection(set Set) Set        { return s.getSet().Intersection(set) }
-func (s *memoSet) Difference(set Set) Set          { return s.getSet().Difference(set) }
-func (s *memoSet) SymmetricDifference(set Set) Set { return s.getSet().SymmetricDifference(set) }
-func (s *memoSet) Powerset() Set                   { return s.getSet().Powerset() }
-
-func (s *memoSet) FastCountUpTo(limit int) (count int, ok bool) {
-	return s.getSet().FastCountUpTo(limit)
+func (s *memoSet[T]) FastHas(el T) (has, ok bool) {
+	s.mu.Lock()
+	seen := s.seen.Has(el)
+	s.mu.Unlock()
+	if seen {
+		return true, true
+	}
+	return s.getSet().FastHas(el)
+}
+
+func (s *memoSet[T]) Hash(seed uintptr) uintptr {
+	return s.materialize("Hash()").Hash(seed)
+}
+
+func (s *memoSet[T]) Equal(set any) bool {
+	if set, ok := set.(SetOf[T]); ok {
+		return s.EqualSet(set)
+	}
+	return false
+}
+
+func (s *memoSet[T]) EqualSet(set SetOf[T]) bool {
+	return s.materialize("EqualSet()").EqualSet(set)
+}
+
+func (s *memoSet[T]) IsSubsetOf(set SetOf[T]) bool {
+	return s.materialize("IsSubsetOf()").IsSubsetOf(set)
+}
+
+func (s *memoSet[T]) With(v T) SetOf[T] {
+	if f, ok := s.frozen(); ok {
+		return f.With(v)
+	}
+	return union[T](s, From(frozen.NewSet(v)))
+}
+
+func (s *memoSet[T]) Without(v T) SetOf[T] {
+	if f, ok := s.frozen(); ok {
+		return f.Without(v)
+	}
+	return difference[T](s, From(frozen.NewSet(v)))
+}
+
+func (s *memoSet[T]) Where(pred func(el T) bool) SetOf[T] { return where[T](s, pred) }
+func (s *memoSet[T]) Union(set SetOf[T]) SetOf[T]         { return union[T](s, set) }
+func (s *memoSet[T]) Intersection(set SetOf[T]) SetOf[T]  { return intersection[T](s, set) }
+func (s *memoSet[T]) Difference(set SetOf[T]) SetOf[T]    { return difference[T](s, set) }
+func (s *memoSet[T]) SymmetricDifference(set SetOf[T]) SetOf[T] {
+	return symmetricDifference[T](s, set)
 }
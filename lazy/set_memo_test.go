@@ -0,0 +1,153 @@
+package lazy_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+// countedWhere returns the even numbers in [0, n), in ascending order,
+// counting the calls made to generate and filter them.
+func countedWhere(n int) (_ lazy.SetOf[int], calls, filtered *int64) {
+	calls, filtered = new(int64), new(int64)
+	src := lazy.FromFiniteFunc(func() (int, bool) {
+		i := int(atomic.AddInt64(calls, 1)) - 1
+		return i, i < n
+	})
+	return src.Where(func(el int) bool {
+		atomic.AddInt64(filtered, 1)
+		return el%2 == 0
+	}), calls, filtered
+}
+
+func TestSetMemoReusesPrefix(t *testing.T) {
+	t.Parallel()
+
+	s, calls, _ := countedWhere(100)
+	test.Equal(t, []int{0, 2, 4}, firstN(s, 3))
+	test.Equal(t, int64(5), atomic.LoadInt64(calls))
+
+	// A later iterator replays the prefix before evaluating more.
+	test.Equal(t, 5, len(firstN(s, 5)))
+	test.Equal(t, int64(9), atomic.LoadInt64(calls))
+	test.True(t, s.Has(8))
+	test.Equal(t, int64(9), atomic.LoadInt64(calls))
+	test.Equal(t, 3, s.CountUpTo(3))
+	test.Equal(t, int64(9), atomic.LoadInt64(calls))
+
+	test.Equal(t, 50, s.Count())
+	test.Equal(t, int64(101), atomic.LoadInt64(calls))
+	test.Equal(t, "memoized (50 elements)", s.Explain())
+	test.True(t, s.Has(98))
+	test.Equal(t, int64(101), atomic.LoadInt64(calls))
+}
+
+func TestSetMemoSingleFlight(t *testing.T) {
+	t.Parallel()
+
+	n, readers := 10_000, 16
+	if testing.Short() {
+		n = 1_000
+	}
+	s, calls, filtered := countedWhere(n)
+	expected := frozen.Iota3(0, n, 2)
+
+	var wg sync.WaitGroup
+	for r := 0; r < readers; r++ {
+		r := r
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch r % 4 {
+			case 0:
+				test.True(t, s.Freeze().Equal(expected))
+			case 1:
+				// Stop early, leaving the rest of the evaluation to others.
+				test.Equal(t, r, len(firstN(s, r)))
+			case 2:
+				test.Equal(t, n/4, s.CountUpTo(n/4))
+			default:
+				test.Equal(t, n/2, s.Count())
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Neither the source nor the filter was evaluated more than once.
+	test.Equal(t, int64(n+1), atomic.LoadInt64(calls))
+	test.Equal(t, int64(n), atomic.LoadInt64(filtered))
+	test.True(t, s.Freeze().Equal(expected))
+}
+
+func TestSetMemoSingleFlightDerived(t *testing.T) {
+	t.Parallel()
+
+	rounds := 20
+	if testing.Short() {
+		rounds = 5
+	}
+	for round := 0; round < rounds; round++ {
+		s, calls, filtered := countedWhere(1_000)
+		u := s.Union(lazy.From(frozen.NewSet(-1)))
+		i := s.Intersection(lazy.From(frozen.Iota3(0, 1_000, 3)))
+
+		var wg sync.WaitGroup
+		for r := 0; r < 8; r++ {
+			r := r
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				switch r % 3 {
+				case 0:
+					test.Equal(t, 501, u.Count())
+				case 1:
+					test.Equal(t, 167, i.Count())
+				default:
+					test.Equal(t, 500, s.Count())
+				}
+			}()
+		}
+		wg.Wait()
+		if !test.Equal(t, int64(1_001), atomic.LoadInt64(calls)) ||
+			!test.Equal(t, int64(1_000), atomic.LoadInt64(filtered)) {
+			break
+		}
+	}
+}
+
+func TestSetMemoGeneratorConcurrent(t *testing.T) {
+	t.Parallel()
+
+	n := 10_000
+	if testing.Short() {
+		n = 1_000
+	}
+	var calls int64
+	s := lazy.FromFiniteFunc(func() (int, bool) {
+		i := int(atomic.AddInt64(&calls, 1))
+		return i, i <= n
+	})
+
+	var wg sync.WaitGroup
+	for r := 0; r < 8; r++ {
+		r := r
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sum := 0
+			for i := s.Range(); i.Next(); {
+				sum += i.Value()
+				if r%2 == 1 && i.Value() == r*100 {
+					return
+				}
+			}
+			test.Equal(t, n*(n+1)/2, sum)
+		}()
+	}
+	wg.Wait()
+	test.Equal(t, int64(n+1), atomic.LoadInt64(&calls))
+}
@@ -0,0 +1,153 @@
+package lazy
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/arr-ai/frozen"
+)
+
+// parallelFreezeMin is the smallest frozen Set that parallelFreeze splits.
+const parallelFreezeMin = 1 << 12
+
+// partitioner is implemented by Sets that can be iterated in parts that may
+// be processed concurrently. The parts of a Set might overlap.
+type partitioner[T any] interface {
+	// parts returns iterators over parts of the Set that together cover it, or
+	// false if it can't be split. The parts of frozen Sets with at least
+	// parallelFreezeMin elements are split n ways.
+	parts(n int) ([]frozen.Iterator[T], bool)
+}
+
+func partsOf[T any](s SetOf[T], n int) ([]frozen.Iterator[T], bool) {
+	if p, ok := s.(partitioner[T]); ok {
+		return p.parts(n)
+	}
+	return nil, false
+}
+
+// parallelFreeze freezes s by splitting the frozen Sets at its leaves into
+// trie branches, evaluating the operations above them for each branch on
+// multiple goroutines, and merging the results. It returns false if s can't be
+// split or there's nothing to gain.
+func parallelFreeze[T any](s SetOf[T]) (frozen.Set[T], bool) {
+	procs := runtime.GOMAXPROCS(0)
+	if procs < 2 {
+		return frozen.Set[T]{}, false
+	}
+	parts, ok := partsOf(s, 4*procs)
+	if !ok || len(parts) < procs {
+		return frozen.Set[T]{}, false
+	}
+
+	work := make(chan frozen.Iterator[T], len(parts))
+	for _, part := range parts {
+		work <- part
+	}
+	close(work)
+
+	results := make([]frozen.Set[T], procs)
+	panics := make([]any, procs)
+	var wg sync.WaitGroup
+	for w := 0; w < procs; w++ {
+		w := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { panics[w] = recover() }()
+			var b frozen.SetBuilder[T]
+			for part := range work {
+				for part.Next() {
+					b.Add(part.Value())
+				}
+			}
+			results[w] = b.Finish()
+		}()
+	}
+	wg.Wait()
+	for _, p := range panics {
+		if p != nil {
+			panic(p)
+		}
+	}
+
+	// Merge pairwise, so that the large unions can themselves run in parallel.
+	for len(results) > 1 {
+		var merged []frozen.Set[T]
+		for i := 0; i+1 < len(results); i += 2 {
+			merged = append(merged, results[i].Union(results[i+1]))
+		}
+		if len(results)%2 == 1 {
+			merged = append(merged, results[len(results)-1])
+		}
+		results = merged
+	}
+	return results[0], true
+}
+
+func (s *frozenSet[T]) parts(n int) ([]frozen.Iterator[T], bool) {
+	if s.set.Count() < parallelFreezeMin {
+		return []frozen.Iterator[T]{s.set.Range()}, true
+	}
+	return s.set.RangeParts(n), true
+}
+
+func (emptySet[T]) parts(int) ([]frozen.Iterator[T], bool) {
+	return nil, true
+}
+
+func (s *memoSet[T]) parts(n int) ([]frozen.Iterator[T], bool) {
+	if f, ok := s.frozen(); ok {
+		return f.parts(n)
+	}
+	return partsOf(s.getSet(), n)
+}
+
+func (s *whereSet[T]) parts(n int) ([]frozen.Iterator[T], bool) {
+	parts, ok := partsOf(s.src, n)
+	for i, part := range parts {
+		parts[i] = &whereSetIterator[T]{i: part, pred: s.pred}
+	}
+	return parts, ok
+}
+
+func (s *mapperSet[T, U]) parts(n int) ([]frozen.Iterator[U], bool) {
+	parts, ok := partsOf(s.src, n)
+	if !ok {
+		return nil, false
+	}
+	result := make([]frozen.Iterator[U], 0, len(parts))
+	for _, part := range parts {
+		result = append(result, &mapperSetIterator[T, U]{i: part, m: s.m})
+	}
+	return result, true
+}
+
+func (s *unionSet[T]) parts(n int) ([]frozen.Iterator[T], bool) {
+	a, ok := partsOf(s.a, n)
+	if !ok {
+		return nil, false
+	}
+	b, ok := partsOf(s.b, n)
+	if !ok {
+		return nil, false
+	}
+	return append(a, b...), true
+}
+
+func (s *intersectionSet[T]) parts(n int) ([]frozen.Iterator[T], bool) {
+	outer, inner := s.order()
+	parts, ok := partsOf(outer, n)
+	for i, part := range parts {
+		parts[i] = &intersectionSetIterator[T]{i: part, b: inner}
+	}
+	return parts, ok
+}
+
+func (s *differenceSet[T]) parts(n int) ([]frozen.Iterator[T], bool) {
+	parts, ok := partsOf(s.a, n)
+	for i, part := range parts {
+		parts[i] = &differenceSetIterator[T]{i: part, b: s.b}
+	}
+	return parts, ok
+}
@@ -0,0 +1,112 @@
+package lazy_test
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+// atLeastFourProcs raises GOMAXPROCS to at least 4 for the rest of the test,
+// to exercise parallel code paths even on a single core. GOMAXPROCS is
+// global, so tests that call it mustn't call t.Parallel, and thus don't run
+// alongside tests that do.
+func atLeastFourProcs(t *testing.T) {
+	t.Helper()
+	if procs := runtime.GOMAXPROCS(0); procs < 4 {
+		runtime.GOMAXPROCS(4)
+		t.Cleanup(func() { runtime.GOMAXPROCS(procs) })
+	}
+}
+
+// drain freezes s sequentially by iterating it.
+func drain[T any](s lazy.SetOf[T]) frozen.Set[T] {
+	var b frozen.SetBuilder[T]
+	for i := s.Range(); i.Next(); {
+		b.Add(i.Value())
+	}
+	return b.Finish()
+}
+
+func TestSetFreezeParallel(t *testing.T) { //nolint:paralleltest
+	atLeastFourProcs(t)
+
+	n := 1 << 16
+	if testing.Short() {
+		n = 1 << 13
+	}
+	a := lazy.From(frozen.Iota(n))
+	b := lazy.From(frozen.Iota3(0, 2*n, 3))
+	small := lazy.From(frozen.NewSet(1, 2, 3, -4))
+	exprs := map[string]func() lazy.SetOf[int]{
+		"where": func() lazy.SetOf[int] {
+			return a.Where(func(el int) bool { return el%7 != 0 })
+		},
+		"map": func() lazy.SetOf[int] {
+			return lazy.SetMap(a, func(el int) int { return el / 3 })
+		},
+		"union": func() lazy.SetOf[int] {
+			return a.Where(func(el int) bool { return el%2 == 0 }).Union(b).Union(small)
+		},
+		"intersection": func() lazy.SetOf[int] {
+			return a.Intersection(b.Where(func(el int) bool { return el%5 != 0 }))
+		},
+		"difference": func() lazy.SetOf[int] {
+			return a.Difference(b).Difference(small)
+		},
+		"nested": func() lazy.SetOf[int] {
+			evens := lazy.SetMap(a, func(el int) int { return 2 * el })
+			return evens.SymmetricDifference(b).Where(func(el int) bool { return el%11 != 0 })
+		},
+	}
+	for name, expr := range exprs {
+		expected := drain(expr())
+		test.True(t, expr().Freeze().Equal(expected), name)
+		test.Equal(t, expected.Count(), expr().Count(), name)
+	}
+}
+
+func TestSetFreezeParallelConcurrency(t *testing.T) { //nolint:paralleltest
+	atLeastFourProcs(t)
+
+	var active, peak int64
+	s := lazy.From(frozen.Iota(1 << 15)).Where(func(el int) bool {
+		n := atomic.AddInt64(&active, 1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+				break
+			}
+		}
+		runtime.Gosched()
+		atomic.AddInt64(&active, -1)
+		return el%2 == 0
+	})
+	test.Equal(t, 1<<14, s.Freeze().Count())
+	test.True(t, atomic.LoadInt64(&peak) > 1)
+}
+
+func TestSetFreezeParallelMemo(t *testing.T) { //nolint:paralleltest
+	atLeastFourProcs(t)
+
+	var calls int64
+	s := lazy.From(frozen.Iota(1 << 14)).Where(func(el int) bool {
+		atomic.AddInt64(&calls, 1)
+		return el%3 == 0
+	})
+	// An iterator that hasn't started yet still works after the Set is frozen
+	// in parallel.
+	i := s.Range()
+	f := s.Freeze()
+	test.Equal(t, "memoized (5462 elements)", s.Explain())
+	test.True(t, drain(s).Equal(f))
+	count := 0
+	for ; i.Next(); count++ {
+		test.True(t, f.Has(i.Value()))
+	}
+	test.Equal(t, f.Count(), count)
+	test.Equal(t, int64(1<<14), atomic.LoadInt64(&calls))
+}
@@ -0,0 +1,59 @@
+package lazy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// planner is implemented by Sets to describe themselves for Explain.
+type planner interface {
+	// plan returns a description of the operation and its inputs.
+	plan() (op string, inputs []any)
+}
+
+// explain renders the plan for s as an indented tree, one operation per line.
+func explain(s any) string {
+	var b strings.Builder
+	var walk func(s any, depth int)
+	walk = func(s any, depth int) {
+		if depth > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(strings.Repeat("  ", depth))
+		p, ok := s.(planner)
+		if !ok {
+			fmt.Fprintf(&b, "%T", s)
+			return
+		}
+		op, inputs := p.plan()
+		b.WriteString(op)
+		for _, input := range inputs {
+			walk(input, depth+1)
+		}
+	}
+	walk(s, 0)
+	return b.String()
+}
+
+// unmemo returns the Set that s memoizes, so that rewrites can see through
+// it, or s itself if it isn't a memoSet.
+func unmemo[T any](s SetOf[T]) SetOf[T] {
+	if m, ok := s.(*memoSet[T]); ok {
+		return m.getSet()
+	}
+	return s
+}
+
+// probe returns whether s has el, trying FastHas before Has.
+func probe[T any](s SetOf[T], el T) bool {
+	if has, ok := s.FastHas(el); ok {
+		return has
+	}
+	return s.Has(el)
+}
+
+// fastIsEmpty returns true iff s is known to be empty without iterating it.
+func fastIsEmpty[T any](s SetOf[T]) bool {
+	empty, ok := s.FastIsEmpty()
+	return ok && empty
+}
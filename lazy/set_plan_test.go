@@ -0,0 +1,91 @@
+package lazy_test
+
+import (
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+func TestSetExplainFusesWheres(t *testing.T) {
+	t.Parallel()
+
+	s := lazy.From(frozen.Iota(20)).
+		Where(func(el int) bool { return el%2 == 0 }).
+		Where(func(el int) bool { return el%3 == 0 }).
+		Where(func(el int) bool { return el > 0 })
+	test.Equal(t, "where (3 predicates fused)\n  frozen (20 elements)", s.Explain())
+	test.True(t, s.Freeze().Equal(frozen.NewSet(6, 12, 18)))
+}
+
+func TestSetExplainPushesWhereThroughUnion(t *testing.T) {
+	t.Parallel()
+
+	a := lazy.From(frozen.NewSet(1, 2, 3, 4)).Where(func(el int) bool { return el > 1 })
+	b := lazy.From(frozen.NewSet(3, 4, 5, 6))
+	s := a.Union(b).Where(func(el int) bool { return el%2 == 0 })
+	test.Equal(t,
+		"union\n"+
+			"  where (2 predicates fused)\n"+
+			"    frozen (4 elements)\n"+
+			"  where\n"+
+			"    frozen (4 elements)",
+		s.Explain())
+	test.True(t, s.Freeze().Equal(frozen.NewSet(2, 4, 6)))
+}
+
+func TestSetExplainPushesWhereThroughMap(t *testing.T) {
+	t.Parallel()
+
+	src := lazy.From(frozen.Iota(10)).Where(func(el int) bool { return el > 2 })
+	s := lazy.SetMap(src, func(el int) int { return el * el }).
+		Where(func(el int) bool { return el%2 == 1 })
+	test.Equal(t, "map\n  where (2 predicates fused)\n    frozen (10 elements)", s.Explain())
+	test.True(t, s.Freeze().Equal(frozen.NewSet(9, 25, 49, 81)))
+}
+
+func TestSetExplainFoldsEmpty(t *testing.T) {
+	t.Parallel()
+
+	a := lazy.From(frozen.NewSet(1, 2, 3))
+	e := lazy.Empty[int]()
+	test.Equal(t, "frozen (3 elements)", a.Union(e).Explain())
+	test.Equal(t, "frozen (3 elements)", e.Union(a).Explain())
+	test.Equal(t, "empty", a.Intersection(e).Explain())
+	test.Equal(t, "empty", e.Difference(a).Explain())
+	test.Equal(t, "frozen (3 elements)", a.Difference(e).Explain())
+	test.Equal(t, "empty", e.Where(func(int) bool { return true }).Explain())
+}
+
+func TestSetExplainIntersectionOrder(t *testing.T) {
+	t.Parallel()
+
+	small := lazy.From(frozen.NewSet(10, 20, 30))
+	large := lazy.From(frozen.Iota(100))
+	const expected = "intersection (iterate first, probe second)\n" +
+		"  frozen (3 elements)\n" +
+		"  frozen (100 elements)"
+	test.Equal(t, expected, small.Intersection(large).Explain())
+	test.Equal(t, expected, large.Intersection(small).Explain())
+	test.True(t, large.Intersection(small).Freeze().Equal(frozen.NewSet(10, 20, 30)))
+
+	// A set without a fast count drives, and the frozen side is probed.
+	filtered := large.Where(func(el int) bool { return el%10 == 0 })
+	test.Equal(t,
+		"intersection (iterate first, probe second)\n"+
+			"  where\n"+
+			"    frozen (100 elements)\n"+
+			"  frozen (3 elements)",
+		small.Intersection(filtered).Explain())
+	test.True(t, small.Intersection(filtered).Freeze().Equal(frozen.NewSet(10, 20, 30)))
+}
+
+func TestSetExplainMemoized(t *testing.T) {
+	t.Parallel()
+
+	s := lazy.From(frozen.Iota(10)).Where(func(el int) bool { return el < 5 })
+	test.Equal(t, "where\n  frozen (10 elements)", s.Explain())
+	test.Equal(t, 5, s.Freeze().Count())
+	test.Equal(t, "memoized (5 elements)", s.Explain())
+}
@@ -6,33 +6,42 @@ import (
 	"github.com/arr-ai/frozen"
 )
 
-type powerSet struct {
-	baseSet
-	set Set
+type powerSet[T any] struct {
+	baseSet[frozen.Set[T]]
+	set SetOf[T]
 }
 
-func powerset(set Set) Set {
-	s := &powerSet{set: set}
-	s.baseSet.set = s
-	return memo(s)
+// Powerset returns the set of all subsets of s.
+func Powerset[T any](s SetOf[T]) SetOf[frozen.Set[T]] {
+	p := &powerSet[T]{set: s}
+	p.baseSet.set = p
+	return memo[frozen.Set[T]](p)
 }
 
-func (s *powerSet) IsEmpty() bool {
-	return s.set.IsEmpty()
+func (s *powerSet[T]) extent() extent {
+	return extentOf(s.set)
 }
 
-func (s *powerSet) FastIsEmpty() (empty, ok bool) {
-	return s.set.FastIsEmpty()
+func (s *powerSet[T]) plan() (string, []any) {
+	return "powerset", []any{s.set}
 }
 
-func (s *powerSet) Count() int {
+func (s *powerSet[T]) IsEmpty() bool {
+	return false
+}
+
+func (s *powerSet[T]) FastIsEmpty() (empty, ok bool) {
+	return false, true
+}
+
+func (s *powerSet[T]) Count() int {
 	if count := s.set.Count(); count < int(8*unsafe.Sizeof(0)) {
 		return 1 << uint(count)
 	}
 	panic("Count(): too many elements")
 }
 
-func (s *powerSet) FastCount() (count int, ok bool) {
+func (s *powerSet[T]) FastCount() (count int, ok bool) {
 	if count, ok := s.set.FastCount(); ok {
 		if count < int(8*unsafe.Sizeof(0)) {
 			return 1 << uint(count), true
@@ -42,7 +51,7 @@ func (s *powerSet) FastCount() (count int, ok bool) {
 	return 0, false
 }
 
-func (s *powerSet) FastCountUpTo(limit int) (count int, ok bool) {
+func (s *powerSet[T]) FastCountUpTo(limit int) (count int, ok bool) {
 	if count, ok := s.set.FastCount(); ok {
 		if count < int(8*unsafe.Sizeof(0)) {
 			n := 1 << uint(count)
@@ -56,28 +65,31 @@ func (s *powerSet) FastCountUpTo(limit int) (count int, ok bool) {
 	return 0, false
 }
 
-func (s *powerSet) Has(el any) bool {
-	ss, ok := el.(frozen.Set[any])
-	return ok && Frozen(ss).IsSubsetOf(s.set)
+func (s *powerSet[T]) Has(el frozen.Set[T]) bool {
+	return From(el).IsSubsetOf(s.set)
+}
+
+func (s *powerSet[T]) hasFromInputs(el frozen.Set[T]) bool {
+	return s.Has(el)
 }
 
-func (s *powerSet) Range() SetIterator {
-	return &powerSetSetIterator{
+func (s *powerSet[T]) Range() frozen.Iterator[frozen.Set[T]] {
+	return &powerSetSetIterator[T]{
 		i:    s.set.Range(),
 		end:  1,
 		mask: ^frozen.BitIterator(0),
 	}
 }
 
-type powerSetSetIterator struct {
-	i     SetIterator
+type powerSetSetIterator[T any] struct {
+	i     frozen.Iterator[T]
 	end   frozen.BitIterator
 	mask  frozen.BitIterator
-	elems []any
-	value frozen.Set[any]
+	elems []T
+	value frozen.Set[T]
 }
 
-func (i *powerSetSetIterator) Next() bool {
+func (i *powerSetSetIterator[T]) Next() bool {
 	i.mask++
 	if i.mask >= i.end {
 		if i.mask > i.end {
@@ -101,6 +113,6 @@ func (i *powerSetSetIterator) Next() bool {
 	return true
 }
 
-func (i *powerSetSetIterator) Value() any {
-	return Frozen(i.value)
+func (i *powerSetSetIterator[T]) Value() frozen.Set[T] {
+	return i.value
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
 	"github.com/arr-ai/frozen/lazy"
 )
 
@@ -11,14 +12,11 @@ func TestSetPowerset(t *testing.T) {
 	t.Parallel()
 
 	f := frozen.NewSet[any](1, 2, 3)
-	s := func() lazy.Set { return lazy.Frozen(f).Powerset() }
+	s := func() lazy.SetOf[frozen.Set[any]] { return lazy.Powerset(lazy.From(f)) }
 
-	assertSetOps(t,
-		frozen.SetMap(
-			frozen.Powerset(f),
-			func(el frozen.Set[any]) any { return lazy.Frozen(el) },
-		),
-		s())
+	assertSetOps(t, frozen.SetAs[any](frozen.Powerset(f)), lazy.SetAs[any](s()))
+	test.True(t, s().Has(frozen.NewSet[any](1, 3)))
+	test.False(t, s().Has(frozen.NewSet[any](1, 4)))
 
 	assertFastNotIsEmpty(t, s())
 	assertFastCountEqual(t, 8, s())
@@ -26,3 +24,14 @@ func TestSetPowerset(t *testing.T) {
 	assertFastCountUpToEqual(t, 8, s(), 8)
 	assertFastCountUpToEqual(t, 8, s(), 9)
 }
+
+func TestSetPowersetEmpty(t *testing.T) {
+	t.Parallel()
+
+	// The powerset of the empty set has one element, the empty set.
+	p := lazy.Powerset(lazy.Empty[int]())
+	assertFastNotIsEmpty(t, p)
+	test.Equal(t, 1, p.Count())
+	test.True(t, p.Has(frozen.Set[int]{}))
+	assertFastNotIsEmpty(t, lazy.Powerset(lazy.From(frozen.Set[int]{})))
+}
@@ -0,0 +1,360 @@
+package lazy
+
+import (
+	"fmt"
+
+	"github.com/arr-ai/hash"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/fu"
+	"github.com/arr-ai/frozen/internal/pkg/value"
+)
+
+// Pair is an element of the Cartesian product of two Sets.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Hash computes a hash for a Pair.
+func (p Pair[A, B]) Hash(seed uintptr) uintptr {
+	return hash.Any(p.Second, hash.Any(p.First, seed))
+}
+
+// Equal returns true iff p and q have equal elements.
+func (p Pair[A, B]) Equal(q Pair[A, B]) bool {
+	return value.Equal(p.First, q.First) && value.Equal(p.Second, q.Second)
+}
+
+// Same returns true iff a is a Pair equal to p.
+func (p Pair[A, B]) Same(a any) bool {
+	q, is := a.(Pair[A, B])
+	return is && p.Equal(q)
+}
+
+// String returns a string representation of a Pair.
+func (p Pair[A, B]) String() string {
+	return fmt.Sprintf("%v", p)
+}
+
+// Format writes a string representation of a Pair into f.
+func (p Pair[A, B]) Format(f fmt.State, verb rune) {
+	fu.WriteString(f, "(")
+	fu.Format(p.First, f, verb)
+	fu.WriteString(f, ", ")
+	fu.Format(p.Second, f, verb)
+	fu.WriteString(f, ")")
+}
+
+// Tuple is an element of the Cartesian product of any number of Sets.
+type Tuple[T any] []T
+
+// Hash computes a hash for a Tuple.
+func (t Tuple[T]) Hash(seed uintptr) uintptr {
+	h := hash.Int(len(t), seed)
+	for _, v := range t {
+		h = hash.Any(v, h)
+	}
+	return h
+}
+
+// Equal returns true iff t and u have equal elements.
+func (t Tuple[T]) Equal(u Tuple[T]) bool {
+	if len(t) != len(u) {
+		return false
+	}
+	for i, v := range t {
+		if !value.Equal(v, u[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Same returns true iff a is a Tuple equal to t.
+func (t Tuple[T]) Same(a any) bool {
+	u, is := a.(Tuple[T])
+	return is && t.Equal(u)
+}
+
+// String returns a string representation of a Tuple.
+func (t Tuple[T]) String() string {
+	return fmt.Sprintf("%v", t)
+}
+
+// Format writes a string representation of a Tuple into f.
+func (t Tuple[T]) Format(f fmt.State, verb rune) {
+	fu.WriteString(f, "(")
+	for i, v := range t {
+		fu.Comma(f, i)
+		fu.Format(v, f, verb)
+	}
+	fu.WriteString(f, ")")
+}
+
+// mulCount returns a*b, and false if it overflows int.
+func mulCount(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	if a > maxInt/b {
+		return 0, false
+	}
+	return a * b, true
+}
+
+type productSet[A, B any] struct {
+	baseSet[Pair[A, B]]
+	a SetOf[A]
+	b SetOf[B]
+}
+
+// Product returns the Cartesian product of a and b. Its elements are all
+// distinct and cheap to regenerate, so it isn't memoized. For each element of
+// a, b is iterated afresh, which memoized Sets make cheap.
+func Product[A, B any](a SetOf[A], b SetOf[B]) SetOf[Pair[A, B]] {
+	if fastIsEmpty(a) || fastIsEmpty(b) {
+		return Empty[Pair[A, B]]()
+	}
+	s := &productSet[A, B]{a: a, b: b}
+	s.baseSet.set = s
+	return s
+}
+
+func (s *productSet[A, B]) extent() extent {
+	a, b := extentOf(s.a), extentOf(s.b)
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (s *productSet[A, B]) plan() (string, []any) {
+	return "product", []any{s.a, s.b}
+}
+
+func (s *productSet[A, B]) IsEmpty() bool {
+	return s.a.IsEmpty() || s.b.IsEmpty()
+}
+
+func (s *productSet[A, B]) FastIsEmpty() (empty, ok bool) {
+	if fastIsEmpty(s.a) || fastIsEmpty(s.b) {
+		return true, true
+	}
+	aEmpty, aOk := s.a.FastIsEmpty()
+	bEmpty, bOk := s.b.FastIsEmpty()
+	return aEmpty || bEmpty, aOk && bOk
+}
+
+func (s *productSet[A, B]) FastCount() (count int, ok bool) {
+	aCount, aOk := s.a.FastCount()
+	bCount, bOk := s.b.FastCount()
+	if aOk && bOk {
+		return mulCount(aCount, bCount)
+	}
+	return 0, false
+}
+
+func (s *productSet[A, B]) FastCountUpTo(limit int) (count int, ok bool) {
+	if count, ok := s.FastCount(); ok {
+		if count < limit {
+			return count, true
+		}
+		return limit, true
+	}
+	return 0, false
+}
+
+func (s *productSet[A, B]) Has(p Pair[A, B]) bool {
+	return s.a.Has(p.First) && s.b.Has(p.Second)
+}
+
+func (s *productSet[A, B]) FastHas(p Pair[A, B]) (has, ok bool) {
+	aHas, aOk := s.a.FastHas(p.First)
+	if aOk && !aHas {
+		return false, true
+	}
+	bHas, bOk := s.b.FastHas(p.Second)
+	if bOk && !bHas {
+		return false, true
+	}
+	return aHas && bHas, aOk && bOk
+}
+
+func (s *productSet[A, B]) Range() frozen.Iterator[Pair[A, B]] {
+	return &productSetIterator[A, B]{a: s.a.Range(), b: s.b}
+}
+
+type productSetIterator[A, B any] struct {
+	a     frozen.Iterator[A]
+	b     SetOf[B]
+	bi    frozen.Iterator[B]
+	first A
+}
+
+func (i *productSetIterator[A, B]) Next() bool {
+	for i.bi == nil || !i.bi.Next() {
+		if !i.a.Next() {
+			return false
+		}
+		i.first = i.a.Value()
+		i.bi = i.b.Range()
+	}
+	return true
+}
+
+func (i *productSetIterator[A, B]) Value() Pair[A, B] {
+	return Pair[A, B]{First: i.first, Second: i.bi.Value()}
+}
+
+type productNSet[T any] struct {
+	baseSet[Tuple[T]]
+	sets []SetOf[T]
+}
+
+// ProductN returns the Cartesian product of sets, as Tuples with one element
+// from each set in order. The product of no sets has one element, the empty
+// Tuple.
+func ProductN[T any](sets ...SetOf[T]) SetOf[Tuple[T]] {
+	for _, set := range sets {
+		if fastIsEmpty(set) {
+			return Empty[Tuple[T]]()
+		}
+	}
+	s := &productNSet[T]{sets: append([]SetOf[T]{}, sets...)}
+	s.baseSet.set = s
+	return s
+}
+
+func (s *productNSet[T]) extent() extent {
+	e := finite
+	for _, set := range s.sets {
+		if f := extentOf(set); f > e {
+			e = f
+		}
+	}
+	return e
+}
+
+func (s *productNSet[T]) plan() (string, []any) {
+	inputs := make([]any, 0, len(s.sets))
+	for _, set := range s.sets {
+		inputs = append(inputs, set)
+	}
+	return fmt.Sprintf("product of %d", len(s.sets)), inputs
+}
+
+func (s *productNSet[T]) FastIsEmpty() (empty, ok bool) {
+	ok = true
+	for _, set := range s.sets {
+		e, o := set.FastIsEmpty()
+		if o && e {
+			return true, true
+		}
+		ok = ok && o
+	}
+	return false, ok
+}
+
+func (s *productNSet[T]) FastCount() (count int, ok bool) {
+	count = 1
+	for _, set := range s.sets {
+		n, ok := set.FastCount()
+		if !ok {
+			return 0, false
+		}
+		if count, ok = mulCount(count, n); !ok {
+			return 0, false
+		}
+	}
+	return count, true
+}
+
+func (s *productNSet[T]) FastCountUpTo(limit int) (count int, ok bool) {
+	if count, ok := s.FastCount(); ok {
+		if count < limit {
+			return count, true
+		}
+		return limit, true
+	}
+	return 0, false
+}
+
+func (s *productNSet[T]) Has(t Tuple[T]) bool {
+	if len(t) != len(s.sets) {
+		return false
+	}
+	for i, set := range s.sets {
+		if !set.Has(t[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *productNSet[T]) FastHas(t Tuple[T]) (has, ok bool) {
+	if len(t) != len(s.sets) {
+		return false, true
+	}
+	has, ok = true, true
+	for i, set := range s.sets {
+		h, o := set.FastHas(t[i])
+		if o && !h {
+			return false, true
+		}
+		has, ok = has && h, ok && o
+	}
+	return has, ok
+}
+
+func (s *productNSet[T]) Range() frozen.Iterator[Tuple[T]] {
+	return &productNSetIterator[T]{sets: s.sets}
+}
+
+// productNSetIterator works like an odometer, advancing the last iterator
+// fastest and restarting exhausted ones.
+type productNSetIterator[T any] struct {
+	sets  []SetOf[T]
+	iters []frozen.Iterator[T]
+	value Tuple[T]
+	done  bool
+}
+
+func (i *productNSetIterator[T]) Next() bool {
+	if i.done {
+		return false
+	}
+	if i.iters == nil {
+		i.iters = make([]frozen.Iterator[T], len(i.sets))
+		i.value = make(Tuple[T], len(i.sets))
+		for j, set := range i.sets {
+			i.iters[j] = set.Range()
+			if !i.iters[j].Next() {
+				i.done = true
+				return false
+			}
+			i.value[j] = i.iters[j].Value()
+		}
+		return true
+	}
+	for j := len(i.iters) - 1; ; j-- {
+		if j < 0 {
+			i.done = true
+			return false
+		}
+		if i.iters[j].Next() {
+			i.value = append(Tuple[T]{}, i.value...)
+			i.value[j] = i.iters[j].Value()
+			for k := j + 1; k < len(i.iters); k++ {
+				i.iters[k] = i.sets[k].Range()
+				i.iters[k].Next()
+				i.value[k] = i.iters[k].Value()
+			}
+			return true
+		}
+	}
+}
+
+func (i *productNSetIterator[T]) Value() Tuple[T] {
+	return i.value
+}
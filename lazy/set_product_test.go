@@ -0,0 +1,85 @@
+package lazy_test
+
+import (
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+func TestProduct(t *testing.T) {
+	t.Parallel()
+
+	a := lazy.From(frozen.NewSet(1, 2, 3))
+	b := lazy.From(frozen.NewSet("x", "y"))
+	p := lazy.Product(a, b)
+	assertFastCountEqual(t, 6, p)
+	assertFastCountUpToEqual(t, 4, p, 4)
+	assertFastNotIsEmpty(t, p)
+	assertFastHas(t, p, lazy.Pair[int, string]{First: 2, Second: "y"})
+	assertFastNotHas(t, p, lazy.Pair[int, string]{First: 4, Second: "y"})
+
+	expected := frozen.NewSet[lazy.Pair[int, string]]()
+	for i := a.Range(); i.Next(); {
+		for j := b.Range(); j.Next(); {
+			expected = expected.With(lazy.Pair[int, string]{First: i.Value(), Second: j.Value()})
+		}
+	}
+	test.True(t, p.Freeze().Equal(expected))
+	test.Equal(t, 6, p.CountUpTo(100))
+	test.Equal(t, "(1, x)", lazy.Pair[int, string]{First: 1, Second: "x"}.String())
+
+	assertFastIsEmpty(t, lazy.Product(a, lazy.Empty[string]()))
+	assertFastIsEmpty(t, lazy.Product(lazy.Empty[int](), lazy.Naturals()))
+
+	// Counts propagate only when both are cheap.
+	evens := a.Where(func(el int) bool { return el%2 == 0 })
+	_, ok := lazy.Product(evens, b).FastCount()
+	test.False(t, ok)
+	test.Equal(t, 2, lazy.Product(evens, b).Count())
+}
+
+func TestProductInfinite(t *testing.T) {
+	t.Parallel()
+
+	p := lazy.Product(lazy.From(frozen.NewSet("a")), lazy.Naturals())
+	test.Equal(t, 3, len(firstN(p, 3)))
+	test.True(t, p.Has(lazy.Pair[string, int]{First: "a", Second: 1_000_000}))
+	assertPanicsWith(t, lazy.ErrInfiniteSet, func() { p.Count() })
+}
+
+func TestProductN(t *testing.T) {
+	t.Parallel()
+
+	a := lazy.From(frozen.NewSet(1, 2))
+	b := lazy.From(frozen.NewSet(10, 20, 30))
+	c := lazy.From(frozen.NewSet(100))
+	p := lazy.ProductN(a, b, c)
+	assertFastCountEqual(t, 6, p)
+	assertFastHas(t, p, lazy.Tuple[int]{2, 30, 100})
+	assertFastNotHas(t, p, lazy.Tuple[int]{2, 30})
+	assertFastNotHas(t, p, lazy.Tuple[int]{2, 40, 100})
+
+	f := p.Freeze()
+	test.Equal(t, 6, f.Count())
+	for i := a.Range(); i.Next(); {
+		for j := b.Range(); j.Next(); {
+			test.True(t, f.Has(lazy.Tuple[int]{i.Value(), j.Value(), 100}))
+		}
+	}
+	test.Equal(t, "(1, 2, 3)", lazy.Tuple[int]{1, 2, 3}.String())
+
+	unit := lazy.ProductN[int]()
+	test.Equal(t, 1, unit.Count())
+	test.True(t, unit.Has(lazy.Tuple[int]{}))
+	assertFastIsEmpty(t, lazy.ProductN(a, lazy.Empty[int](), b))
+	test.Equal(t, 0, lazy.ProductN(a, b.Where(func(int) bool { return false })).Count())
+
+	// The values returned by the iterator aren't overwritten by later ones.
+	var tuples []lazy.Tuple[int]
+	for i := p.Range(); i.Next(); {
+		tuples = append(tuples, i.Value())
+	}
+	test.Equal(t, 6, frozen.NewSet(tuples...).Count())
+}
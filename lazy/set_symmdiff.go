@@ -1,5 +1,5 @@
 package lazy
 
-func symmetricDifference(a, b Set) Set {
+func symmetricDifference[T any](a, b SetOf[T]) SetOf[T] {
 	return a.Difference(b).Union(b.Difference(a))
 }
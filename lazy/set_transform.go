@@ -1,30 +1,62 @@
 package lazy
 
-type mapperSet struct {
-	baseSet
-	src Set
-	m   Mapper
+import "github.com/arr-ai/frozen"
+
+type mapperSet[T, U any] struct {
+	baseSet[U]
+	src SetOf[T]
+	m   func(el T) U
+}
+
+// SetMap returns a Set containing the results of calling m for all the
+// elements of s. Note that the result Set might have fewer elements than s.
+func SetMap[T, U any](s SetOf[T], m func(el T) U) SetOf[U] {
+	if empty, ok := s.FastIsEmpty(); ok && empty {
+		return Empty[U]()
+	}
+	if f, ok := frozenOf(s); ok {
+		return From(frozen.SetMap(f, m))
+	}
+	result := &mapperSet[T, U]{src: s, m: m}
+	result.baseSet.set = result
+	return memo[U](result)
+}
+
+// SetAs returns a Set with the elements of s converted to U, which must be
+// valid for every element of s.
+func SetAs[U, T any](s SetOf[T]) SetOf[U] {
+	return SetMap(s, func(el T) U { return any(el).(U) })
+}
+
+func (s *mapperSet[T, U]) extent() extent {
+	return extentOf(s.src)
+}
+
+func (s *mapperSet[T, U]) plan() (string, []any) {
+	return "map", []any{s.src}
 }
 
-func mapper(set Set, m Mapper) Set {
-	s := &mapperSet{src: set, m: m}
-	s.baseSet.set = s
-	return memo(s)
+// pushWhere filters the input of the map instead of its output, so that the
+// filter can fuse with others below the map. Elements that pass are mapped
+// twice, which is safe because mappers must be pure.
+func (s *mapperSet[T, U]) pushWhere(pred func(el U) bool) SetOf[U] {
+	m := s.m
+	return SetMap(where(s.src, func(el T) bool { return pred(m(el)) }), m)
 }
 
-func (s *mapperSet) Range() SetIterator {
-	return &mapperSetIterator{i: s.src.Range(), m: s.m}
+func (s *mapperSet[T, U]) Range() frozen.Iterator[U] {
+	return &mapperSetIterator[T, U]{i: s.src.Range(), m: s.m}
 }
 
-type mapperSetIterator struct {
-	i SetIterator
-	m Mapper
+type mapperSetIterator[T, U any] struct {
+	i frozen.Iterator[T]
+	m func(el T) U
 }
 
-func (s *mapperSetIterator) Next() bool {
+func (s *mapperSetIterator[T, U]) Next() bool {
 	return s.i.Next()
 }
 
-func (s *mapperSetIterator) Value() any {
+func (s *mapperSetIterator[T, U]) Value() U {
 	return s.m(s.i.Value())
 }
@@ -0,0 +1,42 @@
+package lazy_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+func TestSetTyped(t *testing.T) {
+	t.Parallel()
+
+	f := frozen.NewSet(1, 2, 3, 4, 5)
+	s := lazy.From(f)
+	odd := s.Where(func(el int) bool { return el%2 == 1 })
+	test.True(t, frozen.NewSet(1, 3, 5).Equal(odd.Freeze()))
+	test.True(t, odd.Has(3))
+	test.False(t, odd.Has(4))
+
+	strs := lazy.SetMap(odd, strconv.Itoa)
+	test.True(t, frozen.NewSet("1", "3", "5").Equal(strs.Freeze()))
+	assertFastCountEqual(t, 5, lazy.SetMap(s, strconv.Itoa))
+
+	other := lazy.From(frozen.NewSet(4, 5, 6, 7))
+	test.True(t, f.Union(other.Freeze()).Equal(s.Union(other).Freeze()))
+	test.True(t, frozen.NewSet(4, 5).Equal(s.Intersection(other).Freeze()))
+	test.True(t, frozen.NewSet(1, 2, 3).Equal(s.Difference(other).Freeze()))
+	test.True(t, frozen.NewSet(1, 2, 3, 6, 7).Equal(s.SymmetricDifference(other).Freeze()))
+
+	p := lazy.Powerset(odd)
+	test.Equal(t, 8, p.Count())
+	test.True(t, p.Has(frozen.NewSet(1, 5)))
+	test.False(t, p.Has(frozen.NewSet(2)))
+	test.True(t, frozen.Powerset(frozen.NewSet(1, 3, 5)).Equal(p.Freeze()))
+
+	e := lazy.Empty[string]()
+	test.True(t, lazy.SetMap(e, func(string) int { return 1 }).IsEmpty())
+
+	test.True(t, frozen.NewSet[any](1, 3, 5).Equal(lazy.SetAs[any](odd).Freeze()))
+}
@@ -1,22 +1,55 @@
 package lazy
 
-type unionSet struct {
-	baseSet
-	a, b Set
+import "github.com/arr-ai/frozen"
+
+type unionSet[T any] struct {
+	baseSet[T]
+	a, b SetOf[T]
 }
 
-func union(a, b Set) Set {
-	s := &unionSet{a: a, b: b}
+func union[T any](a, b SetOf[T]) SetOf[T] {
+	switch {
+	case fastIsEmpty(a):
+		return b
+	case fastIsEmpty(b):
+		return a
+	}
+	s := &unionSet[T]{a: a, b: b}
 	s.baseSet.set = s
-	return memo(s)
+	return memo[T](s)
+}
+
+func (s *unionSet[T]) extent() extent {
+	a, b := extentOf(s.a), extentOf(s.b)
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (s *unionSet[T]) plan() (string, []any) {
+	return "union", []any{s.a, s.b}
 }
 
-func (s *unionSet) FastCountUpTo(limit int) (count int, ok bool) {
-	if empty, ok := s.a.FastIsEmpty(); ok && empty {
-		return s.b.CountUpTo(limit), true
+func (s *unionSet[T]) FastIsEmpty() (empty, ok bool) {
+	aEmpty, aOk := s.a.FastIsEmpty()
+	bEmpty, bOk := s.b.FastIsEmpty()
+	switch {
+	case aOk && !aEmpty, bOk && !bEmpty:
+		return false, true
+	case aOk && bOk:
+		return true, true
+	default:
+		return false, false
 	}
-	if empty, ok := s.b.FastIsEmpty(); ok && empty {
-		return s.a.CountUpTo(limit), true
+}
+
+func (s *unionSet[T]) FastCountUpTo(limit int) (count int, ok bool) {
+	if fastIsEmpty(s.a) {
+		return s.b.FastCountUpTo(limit)
+	}
+	if fastIsEmpty(s.b) {
+		return s.a.FastCountUpTo(limit)
 	}
 	if count, ok := s.a.FastCountUpTo(limit); ok && count == limit {
 		return count, true
@@ -27,27 +60,41 @@ func (s *unionSet) FastCountUpTo(limit int) (count int, ok bool) {
 	return 0, false
 }
 
-func (s *unionSet) Has(el any) bool {
+func (s *unionSet[T]) Has(el T) bool {
+	if has, ok := s.FastHas(el); ok {
+		return has
+	}
 	return s.a.Has(el) || s.b.Has(el)
 }
 
-func (s *unionSet) FastHas(el any) (has, ok bool) {
-	if has, ok = s.a.FastHas(el); ok {
-		return has, ok
+func (s *unionSet[T]) hasFromInputs(el T) bool {
+	return s.Has(el)
+}
+
+func (s *unionSet[T]) FastHas(el T) (has, ok bool) {
+	aHas, aOk := s.a.FastHas(el)
+	if aOk && aHas {
+		return true, true
+	}
+	bHas, bOk := s.b.FastHas(el)
+	if bOk && bHas {
+		return true, true
 	}
-	return s.a.FastHas(el)
+	return false, aOk && bOk
 }
 
-func (s *unionSet) Range() SetIterator {
-	return &unionSetIterator{i: s.a.Range(), b: s.b}
+// Range iterates over a, then b. It may yield elements of both twice, but the
+// union is always memoized, and memoization weeds out duplicates.
+func (s *unionSet[T]) Range() frozen.Iterator[T] {
+	return &unionSetIterator[T]{i: s.a.Range(), b: s.b}
 }
 
-type unionSetIterator struct {
-	i SetIterator
-	b Set
+type unionSetIterator[T any] struct {
+	i frozen.Iterator[T]
+	b SetOf[T]
 }
 
-func (i *unionSetIterator) Next() bool {
+func (i *unionSetIterator[T]) Next() bool {
 	for {
 		if i.i.Next() {
 			return true
@@ -60,6 +107,6 @@ func (i *unionSetIterator) Next() bool {
 	}
 }
 
-func (i *unionSetIterator) Value() any {
+func (i *unionSetIterator[T]) Value() T {
 	return i.i.Value()
 }
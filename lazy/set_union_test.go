@@ -0,0 +1,35 @@
+package lazy_test
+
+import (
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+func TestSetUnionFastHas(t *testing.T) {
+	t.Parallel()
+
+	s := lazy.From(frozen.NewSet(1, 2, 3))
+	other := lazy.From(frozen.NewSet(4, 5))
+
+	assertFastHas(t, s.Union(other), 1)
+	assertFastHas(t, s.Union(other), 5)
+	assertFastNotHas(t, s.Union(other), 8)
+}
+
+func TestSetUnionFastCountUpTo(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	odd := lazy.From(frozen.NewSet(1, 2, 3)).Where(func(el int) bool {
+		calls++
+		return el%2 == 1
+	})
+	u := lazy.Empty[int]().Union(odd)
+	_, ok := u.FastCountUpTo(10)
+	test.False(t, ok)
+	test.Equal(t, 0, calls)
+	test.Equal(t, 2, u.CountUpTo(10))
+}
@@ -0,0 +1,301 @@
+package lazy
+
+import (
+	"fmt"
+
+	"github.com/arr-ai/frozen"
+)
+
+// Predicate represents a function that returns true iff el satisfies some
+// condition. The function must be pure. That is: a == b => p(a) == p(b).
+type Predicate func(el any) bool
+
+// Mapper represents a function that transforms el. The function must be
+// pure. That is: a == b => f(a) == f(b).
+type Mapper func(el any) any
+
+// Set represents a set of elements of any type. It is the untyped API that
+// predates generics, and wraps a SetOf[any]. Use Typed and Untyped to convert
+// between the two.
+type Set interface {
+	// IsEmpty returns true iff there are no elements in this Set.
+	IsEmpty() bool
+
+	// FastIsEmpty returns IsEmpty() in O(1) time. Otherwise, ok=false.
+	FastIsEmpty() (empty, ok bool)
+
+	// Count returns the number of elements in this Set.
+	Count() int
+
+	// FastCount returns Count() in O(1) time. Otherwise, ok=false.
+	FastCount() (count int, ok bool)
+
+	// CountUpTo returns the cardinality of this set, up to limit. This avoids
+	// the problem of counting intractable sets.
+	CountUpTo(limit int) int
+
+	// FastCountUpTo returns CountUpTo() in O(1) time. Otherwise, ok=false.
+	FastCountUpTo(limit int) (count int, ok bool)
+
+	// Freeze returns a Set backed by a frozen.Set with all the elements in
+	// this Set.
+	Freeze() Set
+
+	// Range returns an iterator over this Set. Traversal order is indeterminate
+	// and may differ from one invocation of Range to the next.
+	Range() SetIterator
+
+	// Hash returns a hash derived from the elements of the set.
+	Hash(seed uintptr) uintptr
+
+	// Equal implements value.Equaler, returning true iff this Set and set
+	// have all the same elements.
+	Equal(set any) bool
+
+	// EqualSet returns true iff this Set and set have all the same elements.
+	EqualSet(set Set) bool
+
+	// IsSubset returns true iff every element of this Set is in set.
+	IsSubsetOf(set Set) bool
+
+	// Has returns true iff el is in this Set.
+	Has(el any) bool
+
+	// FastHas returns Has(el) in <= O(log n) time. Otherwise, ok=false.
+	FastHas(el any) (has, ok bool)
+
+	// With returns a Set containing all the elements from this Set and v.
+	With(v any) Set
+
+	// Without returns a Set containing all the elements from this Set except
+	// v.
+	Without(v any) Set
+
+	// Where returns a Set containing all the elements from this Set that
+	// satisfy pred.
+	Where(pred Predicate) Set
+
+	// Map returns a Set containing the results of calling m for all the
+	// elements of this Set. Note that the result Set might have fewer elements
+	// than this Set.
+	Map(m Mapper) Set
+
+	// Union returns a Set containing all values that are in either this Set or
+	// set.
+	Union(set Set) Set
+
+	// Intersection returns a Set containing all values that are in both this
+	// Set and set.
+	Intersection(set Set) Set
+
+	// Difference returns a Set containing all values that are in this Set but
+	// not in set.
+	Difference(set Set) Set
+
+	// SymmetricDifference returns a Set containing all values that are in
+	// either this Set or set, but not both.
+	SymmetricDifference(set Set) Set
+
+	// Powerset returns the set of all subsets of this Set, as frozen.Set[any]
+	// elements.
+	Powerset() Set
+}
+
+// SetIterator iterates over the elements of a Set.
+type SetIterator interface {
+	Next() bool
+	Value() any
+}
+
+// Frozen returns a Set with the elements of set.
+func Frozen(set frozen.Set[any]) Set {
+	return Untyped(From(set))
+}
+
+// EmptySet is the empty Set.
+type EmptySet struct {
+	untypedSet
+}
+
+// Untyped returns s as a Set.
+func Untyped(s SetOf[any]) Set {
+	return untypedSet{s: s}
+}
+
+// Typed returns s as a SetOf[any].
+func Typed(s Set) SetOf[any] {
+	switch s := s.(type) {
+	case untypedSet:
+		return s.typed()
+	case EmptySet:
+		return s.typed()
+	case untypedPowerset:
+		return s.typed()
+	}
+	t := &typedSet{s: s}
+	t.baseSet.set = t
+	return t
+}
+
+// untypedSet adapts a SetOf[any] to Set. The zero untypedSet is empty.
+type untypedSet struct {
+	s SetOf[any]
+}
+
+func (u untypedSet) typed() SetOf[any] {
+	if u.s == nil {
+		return Empty[any]()
+	}
+	return u.s
+}
+
+func (u untypedSet) String() string {
+	return fmt.Sprint(u.typed())
+}
+
+func (u untypedSet) IsEmpty() bool {
+	return u.typed().IsEmpty()
+}
+
+func (u untypedSet) FastIsEmpty() (empty, ok bool) {
+	return u.typed().FastIsEmpty()
+}
+
+func (u untypedSet) Count() int {
+	return u.typed().Count()
+}
+
+func (u untypedSet) FastCount() (count int, ok bool) {
+	return u.typed().FastCount()
+}
+
+func (u untypedSet) CountUpTo(limit int) int {
+	return u.typed().CountUpTo(limit)
+}
+
+func (u untypedSet) FastCountUpTo(limit int) (count int, ok bool) {
+	return u.typed().FastCountUpTo(limit)
+}
+
+func (u untypedSet) Freeze() Set {
+	return Frozen(u.typed().Freeze())
+}
+
+func (u untypedSet) Range() SetIterator {
+	return u.typed().Range()
+}
+
+func (u untypedSet) Hash(seed uintptr) uintptr {
+	return u.typed().Hash(seed)
+}
+
+func (u untypedSet) Equal(set any) bool {
+	if s, ok := set.(Set); ok {
+		return u.EqualSet(s)
+	}
+	return u.typed().Equal(set)
+}
+
+func (u untypedSet) EqualSet(set Set) bool {
+	return u.typed().EqualSet(Typed(set))
+}
+
+func (u untypedSet) IsSubsetOf(set Set) bool {
+	return u.typed().IsSubsetOf(Typed(set))
+}
+
+func (u untypedSet) Has(el any) bool {
+	return u.typed().Has(el)
+}
+
+func (u untypedSet) FastHas(el any) (has, ok bool) {
+	return u.typed().FastHas(el)
+}
+
+func (u untypedSet) With(v any) Set {
+	return Untyped(u.typed().With(v))
+}
+
+func (u untypedSet) Without(v any) Set {
+	return Untyped(u.typed().Without(v))
+}
+
+func (u untypedSet) Where(pred Predicate) Set {
+	return Untyped(u.typed().Where(pred))
+}
+
+func (u untypedSet) Map(m Mapper) Set {
+	return Untyped(SetMap[any, any](u.typed(), m))
+}
+
+func (u untypedSet) Union(set Set) Set {
+	return Untyped(u.typed().Union(Typed(set)))
+}
+
+func (u untypedSet) Intersection(set Set) Set {
+	return Untyped(u.typed().Intersection(Typed(set)))
+}
+
+func (u untypedSet) Difference(set Set) Set {
+	return Untyped(u.typed().Difference(Typed(set)))
+}
+
+func (u untypedSet) SymmetricDifference(set Set) Set {
+	return Untyped(u.typed().SymmetricDifference(Typed(set)))
+}
+
+func (u untypedSet) Powerset() Set {
+	p := Powerset(u.typed())
+	return untypedPowerset{untypedSet: untypedSet{s: SetAs[any](p)}, p: p}
+}
+
+// typedSet adapts a Set that wasn't made by Untyped to SetOf[any].
+type typedSet struct {
+	baseSet[any]
+	s Set
+}
+
+func (s *typedSet) plan() (string, []any) {
+	return "untyped", nil
+}
+
+func (s *typedSet) FastIsEmpty() (empty, ok bool) {
+	return s.s.FastIsEmpty()
+}
+
+func (s *typedSet) FastCount() (count int, ok bool) {
+	return s.s.FastCount()
+}
+
+func (s *typedSet) FastCountUpTo(limit int) (count int, ok bool) {
+	return s.s.FastCountUpTo(limit)
+}
+
+func (s *typedSet) FastHas(el any) (has, ok bool) {
+	return s.s.FastHas(el)
+}
+
+func (s *typedSet) Range() frozen.Iterator[any] {
+	return s.s.Range()
+}
+
+// untypedPowerset tests membership in a powerset by subset, rather than by
+// searching its elements.
+type untypedPowerset struct {
+	untypedSet
+	p SetOf[frozen.Set[any]]
+}
+
+func (u untypedPowerset) Has(el any) bool {
+	if f, ok := el.(frozen.Set[any]); ok {
+		return u.p.Has(f)
+	}
+	return false
+}
+
+func (u untypedPowerset) FastHas(el any) (has, ok bool) {
+	if f, ok := el.(frozen.Set[any]); ok {
+		return u.p.FastHas(f)
+	}
+	return false, true
+}
@@ -0,0 +1,47 @@
+package lazy_test
+
+import (
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/lazy"
+)
+
+func TestSetUntyped(t *testing.T) {
+	t.Parallel()
+
+	f := frozen.NewSet[any](1, 2, 3)
+	var s lazy.Set = lazy.Frozen(f)
+	var empty lazy.Set = lazy.EmptySet{}
+
+	assertSetOps(t, f, lazy.Typed(s))
+	assertSetOps(t, frozen.Set[any]{}, lazy.Typed(empty))
+	assertSetOps(t, f.With(4), lazy.Typed(s.With(4)))
+	assertSetOps(t, f.Union(frozen.NewSet[any](5)), lazy.Typed(s.Union(lazy.Frozen(frozen.NewSet[any](5)))))
+	assertSetOps(t, frozen.NewSet[any](1, 3), lazy.Typed(s.Where(func(el any) bool { return el != 2 })))
+	assertSetOps(t, frozen.NewSet[any](2, 4, 6), lazy.Typed(s.Map(func(el any) any { return 2 * el.(int) })))
+
+	test.True(t, s.Freeze().Equal(s))
+	test.True(t, empty.Equal(lazy.Frozen(frozen.Set[any]{})))
+	test.False(t, empty.Equal(s))
+	test.True(t, empty.IsSubsetOf(s))
+	assertFastIsEmpty(t, lazy.Typed(empty.Map(func(any) any { return 42 })))
+
+	n := 0
+	for i := s.Range(); i.Next(); {
+		n += i.Value().(int)
+	}
+	test.Equal(t, 6, n)
+
+	p := s.Powerset()
+	test.Equal(t, 8, p.Count())
+	test.True(t, p.Has(frozen.NewSet[any](1, 3)))
+	test.False(t, p.Has(frozen.NewSet[any](1, 4)))
+	test.False(t, p.Has(1))
+	test.Equal(t, 1, empty.Powerset().Count())
+
+	typed := lazy.From(f)
+	test.True(t, lazy.Untyped(typed).Equal(s))
+	test.True(t, lazy.Typed(lazy.Untyped(typed)) == typed)
+}
@@ -1,38 +1,92 @@
 package lazy
 
-type whereSet struct {
-	baseSet
-	src  Set
-	pred Predicate
+import (
+	"fmt"
+
+	"github.com/arr-ai/frozen"
+)
+
+type whereSet[T any] struct {
+	baseSet[T]
+	src  SetOf[T]
+	pred func(el T) bool
+
+	// preds counts the predicates fused into pred.
+	preds int
+}
+
+// wherePusher is implemented by Sets that can apply a filter to their inputs
+// instead of their output.
+type wherePusher[T any] interface {
+	pushWhere(pred func(el T) bool) SetOf[T]
 }
 
-func where(set Set, pred Predicate) Set {
-	s := &whereSet{src: set, pred: pred}
+// where filters set by pred. Filters over filters are fused into one pass, and
+// filters over unions and maps are pushed down to their inputs, where they may
+// fuse further.
+func where[T any](set SetOf[T], pred func(el T) bool) SetOf[T] {
+	if fastIsEmpty(set) {
+		return Empty[T]()
+	}
+	switch src := unmemo(set).(type) {
+	case *whereSet[T]:
+		p := src.pred
+		return newWhere(src.src, func(el T) bool { return p(el) && pred(el) }, src.preds+1)
+	case *unionSet[T]:
+		return union(where(src.a, pred), where(src.b, pred))
+	case wherePusher[T]:
+		return src.pushWhere(pred)
+	}
+	return newWhere(set, pred, 1)
+}
+
+func newWhere[T any](set SetOf[T], pred func(el T) bool, preds int) SetOf[T] {
+	s := &whereSet[T]{src: set, pred: pred, preds: preds}
 	s.baseSet.set = s
-	return memo(s)
+	return memo[T](s)
 }
 
-func (s *whereSet) FastIsEmpty() (empty, ok bool) {
+func (s *whereSet[T]) extent() extent {
+	return extentOf(s.src)
+}
+
+func (s *whereSet[T]) plan() (string, []any) {
+	if s.preds == 1 {
+		return "where", []any{s.src}
+	}
+	return fmt.Sprintf("where (%d predicates fused)", s.preds), []any{s.src}
+}
+
+func (s *whereSet[T]) FastIsEmpty() (empty, ok bool) {
 	if empty, ok = s.src.FastIsEmpty(); ok && empty {
 		return
 	}
 	return false, false
 }
 
-func (s *whereSet) Has(el any) bool {
+func (s *whereSet[T]) Has(el T) bool {
 	return s.pred(el) && s.src.Has(el)
 }
 
-func (s *whereSet) Range() SetIterator {
-	return &whereSetIterator{i: s.src.Range(), pred: s.pred}
+func (s *whereSet[T]) hasFromInputs(el T) bool {
+	return s.Has(el)
+}
+
+// FastHas doesn't call pred, which might be slow.
+func (s *whereSet[T]) FastHas(T) (has, ok bool) {
+	return false, false
+}
+
+func (s *whereSet[T]) Range() frozen.Iterator[T] {
+	return &whereSetIterator[T]{i: s.src.Range(), pred: s.pred}
 }
 
-type whereSetIterator struct {
-	i    SetIterator
-	pred Predicate
+type whereSetIterator[T any] struct {
+	i    frozen.Iterator[T]
+	pred func(el T) bool
 }
 
-func (s *whereSetIterator) Next() bool {
+func (s *whereSetIterator[T]) Next() bool {
 	for s.i.Next() {
 		if s.pred(s.i.Value()) {
 			return true
@@ -41,6 +95,6 @@ func (s *whereSetIterator) Next() bool {
 	return false
 }
 
-func (s *whereSetIterator) Value() any {
+func (s *whereSetIterator[T]) Value() T {
 	return s.i.Value()
 }
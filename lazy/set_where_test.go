@@ -11,7 +11,7 @@ import (
 func TestSetWhereEmpty(t *testing.T) {
 	t.Parallel()
 
-	f := lazy.Frozen(frozen.Set[any]{})
+	f := lazy.From(frozen.Set[any]{})
 
 	test.True(t, f.IsEmpty())
 	assertFastIsEmpty(t, f)
@@ -21,21 +21,40 @@ func TestSetWhereEmpty(t *testing.T) {
 	test.Equal(t, 0, f.CountUpTo(1))
 	assertFastCountUpToEqual(t, 0, f, 0)
 	assertFastCountUpToEqual(t, 0, f, 1)
-	test.True(t, f.Equal(lazy.Frozen(frozen.Set[any]{})))
-	test.True(t, f.EqualSet(lazy.Frozen(frozen.Set[any]{})))
-	test.False(t, f.EqualSet(lazy.Frozen(frozen.NewSet[any](1))))
+	test.True(t, f.Equal(lazy.From(frozen.Set[any]{})))
+	test.True(t, f.EqualSet(lazy.From(frozen.Set[any]{})))
+	test.False(t, f.EqualSet(lazy.From(frozen.NewSet[any](1))))
 	test.NotEqual(t, 0, f.Hash(0))
 	test.False(t, f.Has(3))
 	assertFastNotHas(t, f, 3)
-	test.True(t, f.IsSubsetOf(lazy.Frozen(frozen.Set[any]{})))
+	test.True(t, f.IsSubsetOf(lazy.From(frozen.Set[any]{})))
 	test.False(t, f.Range().Next())
 	assertFastIsEmpty(t, f.Where(func(any) bool { return true }))
 	assertFastNotIsEmpty(t, f.With(2))
 	assertFastIsEmpty(t, f.Without(2))
 	assertFastIsEmpty(t, f.With(2).Without(2))
-	assertFastIsEmpty(t, f.Map(func(any) any { return 42 }))
-	test.True(t, f.Union(lazy.Frozen(frozen.Set[any]{})).IsEmpty())
-	assertFastIsEmpty(t, f.Intersection(lazy.Frozen(frozen.NewSet[any](1, 2, 3))))
-	assertFastIsEmpty(t, f.Intersection(lazy.Frozen(frozen.NewSet[any](1, 2, 3))))
-	assertFastIsEmpty(t, f.Powerset())
+	assertFastIsEmpty(t, lazy.SetMap(f, func(any) any { return 42 }))
+	test.True(t, f.Union(lazy.From(frozen.Set[any]{})).IsEmpty())
+	assertFastIsEmpty(t, f.Intersection(lazy.From(frozen.NewSet[any](1, 2, 3))))
+	assertFastIsEmpty(t, f.Intersection(lazy.From(frozen.NewSet[any](1, 2, 3))))
+	assertFastNotIsEmpty(t, lazy.Powerset(f))
+}
+
+func TestSetWhereHas(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	s := lazy.From(frozen.NewSet(1, 2, 3, 4)).Where(func(el int) bool {
+		calls++
+		return el%2 == 0
+	})
+	_, ok := s.FastHas(2)
+	test.False(t, ok)
+	test.Equal(t, 0, calls)
+
+	// Has tests the predicate and the input instead of iterating.
+	test.True(t, s.Has(2))
+	test.False(t, s.Has(3))
+	test.False(t, s.Has(6))
+	test.Equal(t, 3, calls)
 }
@@ -18,63 +18,63 @@ func extraArgs(msgAndArgs []any, msg string, args ...any) []any {
 	return append(append([]any{format + msg}, args2...), args...)
 }
 
-func assertFastIsEmpty(t *testing.T, a lazy.Set) bool {
+func assertFastIsEmpty[T any](t *testing.T, a lazy.SetOf[T]) bool {
 	t.Helper()
 
 	empty, ok := a.FastIsEmpty()
 	return test.True(t, ok) && test.True(t, empty)
 }
 
-func assertFastNotIsEmpty(t *testing.T, a lazy.Set) bool {
+func assertFastNotIsEmpty[T any](t *testing.T, a lazy.SetOf[T]) bool {
 	t.Helper()
 
 	empty, ok := a.FastIsEmpty()
 	return test.True(t, ok) && test.False(t, empty)
 }
 
-func assertEqualSet(t *testing.T, expected, s lazy.Set, msgAndArgs ...any) bool {
+func assertEqualSet(t *testing.T, expected, s lazy.SetOf[any], msgAndArgs ...any) bool {
 	t.Helper()
 
 	return test.True(t, expected.EqualSet(s),
 		extraArgs(msgAndArgs, "\nexpected=%v\nactual  =%v", expected.Freeze(), s.Freeze())...)
 }
 
-func assertNotEqualSet(t *testing.T, expected, s lazy.Set, msgAndArgs ...any) bool {
+func assertNotEqualSet(t *testing.T, expected, s lazy.SetOf[any], msgAndArgs ...any) bool {
 	t.Helper()
 
 	return test.False(t, expected.EqualSet(s),
 		extraArgs(msgAndArgs, "\nunexpected=%v\nactual    =%v", expected.Freeze(), s.Freeze())...)
 }
 
-func assertFastCountEqual(t *testing.T, expected int, a lazy.Set) bool {
+func assertFastCountEqual[T any](t *testing.T, expected int, a lazy.SetOf[T]) bool {
 	t.Helper()
 
 	count, ok := a.FastCount()
 	return test.True(t, ok) && test.Equal(t, expected, count)
 }
 
-func assertFastCountUpToEqual(t *testing.T, expected int, a lazy.Set, limit int) bool {
+func assertFastCountUpToEqual[T any](t *testing.T, expected int, a lazy.SetOf[T], limit int) bool {
 	t.Helper()
 
 	count, ok := a.FastCountUpTo(limit)
 	return test.True(t, ok) && test.Equal(t, expected, count)
 }
 
-func assertFastHas(t *testing.T, a lazy.Set, el any) bool {
+func assertFastHas[T any](t *testing.T, a lazy.SetOf[T], el T) bool {
 	t.Helper()
 
 	equal, ok := a.FastHas(el)
 	return test.True(t, ok) && test.True(t, equal)
 }
 
-func assertFastNotHas(t *testing.T, a lazy.Set, el any) bool {
+func assertFastNotHas[T any](t *testing.T, a lazy.SetOf[T], el T) bool {
 	t.Helper()
 
 	equal, ok := a.FastHas(el)
 	return test.True(t, ok) && test.False(t, equal)
 }
 
-func assertRangeEmits(t *testing.T, expected frozen.Set[any], a lazy.Set) bool {
+func assertRangeEmits(t *testing.T, expected frozen.Set[any], a lazy.SetOf[any]) bool {
 	t.Helper()
 
 	var b frozen.SetBuilder[any]
@@ -91,18 +91,18 @@ func extractInt(i any) int {
 	switch x := i.(type) {
 	case int:
 		return x
-	case lazy.Set:
+	case frozen.Set[any]:
 		return x.Count()
 	default:
 		panic("cannot extract int")
 	}
 }
 
-func assertSetOps(t *testing.T, golden frozen.Set[any], s lazy.Set) { //nolint:funlen
+func assertSetOps(t *testing.T, golden frozen.Set[any], s lazy.SetOf[any]) { //nolint:funlen
 	t.Helper()
 
 	count := golden.Count()
-	fgolden := lazy.Frozen(golden)
+	fgolden := lazy.From(golden)
 
 	test.Equal(t, golden.IsEmpty(), s.IsEmpty())
 
@@ -111,8 +111,8 @@ func assertSetOps(t *testing.T, golden frozen.Set[any], s lazy.Set) { //nolint:f
 	assertEqualSet(t, fgolden, s)
 	assertEqualSet(t, s, fgolden)
 
-	assertNotEqualSet(t, lazy.Frozen(golden.With(42)), s)
-	assertNotEqualSet(t, s, lazy.Frozen(golden.With(42)))
+	assertNotEqualSet(t, lazy.From(golden.With(42)), s)
+	assertNotEqualSet(t, s, lazy.From(golden.With(42)))
 
 	test.Equal(t, 0, s.CountUpTo(0))
 	if count > 0 {
@@ -121,9 +121,9 @@ func assertSetOps(t *testing.T, golden frozen.Set[any], s lazy.Set) { //nolint:f
 	test.Equal(t, count, s.CountUpTo(count))
 	test.Equal(t, count, s.CountUpTo(count+1))
 
-	test.Equal(t, golden.Equal(frozen.Set[any]{}), s.Equal(lazy.Frozen(frozen.Set[any]{})))
-	test.Equal(t, golden.Equal(frozen.Set[any]{}), s.EqualSet(lazy.Frozen(frozen.Set[any]{})))
-	test.False(t, golden.Equal(frozen.NewSet[any](1)), s.EqualSet(lazy.Frozen(frozen.NewSet[any](1))))
+	test.Equal(t, golden.Equal(frozen.Set[any]{}), s.Equal(lazy.From(frozen.Set[any]{})))
+	test.Equal(t, golden.Equal(frozen.Set[any]{}), s.EqualSet(lazy.From(frozen.Set[any]{})))
+	test.False(t, golden.Equal(frozen.NewSet[any](1)), s.EqualSet(lazy.From(frozen.NewSet[any](1))))
 
 	test.NotEqual(t, 0, s.Hash(0))
 
@@ -143,17 +143,17 @@ func assertSetOps(t *testing.T, golden frozen.Set[any], s lazy.Set) { //nolint:f
 		func(i any) bool { return extractInt(i)%2 == 0 },
 		func(i any) bool { return extractInt(i) < 3 },
 	} {
-		expected := lazy.Frozen(golden.Where(pred))
+		expected := lazy.From(golden.Where(pred))
 		actual := s.Where(pred)
 		assertEqualSet(t, expected, actual, "i=%v", i)
 	}
 
 	test.False(t, s.With(2).IsEmpty())
 
-	assertEqualSet(t, lazy.Frozen(golden.Without(2)), s.Without(2))
-	assertEqualSet(t, lazy.Frozen(golden.With(2).Without(2)), s.With(2).Without(2))
-	assertEqualSet(t, lazy.Frozen(golden.Without(42)), s.Without(42))
-	assertEqualSet(t, lazy.Frozen(golden.With(42).Without(42)), s.With(42).Without(42))
+	assertEqualSet(t, lazy.From(golden.Without(2)), s.Without(2))
+	assertEqualSet(t, lazy.From(golden.With(2).Without(2)), s.With(2).Without(2))
+	assertEqualSet(t, lazy.From(golden.Without(42)), s.Without(42))
+	assertEqualSet(t, lazy.From(golden.With(42).Without(42)), s.With(42).Without(42))
 
 	for i, m := range []func(any) any{
 		func(any) any { return 42 },
@@ -161,7 +161,7 @@ func assertSetOps(t *testing.T, golden frozen.Set[any], s lazy.Set) { //nolint:f
 		func(i any) any { return 2 * extractInt(i) },
 		func(i any) any { return extractInt(i) / 2 },
 	} {
-		assertEqualSet(t, lazy.Frozen(frozen.SetMap(golden, m)), s.Map(m), "i=%v", i)
+		assertEqualSet(t, lazy.From(frozen.SetMap(golden, m)), lazy.SetMap(s, m), "i=%v", i)
 	}
 
 	for i, u := range []frozen.Set[any]{
@@ -170,14 +170,14 @@ func assertSetOps(t *testing.T, golden frozen.Set[any], s lazy.Set) { //nolint:f
 		frozen.NewSet[any](1, 2, 3, 4),
 		frozen.NewSet[any](4, 5),
 	} {
-		assertEqualSet(t, lazy.Frozen(golden.Union(u)), s.Union(lazy.Frozen(u)), "i=%v", i)
-		assertEqualSet(t, lazy.Frozen(golden.Intersection(u)), s.Intersection(lazy.Frozen(u)), "i=%v", i)
-		assertEqualSet(t, lazy.Frozen(golden.Difference(u)), s.Difference(lazy.Frozen(u)), "i=%v", i)
+		assertEqualSet(t, lazy.From(golden.Union(u)), s.Union(lazy.From(u)), "i=%v", i)
+		assertEqualSet(t, lazy.From(golden.Intersection(u)), s.Intersection(lazy.From(u)), "i=%v", i)
+		assertEqualSet(t, lazy.From(golden.Difference(u)), s.Difference(lazy.From(u)), "i=%v", i)
 		assertEqualSet(t,
-			lazy.Frozen(golden.SymmetricDifference(u)),
-			s.SymmetricDifference(lazy.Frozen(u)),
+			lazy.From(golden.SymmetricDifference(u)),
+			s.SymmetricDifference(lazy.From(u)),
 			"i=%v u=%v", i, u)
 	}
 
-	test.Equal(t, 1<<uint(golden.Count()), s.Powerset().Count())
+	test.Equal(t, 1<<uint(golden.Count()), lazy.Powerset(s).Count())
 }
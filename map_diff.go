@@ -0,0 +1,45 @@
+package frozen
+
+import (
+	"github.com/arr-ai/frozen/internal/pkg/value"
+)
+
+// MapDiff describes the differences between two Maps.
+type MapDiff[K any, V any] struct {
+	// Added holds entries whose keys are only in the newer Map.
+	Added Map[K, V]
+
+	// Removed holds entries whose keys are only in the older Map.
+	Removed Map[K, V]
+
+	// Updated holds the newer values of keys that are in both Maps, but with
+	// different values.
+	Updated Map[K, V]
+}
+
+// IsEmpty returns true iff the diff records no changes.
+func (d MapDiff[K, V]) IsEmpty() bool {
+	return d.Added.IsEmpty() && d.Removed.IsEmpty() && d.Updated.IsEmpty()
+}
+
+// Diff returns the changes required to turn m into n. Subtrees that m and n
+// share are skipped, so diffing two versions of a Map derived from each other
+// costs time proportional to the size of the change.
+func (m Map[K, V]) Diff(n Map[K, V]) MapDiff[K, V] {
+	var added, removed, updated MapBuilder[K, V]
+	m.tree.Diff(n.tree, func(a, b *mapEntry[K, V]) {
+		switch {
+		case b == nil:
+			removed.Put(a.Key, a.Value)
+		case a == nil:
+			added.Put(b.Key, b.Value)
+		case !value.Equal(a.Value, b.Value):
+			updated.Put(b.Key, b.Value)
+		}
+	})
+	return MapDiff[K, V]{
+		Added:   added.Finish(),
+		Removed: removed.Finish(),
+		Updated: updated.Finish(),
+	}
+}
@@ -0,0 +1,160 @@
+package frozen
+
+import (
+	"github.com/arr-ai/frozen/internal/pkg/value"
+)
+
+// Presence records which inputs of a three-way merge contain a key.
+type Presence uint8
+
+const (
+	// InBase means the key is in the common ancestor.
+	InBase Presence = 1 << iota
+
+	// InOurs means the key is in our side of the merge.
+	InOurs
+
+	// InTheirs means the key is in their side of the merge.
+	InTheirs
+)
+
+// Conflict describes a key that both sides of a three-way merge changed in
+// different ways. Values that are absent, as indicated by Presence, are zero.
+type Conflict[V any] struct {
+	Base     V
+	Ours     V
+	Theirs   V
+	Presence Presence
+}
+
+// MergeResolver resolves a key that both sides of a three-way merge changed in
+// different ways. It returns the merged value and true, or false if it cannot
+// resolve the conflict.
+type MergeResolver[K any, V any] func(key K, base, ours, theirs V, presence Presence) (V, bool)
+
+// Merge3 performs a three-way merge of ours and theirs, which were both derived
+// from base. Keys changed on only one side take that side's change. Keys that
+// both sides changed in the same way take the change. For all other keys,
+// resolve is called. If resolve is nil or fails, the key keeps our value
+// and is reported in the returned conflicts.
+//
+// Subtrees that either side shares with base are skipped without being
+// visited, so the cost is proportional to the size of the changes.
+func Merge3[K any, V any](
+	base, ours, theirs Map[K, V],
+	resolve MergeResolver[K, V],
+) (merged Map[K, V], conflicts Map[K, Conflict[V]]) {
+	ourDiff := base.Diff(ours)
+	if ourDiff.IsEmpty() {
+		return theirs, conflicts
+	}
+	theirDiff := base.Diff(theirs)
+	if theirDiff.IsEmpty() {
+		return ours, conflicts
+	}
+
+	var puts MapBuilder[K, V]
+	var removes []K
+	var cb MapBuilder[K, Conflict[V]]
+
+	// Start from theirs and replay our changes onto it.
+	apply := func(key K, v V, present bool) {
+		tv, inTheirs := theirs.Get(key)
+		theirsChanged := theirDiff.Added.Has(key) || theirDiff.Removed.Has(key) || theirDiff.Updated.Has(key)
+		switch {
+		case !theirsChanged:
+		case present == inTheirs && (!present || value.Equal(v, tv)):
+			return
+		default:
+			c := Conflict[V]{Ours: v, Theirs: tv}
+			var inBase bool
+			if c.Base, inBase = base.Get(key); inBase {
+				c.Presence |= InBase
+			}
+			if present {
+				c.Presence |= InOurs
+			}
+			if inTheirs {
+				c.Presence |= InTheirs
+			}
+			if resolve != nil {
+				if r, ok := resolve(key, c.Base, c.Ours, c.Theirs, c.Presence); ok {
+					puts.Put(key, r)
+					return
+				}
+			}
+			cb.Put(key, c)
+		}
+		if present {
+			puts.Put(key, v)
+		} else {
+			removes = append(removes, key)
+		}
+	}
+
+	for i := ourDiff.Added.Range(); i.Next(); {
+		apply(i.Key(), i.Value(), true)
+	}
+	for i := ourDiff.Updated.Range(); i.Next(); {
+		apply(i.Key(), i.Value(), true)
+	}
+	for i := ourDiff.Removed.Range(); i.Next(); {
+		var zero V
+		apply(i.Key(), zero, false)
+	}
+
+	merged = theirs.Update(puts.Finish())
+	for _, key := range removes {
+		merged = merged.Without(key)
+	}
+	return merged, cb.Finish()
+}
+
+// NestedConflict describes a conflict found by Merge3Nested at Path.
+type NestedConflict struct {
+	Path []string
+	Conflict[any]
+}
+
+// Merge3Nested performs a three-way merge of trees of Maps. Where both sides
+// changed the same key and both new values are Map[string, any], the values
+// are merged recursively instead of being treated as a conflict. resolve
+// receives the path from the root to the conflicting key.
+func Merge3Nested(
+	base, ours, theirs Map[string, any],
+	resolve func(path []string, base, ours, theirs any, presence Presence) (any, bool),
+) (Map[string, any], []NestedConflict) {
+	var conflicts []NestedConflict
+	merged := merge3Nested(nil, base, ours, theirs, resolve, &conflicts)
+	return merged, conflicts
+}
+
+func merge3Nested(
+	path []string,
+	base, ours, theirs Map[string, any],
+	resolve func(path []string, base, ours, theirs any, presence Presence) (any, bool),
+	conflicts *[]NestedConflict,
+) Map[string, any] {
+	subpath := func(key string) []string {
+		return append(append(make([]string, 0, len(path)+1), path...), key)
+	}
+	merged, cs := Merge3(base, ours, theirs,
+		func(key string, b, o, t any, presence Presence) (any, bool) {
+			if presence&(InOurs|InTheirs) == InOurs|InTheirs {
+				om, ok1 := o.(Map[string, any])
+				tm, ok2 := t.(Map[string, any])
+				if ok1 && ok2 {
+					bm, _ := b.(Map[string, any])
+					return merge3Nested(subpath(key), bm, om, tm, resolve, conflicts), true
+				}
+			}
+			if resolve != nil {
+				return resolve(subpath(key), b, o, t, presence)
+			}
+			return nil, false
+		})
+	for i := cs.Range(); i.Next(); {
+		*conflicts = append(*conflicts, NestedConflict{Path: subpath(i.Key()), Conflict: i.Value()})
+	}
+	return merged
+}
@@ -0,0 +1,81 @@
+package frozen_test
+
+import (
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+)
+
+func TestMerge3(t *testing.T) {
+	t.Parallel()
+
+	var base mapIntInt
+	for i := 0; i < 100; i++ {
+		base = base.With(i, i)
+	}
+	ours := base.With(1, 10).Without(2).With(100, 100).With(5, 50).With(6, 60).Without(7)
+	theirs := base.With(3, 30).Without(4).With(101, 101).With(5, 50).With(6, 61).With(7, 70)
+
+	merged, conflicts := frozen.Merge3(base, ours, theirs, nil)
+	expected := base.
+		With(1, 10).Without(2).With(100, 100).
+		With(3, 30).Without(4).With(101, 101).
+		With(5, 50).With(6, 60).Without(7)
+	assertMapEqual(t, expected, merged)
+	if test.Equal(t, 2, conflicts.Count()) {
+		test.Equal(t,
+			frozen.Conflict[int]{Base: 6, Ours: 60, Theirs: 61, Presence: frozen.InBase | frozen.InOurs | frozen.InTheirs},
+			conflicts.MustGet(6))
+		test.Equal(t,
+			frozen.Conflict[int]{Base: 7, Theirs: 70, Presence: frozen.InBase | frozen.InTheirs},
+			conflicts.MustGet(7))
+	}
+
+	merged, conflicts = frozen.Merge3(base, ours, theirs,
+		func(_ int, b, o, t int, p frozen.Presence) (int, bool) {
+			if p == frozen.InBase|frozen.InOurs|frozen.InTheirs {
+				return o + t - b, true
+			}
+			return 0, false
+		})
+	assertMapEqual(t, expected.With(6, 115), merged)
+	test.Equal(t, 1, conflicts.Count())
+
+	merged, conflicts = frozen.Merge3(base, base, theirs, nil)
+	assertMapEqual(t, theirs, merged)
+	test.True(t, conflicts.IsEmpty())
+	merged, _ = frozen.Merge3(base, ours, base, nil)
+	assertMapEqual(t, ours, merged)
+}
+
+func TestMerge3Nested(t *testing.T) {
+	t.Parallel()
+
+	tree := func(kvs ...frozen.KeyValue[string, any]) frozen.Map[string, any] {
+		return frozen.NewMap(kvs...)
+	}
+	base := tree(
+		frozen.KV[string, any]("a", tree(frozen.KV[string, any]("x", 1), frozen.KV[string, any]("y", 2))),
+		frozen.KV[string, any]("b", 3),
+	)
+	ours := tree(
+		frozen.KV[string, any]("a", tree(frozen.KV[string, any]("x", 10), frozen.KV[string, any]("y", 2))),
+		frozen.KV[string, any]("b", 4),
+	)
+	theirs := tree(
+		frozen.KV[string, any]("a", tree(frozen.KV[string, any]("x", 1), frozen.KV[string, any]("y", 20))),
+		frozen.KV[string, any]("b", 5),
+	)
+
+	merged, conflicts := frozen.Merge3Nested(base, ours, theirs, nil)
+	expected := tree(
+		frozen.KV[string, any]("a", tree(frozen.KV[string, any]("x", 10), frozen.KV[string, any]("y", 20))),
+		frozen.KV[string, any]("b", 4),
+	)
+	assertMapEqual(t, expected, merged)
+	if test.Equal(t, 1, len(conflicts)) {
+		test.Equal(t, []string{"b"}, conflicts[0].Path)
+		test.Equal(t, 5, conflicts[0].Theirs)
+	}
+}
@@ -0,0 +1,282 @@
+package frozen
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/arr-ai/frozen/internal/pkg/depth"
+	"github.com/arr-ai/frozen/internal/pkg/value"
+)
+
+// ErrPatchMismatch is returned by ApplyStrict when the Map doesn't satisfy the
+// preconditions of a Patch.
+var ErrPatchMismatch = errors.New("patch precondition mismatch")
+
+// Patch is a serializable set of changes to a Map. Removes and Replaced record
+// the values that the Patch overwrites, so that it can be checked against the
+// Map it is applied to and can be inverted. The zero value is the empty Patch.
+type Patch[K any, V any] struct {
+	// Adds holds entries for keys that the Patch adds.
+	Adds Map[K, V]
+
+	// Removes holds the old entries for keys that the Patch removes.
+	Removes Map[K, V]
+
+	// Updates holds the new values for keys whose values change.
+	Updates Map[K, V]
+
+	// Replaced holds the old values for the keys in Updates.
+	Replaced Map[K, V]
+}
+
+// DiffPatch returns a Patch that turns m into n. Like Diff, it skips subtrees
+// that m and n share.
+func (m Map[K, V]) DiffPatch(n Map[K, V]) Patch[K, V] {
+	var adds, removes, updates, replaced MapBuilder[K, V]
+	m.tree.Diff(n.tree, func(a, b *mapEntry[K, V]) {
+		switch {
+		case b == nil:
+			removes.Put(a.Key, a.Value)
+		case a == nil:
+			adds.Put(b.Key, b.Value)
+		case !value.Equal(a.Value, b.Value):
+			updates.Put(b.Key, b.Value)
+			replaced.Put(a.Key, a.Value)
+		}
+	})
+	return Patch[K, V]{
+		Adds:     adds.Finish(),
+		Removes:  removes.Finish(),
+		Updates:  updates.Finish(),
+		Replaced: replaced.Finish(),
+	}
+}
+
+// Apply returns m with p applied. Preconditions are not checked: adds and
+// updates are written regardless of what m holds, and removes of absent keys
+// are ignored. The edits are applied as bulk tree operations rather than one
+// entry at a time.
+func (m Map[K, V]) Apply(p Patch[K, V]) Map[K, V] {
+	return m.removeKeys(p.Removes).Update(p.Adds.Update(p.Updates))
+}
+
+// ApplyStrict is like Apply, but first checks that m satisfies the
+// preconditions of p: keys added must be absent and keys removed or updated
+// must hold the values the Patch expects to replace. It returns m and an error
+// wrapping ErrPatchMismatch if they don't.
+func (m Map[K, V]) ApplyStrict(p Patch[K, V]) (Map[K, V], error) {
+	for i := p.Adds.Range(); i.Next(); {
+		if m.Has(i.Key()) {
+			return m, fmt.Errorf("%w: added key %v already present", ErrPatchMismatch, i.Key())
+		}
+	}
+	for _, expected := range []Map[K, V]{p.Removes, p.Replaced} {
+		for i := expected.Range(); i.Next(); {
+			if v, has := m.Get(i.Key()); !has || !value.Equal(v, i.Value()) {
+				return m, fmt.Errorf("%w: key %v doesn't have value %v", ErrPatchMismatch, i.Key(), i.Value())
+			}
+		}
+	}
+	return m.Apply(p), nil
+}
+
+// removeKeys returns m without any of the keys in n.
+func (m Map[K, V]) removeKeys(n Map[K, V]) Map[K, V] {
+	if n.IsEmpty() {
+		return m
+	}
+	return newMap(m.tree.Difference(depth.NewGauge(m.Count()), n.tree))
+}
+
+// IsEmpty returns true iff p makes no changes.
+func (p Patch[K, V]) IsEmpty() bool {
+	return p.Adds.IsEmpty() && p.Removes.IsEmpty() && p.Updates.IsEmpty()
+}
+
+// Count returns the number of keys p changes.
+func (p Patch[K, V]) Count() int {
+	return p.Adds.Count() + p.Removes.Count() + p.Updates.Count()
+}
+
+// Invert returns a Patch that undoes p.
+func (p Patch[K, V]) Invert() Patch[K, V] {
+	return Patch[K, V]{
+		Adds:     p.Removes,
+		Removes:  p.Adds,
+		Updates:  p.Replaced,
+		Replaced: p.Updates,
+	}
+}
+
+// Compose returns a Patch equivalent to applying p and then q.
+func (p Patch[K, V]) Compose(q Patch[K, V]) Patch[K, V] {
+	var adds, removes, updates, replaced MapBuilder[K, V]
+	compose := func(key K) {
+		// Each key's state before p comes from p if p changes it, otherwise
+		// from q. Likewise, its state after q comes from q, otherwise p.
+		from, hadFrom, changed := p.before(key)
+		if !changed {
+			from, hadFrom, _ = q.before(key)
+		}
+		to, hasTo, changed := q.after(key)
+		if !changed {
+			to, hasTo, _ = p.after(key)
+		}
+		switch {
+		case !hadFrom && hasTo:
+			adds.Put(key, to)
+		case hadFrom && !hasTo:
+			removes.Put(key, from)
+		case hadFrom && !value.Equal(from, to):
+			updates.Put(key, to)
+			replaced.Put(key, from)
+		}
+	}
+	p.rangeKeys(compose)
+	q.rangeKeys(func(key K) {
+		if _, _, changed := p.before(key); !changed {
+			compose(key)
+		}
+	})
+	return Patch[K, V]{
+		Adds:     adds.Finish(),
+		Removes:  removes.Finish(),
+		Updates:  updates.Finish(),
+		Replaced: replaced.Finish(),
+	}
+}
+
+// Equal returns true iff p and q make the same changes.
+func (p Patch[K, V]) Equal(q Patch[K, V]) bool {
+	return p.Adds.Equal(q.Adds) &&
+		p.Removes.Equal(q.Removes) &&
+		p.Updates.Equal(q.Updates) &&
+		p.Replaced.Equal(q.Replaced)
+}
+
+// String returns a string representation of the Patch.
+func (p Patch[K, V]) String() string {
+	return fmt.Sprintf("+%v -%v ~%v", p.Adds, p.Removes, p.Updates)
+}
+
+// before returns the value p expects key to have before it is applied, if any,
+// and whether p changes key at all.
+func (p Patch[K, V]) before(key K) (_ V, present, changed bool) {
+	if p.Adds.Has(key) {
+		changed = true
+		return
+	}
+	if v, has := p.Removes.Get(key); has {
+		return v, true, true
+	}
+	if v, has := p.Replaced.Get(key); has {
+		return v, true, true
+	}
+	return
+}
+
+// after returns the value key has after p is applied, if any, and whether p
+// changes key at all.
+func (p Patch[K, V]) after(key K) (_ V, present, changed bool) {
+	if v, has := p.Adds.Get(key); has {
+		return v, true, true
+	}
+	if p.Removes.Has(key) {
+		changed = true
+		return
+	}
+	if v, has := p.Updates.Get(key); has {
+		return v, true, true
+	}
+	return
+}
+
+func (p Patch[K, V]) rangeKeys(f func(key K)) {
+	for _, m := range []Map[K, V]{p.Adds, p.Removes, p.Updates} {
+		for i := m.Range(); i.Next(); {
+			f(i.Key())
+		}
+	}
+}
+
+// patchProxy is the serialized form of a Patch.
+type patchProxy[K any, V any] struct {
+	Adds     []KeyValue[K, V] `json:"adds,omitempty"`
+	Removes  []KeyValue[K, V] `json:"removes,omitempty"`
+	Updates  []KeyValue[K, V] `json:"updates,omitempty"`
+	Replaced []KeyValue[K, V] `json:"replaced,omitempty"`
+}
+
+func (p Patch[K, V]) proxy() patchProxy[K, V] {
+	entries := func(m Map[K, V]) []KeyValue[K, V] {
+		result := make([]KeyValue[K, V], 0, m.Count())
+		for i := m.Range(); i.Next(); {
+			result = append(result, KV(i.Entry()))
+		}
+		return result
+	}
+	return patchProxy[K, V]{
+		Adds:     entries(p.Adds),
+		Removes:  entries(p.Removes),
+		Updates:  entries(p.Updates),
+		Replaced: entries(p.Replaced),
+	}
+}
+
+func (x patchProxy[K, V]) patch() Patch[K, V] {
+	return Patch[K, V]{
+		Adds:     NewMap(x.Adds...),
+		Removes:  NewMap(x.Removes...),
+		Updates:  NewMap(x.Updates...),
+		Replaced: NewMap(x.Replaced...),
+	}
+}
+
+// MarshalJSON implements json.Marshaler. Each of the four maps is encoded as
+// an array of objects with "Key" and "Value" fields.
+func (p Patch[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.proxy())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *Patch[K, V]) UnmarshalJSON(data []byte) error {
+	var x patchProxy[K, V]
+	if err := json.Unmarshal(data, &x); err != nil {
+		return err
+	}
+	*p = x.patch()
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using encoding/gob. If K or
+// V are interface types, the concrete types stored in them must be registered
+// with gob.Register.
+func (p Patch[K, V]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(p.proxy()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *Patch[K, V]) UnmarshalBinary(data []byte) error {
+	var x patchProxy[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&x); err != nil {
+		return err
+	}
+	*p = x.patch()
+	return nil
+}
+
+// Ensure that Patch implements the encoding interfaces.
+var (
+	_ json.Marshaler             = Patch[int, int]{}
+	_ json.Unmarshaler           = &Patch[int, int]{}
+	_ encoding.BinaryMarshaler   = Patch[int, int]{}
+	_ encoding.BinaryUnmarshaler = &Patch[int, int]{}
+)
@@ -0,0 +1,97 @@
+package frozen_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+)
+
+func randomMapIntInt(r *rand.Rand, n int) mapIntInt {
+	var b frozen.MapBuilder[int, int]
+	for i := 0; i < n; i++ {
+		b.Put(r.Intn(2*n), r.Intn(3))
+	}
+	return b.Finish()
+}
+
+func TestPatch(t *testing.T) {
+	t.Parallel()
+
+	m := frozen.NewMap(frozen.KV(1, 1), frozen.KV(2, 2), frozen.KV(3, 3))
+	n := frozen.NewMap(frozen.KV(1, 1), frozen.KV(2, 20), frozen.KV(4, 4))
+	p := m.DiffPatch(n)
+	assertMapEqual(t, frozen.NewMap(frozen.KV(4, 4)), p.Adds)
+	assertMapEqual(t, frozen.NewMap(frozen.KV(3, 3)), p.Removes)
+	assertMapEqual(t, frozen.NewMap(frozen.KV(2, 20)), p.Updates)
+	assertMapEqual(t, frozen.NewMap(frozen.KV(2, 2)), p.Replaced)
+	test.Equal(t, 3, p.Count())
+
+	assertMapEqual(t, n, m.Apply(p))
+	assertMapEqual(t, m, n.Apply(p.Invert()))
+	test.True(t, m.DiffPatch(m).IsEmpty())
+	test.True(t, p.Compose(p.Invert()).IsEmpty())
+}
+
+func TestPatchApplyStrict(t *testing.T) {
+	t.Parallel()
+
+	m := frozen.NewMap(frozen.KV(1, 1), frozen.KV(2, 2))
+	p := m.DiffPatch(m.With(1, 10).Without(2).With(3, 3))
+
+	n, err := m.ApplyStrict(p)
+	if test.NoError(t, err) {
+		assertMapEqual(t, m.Apply(p), n)
+	}
+
+	for _, bad := range []mapIntInt{m.With(1, 5), m.With(2, 5), m.With(3, 5), m.Without(1)} {
+		n, err := bad.ApplyStrict(p)
+		test.True(t, errors.Is(err, frozen.ErrPatchMismatch), "%v", bad)
+		assertMapEqual(t, bad, n)
+	}
+}
+
+func TestPatchCompose(t *testing.T) {
+	t.Parallel()
+
+	rounds := 200
+	if testing.Short() {
+		rounds = 20
+	}
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	for round := 0; round < rounds; round++ {
+		a := randomMapIntInt(r, 20)
+		b := randomMapIntInt(r, 20)
+		c := randomMapIntInt(r, 20)
+		p := a.DiffPatch(b).Compose(b.DiffPatch(c))
+		assertMapEqual(t, c, a.Apply(p))
+		test.True(t, a.DiffPatch(c).Equal(p), "round=%d", round)
+		test.True(t, c.DiffPatch(a).Equal(p.Invert()), "round=%d", round)
+	}
+}
+
+func TestPatchEncoding(t *testing.T) {
+	t.Parallel()
+
+	m := frozen.NewMap(frozen.KV("a", 1), frozen.KV("b", 2))
+	p := m.DiffPatch(m.With("a", 10).Without("b").With("c", 3))
+
+	data, err := json.Marshal(p)
+	if test.NoError(t, err) {
+		var q frozen.Patch[string, int]
+		if test.NoError(t, json.Unmarshal(data, &q)) {
+			test.True(t, p.Equal(q), "%s", data)
+		}
+	}
+
+	data, err = p.MarshalBinary()
+	if test.NoError(t, err) {
+		var q frozen.Patch[string, int]
+		if test.NoError(t, q.UnmarshalBinary(data)) {
+			test.True(t, p.Equal(q))
+		}
+	}
+}
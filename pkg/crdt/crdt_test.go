@@ -0,0 +1,184 @@
+package crdt_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/pkg/crdt"
+)
+
+type lattice[S any] interface {
+	Merge(S) S
+	Equal(S) bool
+}
+
+// simulate runs three replicas through a random sequence of local updates and
+// pairwise merges, and returns their final states.
+func simulate[S lattice[S]](r *rand.Rand, steps int, update func(r *rand.Rand, replica string, step int, s S) S) [3]S {
+	var states [3]S
+	for step := 0; step < steps; step++ {
+		i := r.Intn(len(states))
+		if r.Intn(4) == 0 {
+			states[i] = states[i].Merge(states[r.Intn(len(states))])
+		} else {
+			states[i] = update(r, fmt.Sprintf("r%d", i), step, states[i])
+		}
+	}
+	return states
+}
+
+func assertLattice[S lattice[S]](t *testing.T, update func(r *rand.Rand, replica string, step int, s S) S) {
+	t.Helper()
+
+	rounds := 200
+	if testing.Short() {
+		rounds = 20
+	}
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	for round := 0; round < rounds; round++ {
+		s := simulate(r, 30, update)
+		a, b, c := s[0], s[1], s[2]
+		test.True(t, a.Merge(b).Equal(b.Merge(a)), "commutativity: round=%d", round)
+		test.True(t, a.Merge(b).Merge(c).Equal(a.Merge(b.Merge(c))), "associativity: round=%d", round)
+		test.True(t, a.Merge(a).Equal(a), "idempotence: round=%d", round)
+		test.True(t, a.Merge(b).Merge(a).Equal(a.Merge(b)), "absorption: round=%d", round)
+	}
+}
+
+func TestVClock(t *testing.T) {
+	t.Parallel()
+
+	var c crdt.VClock
+	a := c.Tick("a")
+	b := c.Tick("b")
+	test.True(t, c.LessOrEqual(a))
+	test.False(t, a.LessOrEqual(c))
+	test.True(t, a.Concurrent(b))
+	ab := a.Merge(b)
+	test.True(t, a.LessOrEqual(ab) && b.LessOrEqual(ab))
+	test.Equal(t, uint64(2), ab.Tick("a").Get("a"))
+	test.True(t, ab.Equal(b.Merge(a)))
+}
+
+func TestORSet(t *testing.T) {
+	t.Parallel()
+
+	var a crdt.ORSet[int]
+	a = a.Add("a", 1).Add("a", 2)
+	test.True(t, a.Has(1))
+	test.False(t, a.Has(3))
+
+	b := a.Remove(1)
+	test.False(t, b.Has(1))
+
+	// A concurrent add beats a remove.
+	c := a.Add("c", 1)
+	test.True(t, b.Merge(c).Has(1))
+	test.True(t, c.Merge(b).Has(1))
+
+	// A remove beats the adds it observed.
+	test.False(t, a.Merge(b).Has(1))
+	test.True(t, frozen.NewSet(2).Equal(a.Merge(b).Elements()))
+}
+
+func TestORSetMergeLaws(t *testing.T) {
+	t.Parallel()
+
+	assertLattice(t, func(r *rand.Rand, replica string, _ int, s crdt.ORSet[int]) crdt.ORSet[int] {
+		e := r.Intn(5)
+		if r.Intn(3) == 0 {
+			return s.Remove(e)
+		}
+		return s.Add(replica, e)
+	})
+}
+
+func TestLWWMap(t *testing.T) {
+	t.Parallel()
+
+	var m crdt.LWWMap[string, int]
+	m = m.Put("x", 1, crdt.Timestamp{Time: 1, Replica: "a"})
+	n := m.Put("x", 2, crdt.Timestamp{Time: 2, Replica: "b"})
+	o := m.Remove("x", crdt.Timestamp{Time: 3, Replica: "c"})
+
+	v, has := n.Merge(m).Get("x")
+	test.True(t, has)
+	test.Equal(t, 2, v)
+
+	_, has = n.Merge(o).Get("x")
+	test.False(t, has)
+
+	// Stale writes are ignored.
+	_, has = o.Put("x", 4, crdt.Timestamp{Time: 2, Replica: "d"}).Get("x")
+	test.False(t, has)
+	test.True(t, frozen.NewMap(frozen.KV("x", 5)).Equal(
+		o.Put("x", 5, crdt.Timestamp{Time: 4, Replica: "d"}).Map()))
+}
+
+// collider hashes every value the same.
+type collider struct{ n int }
+
+func (collider) Hash(seed uintptr) uintptr { return seed }
+
+func TestLWWMapTimestampTie(t *testing.T) {
+	t.Parallel()
+
+	ts := crdt.Timestamp{Time: 1, Replica: "a"}
+	var m crdt.LWWMap[string, collider]
+	a, b := m.Put("x", collider{1}, ts), m.Put("x", collider{2}, ts)
+	test.True(t, a.Merge(b).Equal(b.Merge(a)))
+	test.True(t, a.Put("x", collider{2}, ts).Equal(b.Put("x", collider{1}, ts)))
+
+	// A remove beats a put at the same Timestamp.
+	_, has := a.Merge(m.Remove("x", ts)).Get("x")
+	test.False(t, has)
+
+	// The winner doesn't depend on hashes, whose seeds differ between
+	// processes, so every replica picks the same one.
+	var s crdt.LWWMap[string, string]
+	for _, order := range [][2]string{{"apple", "banana"}, {"banana", "apple"}} {
+		v, has := s.Put("x", order[0], ts).Put("x", order[1], ts).Get("x")
+		test.True(t, has)
+		test.Equal(t, "banana", v)
+		v, _ = s.Put("x", order[0], ts).Merge(s.Put("x", order[1], ts)).Get("x")
+		test.Equal(t, "banana", v)
+	}
+}
+
+func TestLWWMapMergeLaws(t *testing.T) {
+	t.Parallel()
+
+	assertLattice(t, func(r *rand.Rand, replica string, step int, m crdt.LWWMap[int, int]) crdt.LWWMap[int, int] {
+		ts := crdt.Timestamp{Time: int64(step / 2), Replica: replica}
+		k := r.Intn(5)
+		if r.Intn(3) == 0 {
+			return m.Remove(k, ts)
+		}
+		return m.Put(k, r.Intn(10), ts)
+	})
+}
+
+func TestPNCounterMap(t *testing.T) {
+	t.Parallel()
+
+	var a, b crdt.PNCounterMap[string]
+	a = a.Add("a", "x", 5).Add("a", "x", -2)
+	b = b.Add("b", "x", 10).Add("b", "y", -1)
+	m := a.Merge(b)
+	test.Equal(t, int64(13), m.Get("x"))
+	test.Equal(t, int64(-1), m.Get("y"))
+	test.Equal(t, int64(0), m.Get("z"))
+	test.Equal(t, int64(13), m.Merge(a).Get("x"))
+	test.True(t, frozen.NewMap(frozen.KV("x", int64(13)), frozen.KV("y", int64(-1))).Equal(m.Values()))
+}
+
+func TestPNCounterMapMergeLaws(t *testing.T) {
+	t.Parallel()
+
+	assertLattice(t, func(r *rand.Rand, replica string, _ int, m crdt.PNCounterMap[int]) crdt.PNCounterMap[int] {
+		return m.Add(replica, r.Intn(3), int64(r.Intn(11)-5))
+	})
+}
@@ -0,0 +1,120 @@
+package crdt
+
+import (
+	"fmt"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/value"
+)
+
+// Timestamp orders writes to an LWWMap. Writes are ordered by Time, and ties
+// are broken by Replica. Each replica must give its writes distinct Times.
+type Timestamp struct {
+	Time    int64
+	Replica string
+}
+
+// Less returns true iff t orders before u.
+func (t Timestamp) Less(u Timestamp) bool {
+	if t.Time != u.Time {
+		return t.Time < u.Time
+	}
+	return t.Replica < u.Replica
+}
+
+type lwwEntry[V any] struct {
+	value   V
+	ts      Timestamp
+	deleted bool
+}
+
+func (e lwwEntry[V]) Equal(f lwwEntry[V]) bool {
+	return e.ts == f.ts && e.deleted == f.deleted && value.Equal(e.value, f.value)
+}
+
+// LWWMap is a last-writer-wins map. Each key holds the value of the write with
+// the latest Timestamp. Removals are kept as tombstones so that they can beat
+// older writes that arrive later. The zero value is the empty LWWMap.
+type LWWMap[K any, V any] struct {
+	entries frozen.Map[K, lwwEntry[V]]
+}
+
+// Put returns an LWWMap with key set to val at ts. Put has no effect if key was
+// already written at a later Timestamp. If it was written at the same
+// Timestamp, the value with the greater %#v representation wins, so that
+// replicas agree; it should therefore not depend on pointers.
+func (m LWWMap[K, V]) Put(key K, val V, ts Timestamp) LWWMap[K, V] {
+	return m.write(key, lwwEntry[V]{value: val, ts: ts})
+}
+
+// Remove returns an LWWMap with key removed at ts. Remove has no effect if key
+// was already written at a later Timestamp.
+func (m LWWMap[K, V]) Remove(key K, ts Timestamp) LWWMap[K, V] {
+	return m.write(key, lwwEntry[V]{ts: ts, deleted: true})
+}
+
+// Get returns the value of key and true, or false if key is absent.
+func (m LWWMap[K, V]) Get(key K) (_ V, _ bool) {
+	if e, has := m.entries.Get(key); has && !e.deleted {
+		return e.value, true
+	}
+	return
+}
+
+// Map returns a Map of all the live entries in m.
+func (m LWWMap[K, V]) Map() frozen.Map[K, V] {
+	var b frozen.MapBuilder[K, V]
+	for i := m.entries.Range(); i.Next(); {
+		if key, e := i.Entry(); !e.deleted {
+			b.Put(key, e.value)
+		}
+	}
+	return b.Finish()
+}
+
+// Merge returns the least upper bound of m and n.
+func (m LWWMap[K, V]) Merge(n LWWMap[K, V]) LWWMap[K, V] {
+	return LWWMap[K, V]{entries: m.entries.Merge(n.entries, resolveLWW[K, V])}
+}
+
+// Equal returns true iff m and n have identical states, including tombstones.
+func (m LWWMap[K, V]) Equal(n LWWMap[K, V]) bool {
+	return m.entries.Equal(n.entries)
+}
+
+// String returns a string representation of the live entries of m.
+func (m LWWMap[K, V]) String() string {
+	return m.Map().String()
+}
+
+func (m LWWMap[K, V]) write(key K, e lwwEntry[V]) LWWMap[K, V] {
+	if old, has := m.entries.Get(key); has {
+		e = resolveLWW(key, old, e)
+	}
+	return LWWMap[K, V]{entries: m.entries.With(key, e)}
+}
+
+// resolveLWW picks the later of two writes. Identical Timestamps only arise if
+// a replica reuses a Time, in which case a remove beats a put and puts are
+// ordered by their values' Go-syntax representations, so that every replica
+// picks the same winner regardless of the order of the arguments. Hashes
+// can't be used for this, since their seeds differ between processes.
+func resolveLWW[K, V any](_ K, a, b lwwEntry[V]) lwwEntry[V] {
+	switch {
+	case a.ts.Less(b.ts):
+		return b
+	case b.ts.Less(a.ts):
+		return a
+	case a.deleted || b.deleted:
+		if a.deleted {
+			return a
+		}
+		return b
+	case value.Equal(a.value, b.value):
+		return a
+	case fmt.Sprintf("%#v", b.value) > fmt.Sprintf("%#v", a.value):
+		return b
+	default:
+		return a
+	}
+}
@@ -0,0 +1,101 @@
+package crdt
+
+import (
+	"github.com/arr-ai/hash"
+
+	"github.com/arr-ai/frozen"
+)
+
+// dot uniquely identifies a single Add operation.
+type dot struct {
+	replica string
+	counter uint64
+}
+
+// Hash computes a hash value for d.
+func (d dot) Hash(seed uintptr) uintptr {
+	return hash.Uint64(d.counter, hash.String(d.replica, seed))
+}
+
+type dots = frozen.Set[dot]
+
+// ORSet is an observed-remove set. Each Add tags the element with a unique dot
+// and Remove tombstones only the dots it has observed. Thus, when an add and a
+// remove of the same element are concurrent, the add wins. The zero value is
+// the empty ORSet.
+//
+// Tombstones are never discarded, so an ORSet's state grows with every Add,
+// including those of elements that have since been removed. A tombstone could
+// only be dropped once every replica has seen it, since a replica that hasn't
+// would otherwise revive the dots it covers on the next Merge, and ORSet
+// doesn't track which replicas have seen what. Applications that churn through
+// many elements should periodically start afresh from Elements once replicas
+// have converged.
+type ORSet[T any] struct {
+	adds    frozen.Map[T, dots]
+	removes frozen.Map[T, dots]
+	clock   VClock
+}
+
+// Add returns an ORSet with elem added on behalf of replica.
+func (s ORSet[T]) Add(replica string, elem T) ORSet[T] {
+	s.clock = s.clock.Tick(replica)
+	d := dot{replica: replica, counter: s.clock.Get(replica)}
+	s.adds = s.adds.With(elem, s.adds.GetElse(elem, dots{}).With(d))
+	return s
+}
+
+// Remove returns an ORSet with elem removed. Only adds of elem that this
+// replica has observed are affected. The dots of those adds are kept as
+// tombstones, as described on ORSet.
+func (s ORSet[T]) Remove(elem T) ORSet[T] {
+	if added, has := s.adds.Get(elem); has {
+		s.removes = s.removes.With(elem, s.removes.GetElse(elem, dots{}).Union(added))
+	}
+	return s
+}
+
+// Has returns true iff elem is in the set.
+func (s ORSet[T]) Has(elem T) bool {
+	added, has := s.adds.Get(elem)
+	return has && !added.IsSubsetOf(s.removes.GetElse(elem, dots{}))
+}
+
+// Elements returns a Set of all elements in the ORSet.
+func (s ORSet[T]) Elements() frozen.Set[T] {
+	var b frozen.SetBuilder[T]
+	for i := s.adds.Range(); i.Next(); {
+		if elem := i.Key(); s.Has(elem) {
+			b.Add(elem)
+		}
+	}
+	return b.Finish()
+}
+
+// Clock returns the vector clock of the Adds observed by s.
+func (s ORSet[T]) Clock() VClock {
+	return s.clock
+}
+
+// Merge returns the least upper bound of s and t.
+func (s ORSet[T]) Merge(t ORSet[T]) ORSet[T] {
+	return ORSet[T]{
+		adds:    s.adds.Merge(t.adds, unionDots[T]),
+		removes: s.removes.Merge(t.removes, unionDots[T]),
+		clock:   s.clock.Merge(t.clock),
+	}
+}
+
+// Equal returns true iff s and t have identical states, including metadata.
+func (s ORSet[T]) Equal(t ORSet[T]) bool {
+	return s.adds.Equal(t.adds) && s.removes.Equal(t.removes) && s.clock.Equal(t.clock)
+}
+
+// String returns a string representation of the elements of s.
+func (s ORSet[T]) String() string {
+	return s.Elements().String()
+}
+
+func unionDots[T any](_ T, a, b dots) dots {
+	return a.Union(b)
+}
@@ -0,0 +1,82 @@
+package crdt
+
+import (
+	"github.com/arr-ai/frozen"
+)
+
+type pnCounter struct {
+	p, n frozen.Map[string, uint64]
+}
+
+func (c pnCounter) Equal(d pnCounter) bool {
+	return c.p.Equal(d.p) && c.n.Equal(d.n)
+}
+
+func (c pnCounter) value() int64 {
+	var total int64
+	for i := c.p.Range(); i.Next(); {
+		total += int64(i.Value())
+	}
+	for i := c.n.Range(); i.Next(); {
+		total -= int64(i.Value())
+	}
+	return total
+}
+
+// PNCounterMap maps keys to counters that any replica can increment or
+// decrement. Each counter tracks increments and decrements separately for
+// each replica, and merging keeps the larger tally for each. The zero value
+// maps every key to zero.
+type PNCounterMap[K any] struct {
+	counters frozen.Map[K, pnCounter]
+}
+
+// Add returns a PNCounterMap with delta added to the counter for key on behalf
+// of replica. delta may be negative.
+func (m PNCounterMap[K]) Add(replica string, key K, delta int64) PNCounterMap[K] {
+	c := m.counters.GetElse(key, pnCounter{})
+	switch {
+	case delta > 0:
+		c.p = c.p.With(replica, c.p.GetElse(replica, 0)+uint64(delta))
+	case delta < 0:
+		c.n = c.n.With(replica, c.n.GetElse(replica, 0)+uint64(-delta))
+	default:
+		return m
+	}
+	return PNCounterMap[K]{counters: m.counters.With(key, c)}
+}
+
+// Get returns the value of the counter for key.
+func (m PNCounterMap[K]) Get(key K) int64 {
+	if c, has := m.counters.Get(key); has {
+		return c.value()
+	}
+	return 0
+}
+
+// Values returns a Map of the values of all counters that have been updated.
+func (m PNCounterMap[K]) Values() frozen.Map[K, int64] {
+	return frozen.MapMap(m.counters, func(_ K, c pnCounter) int64 {
+		return c.value()
+	})
+}
+
+// Merge returns the least upper bound of m and n.
+func (m PNCounterMap[K]) Merge(n PNCounterMap[K]) PNCounterMap[K] {
+	return PNCounterMap[K]{
+		counters: m.counters.Merge(n.counters, func(_ K, a, b pnCounter) pnCounter {
+			return pnCounter{p: maxMerge(a.p, b.p), n: maxMerge(a.n, b.n)}
+		}),
+	}
+}
+
+// Equal returns true iff m and n have identical states, including per-replica
+// tallies.
+func (m PNCounterMap[K]) Equal(n PNCounterMap[K]) bool {
+	return m.counters.Equal(n.counters)
+}
+
+// String returns a string representation of the values of m.
+func (m PNCounterMap[K]) String() string {
+	return m.Values().String()
+}
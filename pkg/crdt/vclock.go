@@ -0,0 +1,76 @@
+// Package crdt provides conflict-free replicated data types built on frozen
+// Maps and Sets. Every type is a state-based CRDT: replicas apply local updates
+// independently and converge by merging each other's states in any order, any
+// number of times.
+package crdt
+
+import (
+	"fmt"
+
+	"github.com/arr-ai/hash"
+
+	"github.com/arr-ai/frozen"
+)
+
+// VClock is a vector clock that maps replica IDs to logical times. The zero
+// value is the clock at which no replica has ticked.
+type VClock struct {
+	times frozen.Map[string, uint64]
+}
+
+// Get returns the logical time of replica.
+func (c VClock) Get(replica string) uint64 {
+	return c.times.GetElse(replica, 0)
+}
+
+// Tick returns a VClock with the time of replica advanced by one.
+func (c VClock) Tick(replica string) VClock {
+	return VClock{times: c.times.With(replica, c.Get(replica)+1)}
+}
+
+// Merge returns the pointwise maximum of c and d.
+func (c VClock) Merge(d VClock) VClock {
+	return VClock{times: maxMerge(c.times, d.times)}
+}
+
+// LessOrEqual returns true iff no replica has a later time in c than in d.
+// That is, every event known to c is also known to d.
+func (c VClock) LessOrEqual(d VClock) bool {
+	for i := c.times.Range(); i.Next(); {
+		if replica, t := i.Entry(); t > d.Get(replica) {
+			return false
+		}
+	}
+	return true
+}
+
+// Concurrent returns true iff neither c nor d happened before the other.
+func (c VClock) Concurrent(d VClock) bool {
+	return !c.LessOrEqual(d) && !d.LessOrEqual(c)
+}
+
+// Equal returns true iff c and d record the same times for all replicas.
+func (c VClock) Equal(d VClock) bool {
+	return c.times.Equal(d.times)
+}
+
+// Hash computes a hash value for c.
+func (c VClock) Hash(seed uintptr) uintptr {
+	return hash.Any(c.times, seed)
+}
+
+// String returns a string representation of c.
+func (c VClock) String() string {
+	return fmt.Sprintf("%v", c.times)
+}
+
+// maxMerge merges two per-replica counters, keeping the larger count for each
+// replica.
+func maxMerge(a, b frozen.Map[string, uint64]) frozen.Map[string, uint64] {
+	return a.Merge(b, func(_ string, x, y uint64) uint64 {
+		if x > y {
+			return x
+		}
+		return y
+	})
+}
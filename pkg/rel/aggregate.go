@@ -0,0 +1,357 @@
+package rel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/arr-ai/frozen"
+)
+
+// ErrInvalidValue is returned when an aggregator can't handle an attribute's
+// value, such as when summing strings.
+var ErrInvalidValue = errors.New("invalid value")
+
+// Aggregator computes a summary value over the tuples in a group. To allow
+// groups to be summarized in parallel, it works with partial states: Init
+// returns the state for a single tuple, Merge combines the states of two
+// disjoint sets of tuples, and Result turns the state for a whole group into
+// its summary value. Merge must be commutative and associative, and all
+// methods must be safe to call concurrently.
+type Aggregator interface {
+	Init(t Tuple) (any, error)
+	Merge(a, b any) (any, error)
+	Result(state any) any
+}
+
+// NewAggregator returns an Aggregator with partial states of type S built from
+// the given functions.
+func NewAggregator[S any](
+	init func(t Tuple) (S, error),
+	merge func(a, b S) (S, error),
+	result func(state S) any,
+) Aggregator {
+	return aggregator[S]{init: init, merge: merge, result: result}
+}
+
+type aggregator[S any] struct {
+	init   func(t Tuple) (S, error)
+	merge  func(a, b S) (S, error)
+	result func(state S) any
+}
+
+func (a aggregator[S]) Init(t Tuple) (any, error) {
+	return a.init(t)
+}
+
+func (a aggregator[S]) Merge(x, y any) (any, error) {
+	return a.merge(x.(S), y.(S))
+}
+
+func (a aggregator[S]) Result(state any) any {
+	return a.result(state.(S))
+}
+
+// Count returns an Aggregator that counts the tuples in a group.
+func Count() Aggregator {
+	return NewAggregator(
+		func(Tuple) (int, error) { return 1, nil },
+		func(a, b int) (int, error) { return a + b, nil },
+		func(n int) any { return n },
+	)
+}
+
+// Sum returns an Aggregator that sums the values of attr, which must be
+// numbers. The sum is an int if every value is an integer and a float64
+// otherwise.
+func Sum(attr string) Aggregator {
+	return NewAggregator(
+		func(t Tuple) (number, error) { return numberAttr("Sum", t, attr) },
+		func(a, b number) (number, error) { return a.add(b), nil },
+		func(n number) any { return n.value() },
+	)
+}
+
+// Avg returns an Aggregator that computes the mean of the values of attr,
+// which must be numbers, as a float64.
+func Avg(attr string) Aggregator {
+	type avg struct {
+		sum number
+		n   int
+	}
+	return NewAggregator(
+		func(t Tuple) (avg, error) {
+			n, err := numberAttr("Avg", t, attr)
+			return avg{sum: n, n: 1}, err
+		},
+		func(a, b avg) (avg, error) { return avg{sum: a.sum.add(b.sum), n: a.n + b.n}, nil },
+		func(a avg) any { return a.sum.float() / float64(a.n) },
+	)
+}
+
+// Min returns an Aggregator that finds the least value of attr. Values must be
+// all numbers or all strings.
+func Min(attr string) Aggregator {
+	return extremum("Min", attr, func(a, b any) (any, error) {
+		less, err := lessValue("Min", b, a)
+		if less {
+			return b, err
+		}
+		return a, err
+	})
+}
+
+// Max returns an Aggregator that finds the greatest value of attr. Values must
+// be all numbers or all strings.
+func Max(attr string) Aggregator {
+	return extremum("Max", attr, func(a, b any) (any, error) {
+		less, err := lessValue("Max", a, b)
+		if less {
+			return b, err
+		}
+		return a, err
+	})
+}
+
+func extremum(op, attr string, merge func(a, b any) (any, error)) Aggregator {
+	return NewAggregator(
+		func(t Tuple) (any, error) {
+			v, err := getAttr(op, t, attr)
+			if err != nil {
+				return nil, err
+			}
+			if _, is := toNumber(v); !is {
+				if _, is := v.(string); !is {
+					return nil, fmt.Errorf("%s: %w: %v is not a number or string", op, ErrInvalidValue, v)
+				}
+			}
+			return v, nil
+		},
+		merge,
+		func(v any) any { return v },
+	)
+}
+
+// CollectSet returns an Aggregator that collects the values of attr into a
+// frozen.Set[any].
+func CollectSet(attr string) Aggregator {
+	return NewAggregator(
+		func(t Tuple) (frozen.Set[any], error) {
+			v, err := getAttr("CollectSet", t, attr)
+			return frozen.NewSet(v), err
+		},
+		func(a, b frozen.Set[any]) (frozen.Set[any], error) { return a.Union(b), nil },
+		func(s frozen.Set[any]) any { return s },
+	)
+}
+
+// Summarize groups the tuples of r by the attributes in by and computes an
+// attribute for each entry in aggs by applying its Aggregator to each group.
+// The result has one tuple per group, with the attributes in by and the keys
+// of aggs. If r is empty, there are no groups, so the result is empty.
+// Each group is aggregated in parallel over the branches of its trie.
+//
+// It returns an error if r doesn't have all the attributes in by, a key of aggs
+// is also in by, or an Aggregator fails.
+func Summarize(r Relation, by []string, aggs map[string]Aggregator) (Relation, error) {
+	byHeading := NewHeading(by...)
+	if !byHeading.IsSubsetOf(r.heading) {
+		return Relation{}, fmt.Errorf("Summarize: %w: %v not in %v",
+			ErrUnknownAttribute, byHeading.Difference(r.heading), r.heading)
+	}
+	names := make([]string, 0, len(aggs))
+	for name := range aggs {
+		if byHeading.Has(name) {
+			return Relation{}, fmt.Errorf("Summarize: %w: %q", ErrDuplicateAttribute, name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	heading := byHeading.Union(NewHeading(names...))
+
+	byAttrs := byHeading.Elements()
+	groups := frozen.SetGroupBy(r.tuples, func(t Tuple) Tuple {
+		return t.Project(byAttrs...)
+	})
+	var b frozen.SetBuilder[Tuple]
+	for i := groups.Range(); i.Next(); {
+		partials := frozen.SetMap(i.Value(), func(t Tuple) summary {
+			s := summary{src: t, states: make([]any, 0, len(names))}
+			for _, name := range names {
+				state, err := aggs[name].Init(t)
+				if err != nil {
+					s.err = err
+					break
+				}
+				s.states = append(s.states, state)
+			}
+			return s
+		})
+		total, _ := partials.Reduce2(func(a, b summary) summary {
+			return a.merge(b, names, aggs)
+		})
+		if total.err != nil {
+			return Relation{}, fmt.Errorf("Summarize: %q: %w", total.errName(names), total.err)
+		}
+		t := i.Key()
+		for j, name := range names {
+			t = t.With(name, aggs[name].Result(total.states[j]))
+		}
+		b.Add(t)
+	}
+	return Relation{heading: heading, tuples: b.Finish()}, nil
+}
+
+// summary holds the partial states of each aggregator for some of the tuples
+// in a group. src is one of those tuples, which keeps summaries distinct in
+// the Set that Summarize reduces.
+type summary struct {
+	src    Tuple
+	states []any
+	err    error
+}
+
+// Hash computes a hash value for s.
+func (s summary) Hash(seed uintptr) uintptr {
+	return s.src.Hash(seed)
+}
+
+// Equal returns true iff s and t came from the same tuple.
+func (s summary) Equal(t summary) bool {
+	return s.src.Equal(t.src)
+}
+
+func (s summary) merge(t summary, names []string, aggs map[string]Aggregator) summary {
+	if s.err != nil {
+		return s
+	}
+	if t.err != nil {
+		return t
+	}
+	states := make([]any, 0, len(names))
+	for j, name := range names {
+		state, err := aggs[name].Merge(s.states[j], t.states[j])
+		if err != nil {
+			return summary{src: s.src, states: states, err: err}
+		}
+		states = append(states, state)
+	}
+	return summary{src: s.src, states: states}
+}
+
+// errName returns the name of the aggregator that failed, which is the first
+// one without a state.
+func (s summary) errName(names []string) string {
+	return names[len(s.states)]
+}
+
+func getAttr(op string, t Tuple, attr string) (any, error) {
+	v, has := t.Get(attr)
+	if !has {
+		return nil, fmt.Errorf("%s: %w: %q", op, ErrUnknownAttribute, attr)
+	}
+	return v, nil
+}
+
+func numberAttr(op string, t Tuple, attr string) (number, error) {
+	v, err := getAttr(op, t, attr)
+	if err != nil {
+		return number{}, err
+	}
+	n, is := toNumber(v)
+	if !is {
+		return number{}, fmt.Errorf("%s: %w: %v is not a number", op, ErrInvalidValue, v)
+	}
+	return n, nil
+}
+
+// lessValue returns true iff a < b, where a and b are both numbers or both
+// strings.
+func lessValue(op string, a, b any) (bool, error) {
+	if m, is := toNumber(a); is {
+		if n, is := toNumber(b); is {
+			if m.isFloat || n.isFloat {
+				return m.float() < n.float(), nil
+			}
+			return m.i < n.i, nil
+		}
+	} else if s, is := a.(string); is {
+		if t, is := b.(string); is {
+			return s < t, nil
+		}
+	}
+	return false, fmt.Errorf("%s: %w: can't compare %v and %v", op, ErrInvalidValue, a, b)
+}
+
+// number is an integer or floating-point value.
+type number struct {
+	i       int64
+	f       float64
+	isFloat bool
+}
+
+func toNumber(v any) (number, bool) {
+	switch v := v.(type) {
+	case int:
+		return number{i: int64(v)}, true
+	case int8:
+		return number{i: int64(v)}, true
+	case int16:
+		return number{i: int64(v)}, true
+	case int32:
+		return number{i: int64(v)}, true
+	case int64:
+		return number{i: v}, true
+	case uint:
+		return fromUint64(uint64(v)), true
+	case uint8:
+		return number{i: int64(v)}, true
+	case uint16:
+		return number{i: int64(v)}, true
+	case uint32:
+		return number{i: int64(v)}, true
+	case uint64:
+		return fromUint64(v), true
+	case float32:
+		return number{f: float64(v), isFloat: true}, true
+	case float64:
+		return number{f: v, isFloat: true}, true
+	}
+	return number{}, false
+}
+
+// fromUint64 returns v as a number, falling back to a float if v doesn't fit
+// in an int64.
+func fromUint64(v uint64) number {
+	if v > math.MaxInt64 {
+		return number{f: float64(v), isFloat: true}
+	}
+	return number{i: int64(v)}
+}
+
+// add returns n + m, falling back to a float if the sum overflows an int64.
+func (n number) add(m number) number {
+	if !n.isFloat && !m.isFloat {
+		// The sum overflowed iff its sign differs from both operands'.
+		if sum := n.i + m.i; (n.i^sum)&(m.i^sum) >= 0 {
+			return number{i: sum}
+		}
+	}
+	return number{f: n.float() + m.float(), isFloat: true}
+}
+
+func (n number) float() float64 {
+	if n.isFloat {
+		return n.f
+	}
+	return float64(n.i)
+}
+
+// value returns n as an int if it fits in one, and otherwise as a float64.
+func (n number) value() any {
+	if !n.isFloat && math.MinInt <= n.i && n.i <= math.MaxInt {
+		return int(n.i)
+	}
+	return n.float()
+}
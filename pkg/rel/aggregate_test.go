@@ -0,0 +1,143 @@
+package rel_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/pkg/rel"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	must := mustFor(t)
+	r := rel.MustNew([]string{"dept", "name", "salary"},
+		[]any{"eng", "alice", 100},
+		[]any{"eng", "bob", 80},
+		[]any{"eng", "carol", 120},
+		[]any{"ops", "dave", 90},
+		[]any{"ops", "erin", 70.5},
+	)
+	s := must(rel.Summarize(r, []string{"dept"}, map[string]rel.Aggregator{
+		"n":     rel.Count(),
+		"total": rel.Sum("salary"),
+		"mean":  rel.Avg("salary"),
+		"least": rel.Min("salary"),
+		"most":  rel.Max("name"),
+		"names": rel.CollectSet("name"),
+	}))
+	assertRelationEqual(t,
+		rel.MustNew([]string{"dept", "n", "total", "mean", "least", "most", "names"},
+			[]any{"eng", 3, 300, 100.0, 80, "carol", frozen.NewSet[any]("alice", "bob", "carol")},
+			[]any{"ops", 2, 160.5, 80.25, 70.5, "erin", frozen.NewSet[any]("dave", "erin")},
+		),
+		s)
+
+	// No grouping attributes summarizes the whole relation.
+	assertRelationEqual(t,
+		rel.MustNew([]string{"n"}, []any{5}),
+		must(rel.Summarize(r, nil, map[string]rel.Aggregator{"n": rel.Count()})))
+	assertRelationEqual(t,
+		rel.MustNew([]string{"dept", "n"}),
+		must(rel.Summarize(rel.MustNew([]string{"dept"}), []string{"dept"}, map[string]rel.Aggregator{"n": rel.Count()})))
+}
+
+func TestSummarizeLarge(t *testing.T) {
+	t.Parallel()
+
+	must := mustFor(t)
+	rnd := rand.New(rand.NewSource(0)) //nolint:gosec
+	n := 20000
+	if testing.Short() {
+		n = 2000
+	}
+	b := rel.NewRelationBuilder(rel.NewHeading("id", "k", "v"))
+	counts := map[int]int{}
+	sums := map[int]int{}
+	for id := 0; id < n; id++ {
+		k, v := rnd.Intn(5), rnd.Intn(1000)
+		counts[k]++
+		sums[k] += v
+		test.RequireNoError(t, b.Add(rel.NewTuple(
+			frozen.KV[string, any]("id", id),
+			frozen.KV[string, any]("k", k),
+			frozen.KV[string, any]("v", v),
+		)))
+	}
+	s := must(rel.Summarize(b.Finish(), []string{"k"}, map[string]rel.Aggregator{
+		"n":   rel.Count(),
+		"sum": rel.Sum("v"),
+	}))
+	test.Equal(t, len(counts), s.Count())
+	for i := s.Range(); i.Next(); {
+		k := i.Value().MustGet("k").(int)
+		test.Equal(t, counts[k], i.Value().MustGet("n"))
+		test.Equal(t, sums[k], i.Value().MustGet("sum"))
+	}
+}
+
+func TestSummarizeUnsigned(t *testing.T) {
+	t.Parallel()
+
+	must := mustFor(t)
+	small := rel.MustNew([]string{"v"}, []any{uint64(1)}, []any{uint(2)})
+	assertRelationEqual(t,
+		rel.MustNew([]string{"s"}, []any{3}),
+		must(rel.Summarize(small, nil, map[string]rel.Aggregator{"s": rel.Sum("v")})))
+
+	// Values too large for an int64 are summed as floats rather than wrapping.
+	large := rel.MustNew([]string{"v"}, []any{uint64(1 << 63)}, []any{uint(1)})
+	assertRelationEqual(t,
+		rel.MustNew([]string{"s"}, []any{float64(1 << 63)}),
+		must(rel.Summarize(large, nil, map[string]rel.Aggregator{"s": rel.Sum("v")})))
+
+	// Sums that overflow an int64 are also computed as floats.
+	overflow := rel.MustNew([]string{"k", "v"},
+		[]any{1, uint64(1 << 62)}, []any{2, uint64(1 << 62)}, []any{3, uint64(1 << 62)})
+	assertRelationEqual(t,
+		rel.MustNew([]string{"s"}, []any{float64(3 << 62)}),
+		must(rel.Summarize(overflow, nil, map[string]rel.Aggregator{"s": rel.Sum("v")})))
+	negative := rel.MustNew([]string{"v"}, []any{int64(math.MinInt64)}, []any{-1})
+	assertRelationEqual(t,
+		rel.MustNew([]string{"s"}, []any{float64(math.MinInt64)}),
+		must(rel.Summarize(negative, nil, map[string]rel.Aggregator{"s": rel.Sum("v")})))
+}
+
+func TestSummarizeCustom(t *testing.T) {
+	t.Parallel()
+
+	must := mustFor(t)
+	product := rel.NewAggregator(
+		func(t rel.Tuple) (int, error) { return t.MustGet("x").(int), nil },
+		func(a, b int) (int, error) { return a * b, nil },
+		func(p int) any { return p },
+	)
+	r := rel.MustNew([]string{"x"}, []any{2}, []any{3}, []any{7})
+	assertRelationEqual(t,
+		rel.MustNew([]string{"p"}, []any{42}),
+		must(rel.Summarize(r, nil, map[string]rel.Aggregator{"p": product})))
+}
+
+func TestSummarizeErrors(t *testing.T) {
+	t.Parallel()
+
+	r := rel.MustNew([]string{"a", "b"}, []any{1, "x"}, []any{2, 3})
+	_, err := rel.Summarize(r, []string{"c"}, map[string]rel.Aggregator{"n": rel.Count()})
+	test.True(t, errors.Is(err, rel.ErrUnknownAttribute))
+	_, err = rel.Summarize(r, []string{"a"}, map[string]rel.Aggregator{"a": rel.Count()})
+	test.True(t, errors.Is(err, rel.ErrDuplicateAttribute))
+	_, err = rel.Summarize(r, nil, map[string]rel.Aggregator{"s": rel.Sum("c")})
+	test.True(t, errors.Is(err, rel.ErrUnknownAttribute))
+	_, err = rel.Summarize(r, nil, map[string]rel.Aggregator{"s": rel.Sum("b")})
+	test.True(t, errors.Is(err, rel.ErrInvalidValue))
+	_, err = rel.Summarize(r, nil, map[string]rel.Aggregator{"m": rel.Max("b")})
+	test.True(t, errors.Is(err, rel.ErrInvalidValue))
+
+	// Values within a group can be summarized as long as they're comparable.
+	_, err = rel.Summarize(r, []string{"a"}, map[string]rel.Aggregator{"m": rel.Max("b")})
+	test.NoError(t, err)
+}
@@ -0,0 +1,160 @@
+package rel
+
+import (
+	"fmt"
+
+	"github.com/arr-ai/frozen"
+)
+
+// Restrict returns the tuples in r that satisfy pred.
+func Restrict(r Relation, pred func(t Tuple) bool) Relation {
+	return Relation{heading: r.heading, tuples: r.tuples.Where(pred)}
+}
+
+// Rename returns r with attributes renamed according to renames, which maps
+// old names to new ones. Attributes not in renames keep their names. It
+// returns an error if r lacks an attribute in renames or if two attributes
+// would end up with the same name.
+func Rename(r Relation, renames map[string]string) (Relation, error) {
+	if len(renames) == 0 {
+		return r, nil
+	}
+	for from := range renames {
+		if !r.heading.Has(from) {
+			return Relation{}, fmt.Errorf("Rename: %w: %q not in %v", ErrUnknownAttribute, from, r.heading)
+		}
+	}
+	var attrs frozen.SetBuilder[string]
+	for i := r.heading.attrs.Range(); i.Next(); {
+		attr := i.Value()
+		if to, has := renames[attr]; has {
+			attr = to
+		}
+		if attrs.Has(attr) {
+			return Relation{}, fmt.Errorf("Rename: %w: %q", ErrDuplicateAttribute, attr)
+		}
+		attrs.Add(attr)
+	}
+	return Relation{
+		heading: Heading{attrs: attrs.Finish()},
+		tuples: frozen.SetMap(r.tuples, func(t Tuple) Tuple {
+			var b frozen.MapBuilder[string, any]
+			for i := t.Range(); i.Next(); {
+				attr := i.Key()
+				if to, has := renames[attr]; has {
+					attr = to
+				}
+				b.Put(attr, i.Value())
+			}
+			return b.Finish()
+		}),
+	}, nil
+}
+
+// Extend returns r with a new attribute, attr, added to each tuple and set to
+// the result of calling f on it. It returns an error if r already has attr.
+func Extend(r Relation, attr string, f func(t Tuple) any) (Relation, error) {
+	if r.heading.Has(attr) {
+		return Relation{}, fmt.Errorf("Extend: %w: %q", ErrDuplicateAttribute, attr)
+	}
+	return Relation{
+		heading: r.heading.Union(NewHeading(attr)),
+		tuples: frozen.SetMap(r.tuples, func(t Tuple) Tuple {
+			return t.With(attr, f(t))
+		}),
+	}, nil
+}
+
+// SemiJoin returns the tuples in s that match at least one tuple in t on the
+// attributes they have in common. It is equivalent to, but cheaper than,
+// projecting Join(s, t) onto the heading of s.
+func SemiJoin(s, t Relation) Relation {
+	return semiJoin(s, t, true)
+}
+
+// AntiJoin returns the tuples in s that match no tuple in t on the attributes
+// they have in common. It is the complement of SemiJoin within s.
+func AntiJoin(s, t Relation) Relation {
+	return semiJoin(s, t, false)
+}
+
+func semiJoin(s, t Relation, match bool) Relation {
+	if t.IsEmpty() {
+		if match {
+			return Relation{heading: s.heading}
+		}
+		return s
+	}
+	commonAttrs := s.heading.Intersection(t.heading).Elements()
+	projectCommon := func(t Tuple) Tuple {
+		return t.Project(commonAttrs...)
+	}
+	matchable := func(t Tuple) bool {
+		return hasAttrs(t, commonAttrs)
+	}
+	tGroup := frozen.SetGroupBy(t.tuples.Where(matchable), projectCommon)
+	return Restrict(s, func(el Tuple) bool {
+		return (matchable(el) && tGroup.Has(projectCommon(el))) == match
+	})
+}
+
+// Divide returns the largest relation q with the attributes of s that aren't
+// in t, such that CartesianProduct(q, t) is a subset of s. That is, it returns
+// the tuples that s pairs with every tuple in t. It returns an error if t has
+// attributes that s doesn't.
+func Divide(s, t Relation) (Relation, error) {
+	if !t.heading.IsSubsetOf(s.heading) {
+		return Relation{}, fmt.Errorf("Divide: %w: divisor %v not a subset of %v", ErrHeadingMismatch, t.heading, s.heading)
+	}
+	heading := s.heading.Difference(t.heading)
+	if t.IsEmpty() {
+		return project(s, heading), nil
+	}
+	qAttrs := heading.Elements()
+	groups := frozen.SetGroupBy(s.tuples, func(t Tuple) Tuple {
+		return t.Project(qAttrs...)
+	})
+	tAttrs := t.heading.Elements()
+	return Relation{
+		heading: heading,
+		tuples: groups.Where(func(_ Tuple, group frozen.Set[Tuple]) bool {
+			return t.tuples.IsSubsetOf(frozen.SetMap(group, func(t Tuple) Tuple {
+				return t.Project(tAttrs...)
+			}))
+		}).Keys(),
+	}, nil
+}
+
+// Union returns the tuples in either s or t. It returns an error if s and t
+// have different headings.
+func Union(s, t Relation) (Relation, error) {
+	if err := checkHeadings("Union", s, t); err != nil {
+		return Relation{}, err
+	}
+	return Relation{heading: s.heading, tuples: s.tuples.Union(t.tuples)}, nil
+}
+
+// Intersection returns the tuples in both s and t. It returns an error if s and
+// t have different headings.
+func Intersection(s, t Relation) (Relation, error) {
+	if err := checkHeadings("Intersection", s, t); err != nil {
+		return Relation{}, err
+	}
+	return Relation{heading: s.heading, tuples: s.tuples.Intersection(t.tuples)}, nil
+}
+
+// Difference returns the tuples in s that aren't in t. It returns an error if s
+// and t have different headings.
+func Difference(s, t Relation) (Relation, error) {
+	if err := checkHeadings("Difference", s, t); err != nil {
+		return Relation{}, err
+	}
+	return Relation{heading: s.heading, tuples: s.tuples.Difference(t.tuples)}, nil
+}
+
+func checkHeadings(op string, s, t Relation) error {
+	if !s.heading.Equal(t.heading) {
+		return fmt.Errorf("%s: %w: %v and %v", op, ErrHeadingMismatch, s.heading, t.heading)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package rel_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/pkg/rel"
+)
+
+// mustFor returns a function that returns a Relation result, failing t if it
+// comes with an error.
+func mustFor(t *testing.T) func(r rel.Relation, err error) rel.Relation {
+	t.Helper()
+
+	return func(r rel.Relation, err error) rel.Relation {
+		t.Helper()
+
+		test.RequireNoError(t, err)
+		return r
+	}
+}
+
+// randomRelation returns a relation with the given heading and up to maxRows
+// tuples with values in [0, 3).
+func randomRelation(r *rand.Rand, maxRows int, header ...string) rel.Relation {
+	rows := make([][]any, 0, maxRows)
+	for n := r.Intn(maxRows + 1); n > 0; n-- {
+		row := make([]any, 0, len(header))
+		for range header {
+			row = append(row, r.Intn(3))
+		}
+		rows = append(rows, row)
+	}
+	return rel.MustNew(header, rows...)
+}
+
+// forEachRandomPair calls f with random relations over {x, y} and {y, z}.
+func forEachRandomPair(t *testing.T, f func(s, u rel.Relation) bool) {
+	t.Helper()
+
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	rounds := 200
+	if testing.Short() {
+		rounds = 20
+	}
+	for i := 0; i < rounds; i++ {
+		s := randomRelation(r, 8, "x", "y")
+		u := randomRelation(r, 8, "y", "z")
+		if !f(s, u) {
+			t.Logf("s=%v u=%v", s, u)
+			break
+		}
+	}
+}
+
+func TestRestrict(t *testing.T) {
+	t.Parallel()
+
+	must := mustFor(t)
+	even := func(t rel.Tuple) bool { return t.MustGet("x").(int)%2 == 0 }
+	odd := func(t rel.Tuple) bool { return !even(t) }
+	forEachRandomPair(t, func(s, _ rel.Relation) bool {
+		a, b := rel.Restrict(s, even), rel.Restrict(s, odd)
+		return assertRelationEqual(t, s, must(rel.Union(a, b))) &&
+			test.True(t, must(rel.Intersection(a, b)).IsEmpty())
+	})
+}
+
+func TestRename(t *testing.T) {
+	t.Parallel()
+
+	must := mustFor(t)
+	swap := map[string]string{"x": "y", "y": "x"}
+	forEachRandomPair(t, func(s, _ rel.Relation) bool {
+		swapped := must(rel.Rename(s, swap))
+		renamed := must(rel.Rename(s, map[string]string{"x": "a"}))
+		return test.True(t, swapped.Heading().Equal(rel.NewHeading("x", "y"))) &&
+			test.True(t, renamed.Heading().Equal(rel.NewHeading("a", "y"))) &&
+			assertRelationEqual(t, s, must(rel.Rename(swapped, swap))) &&
+			assertRelationEqual(t, s, must(rel.Rename(renamed, map[string]string{"a": "x"})))
+	})
+
+	s := rel.MustNew([]string{"x", "y"}, []any{1, 2})
+	_, err := rel.Rename(s, map[string]string{"x": "y"})
+	test.True(t, errors.Is(err, rel.ErrDuplicateAttribute))
+	_, err = rel.Rename(s, map[string]string{"z": "a"})
+	test.True(t, errors.Is(err, rel.ErrUnknownAttribute))
+	assertRelationEqual(t,
+		rel.MustNew([]string{"y", "z"}, []any{1, 2}),
+		must(rel.Rename(s, map[string]string{"x": "y", "y": "z"})))
+}
+
+func TestExtend(t *testing.T) {
+	t.Parallel()
+
+	must := mustFor(t)
+	sum := func(t rel.Tuple) any { return t.MustGet("x").(int) + t.MustGet("y").(int) }
+	forEachRandomPair(t, func(s, _ rel.Relation) bool {
+		e := must(rel.Extend(s, "sum", sum))
+		return assertRelationEqual(t, s, must(rel.Project(e, "x", "y"))) &&
+			test.True(t, e.Heading().Equal(rel.NewHeading("x", "y", "sum"))) &&
+			test.True(t, rel.Restrict(e, func(t rel.Tuple) bool { return t.MustGet("sum") != sum(t) }).IsEmpty())
+	})
+
+	_, err := rel.Extend(rel.MustNew([]string{"x", "y"}), "x", sum)
+	test.True(t, errors.Is(err, rel.ErrDuplicateAttribute))
+}
+
+func TestSemiJoinAntiJoin(t *testing.T) {
+	t.Parallel()
+
+	must := mustFor(t)
+	forEachRandomPair(t, func(s, u rel.Relation) bool {
+		semi, anti := rel.SemiJoin(s, u), rel.AntiJoin(s, u)
+		return assertRelationEqual(t, must(rel.Project(rel.Join(s, u), "x", "y")), semi) &&
+			assertRelationEqual(t, s, must(rel.Union(semi, anti))) &&
+			test.True(t, must(rel.Intersection(semi, anti)).IsEmpty())
+	})
+
+	// With no common attributes, everything matches a non-empty relation.
+	s := rel.MustNew([]string{"x"}, []any{1}, []any{2})
+	z := rel.MustNew([]string{"z"}, []any{3})
+	assertRelationEqual(t, s, rel.SemiJoin(s, z))
+	assertRelationEqual(t, rel.MustNew([]string{"x"}), rel.AntiJoin(s, z))
+	assertRelationEqual(t, rel.MustNew([]string{"x"}), rel.SemiJoin(s, rel.MustNew([]string{"z"})))
+	assertRelationEqual(t, s, rel.AntiJoin(s, rel.MustNew([]string{"z"})))
+}
+
+func TestDivide(t *testing.T) {
+	t.Parallel()
+
+	must := mustFor(t)
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	rounds := 200
+	if testing.Short() {
+		rounds = 20
+	}
+	for i := 0; i < rounds; i++ {
+		q := randomRelation(r, 4, "x")
+		d := randomRelation(r, 4, "y")
+		s := randomRelation(r, 8, "x", "y")
+		if d.IsEmpty() {
+			// Dividing by an empty relation leaves all candidates.
+			if !assertRelationEqual(t, must(rel.Project(s, "x")), must(rel.Divide(s, d))) {
+				break
+			}
+			continue
+		}
+		// (q × d) ÷ d = q
+		if !assertRelationEqual(t, q, must(rel.Divide(must(rel.CartesianProduct(q, d)), d)), "q=%v d=%v", q, d) {
+			break
+		}
+		// (s ÷ d) × d ⊆ s, and s ÷ d is the largest such relation.
+		div := must(rel.Divide(s, d))
+		if !test.True(t, must(rel.CartesianProduct(div, d)).Tuples().IsSubsetOf(s.Tuples()), "s=%v d=%v", s, d) {
+			break
+		}
+		for j := must(rel.Difference(must(rel.Project(s, "x")), div)).Range(); j.Next(); {
+			extra := must(rel.FromSet(div.Heading(), frozen.NewSet(j.Value())))
+			test.False(t, must(rel.CartesianProduct(extra, d)).Tuples().IsSubsetOf(s.Tuples()), "s=%v d=%v", s, d)
+		}
+	}
+
+	_, err := rel.Divide(rel.MustNew([]string{"x", "y"}), rel.MustNew([]string{"z"}))
+	test.True(t, errors.Is(err, rel.ErrHeadingMismatch))
+}
+
+func TestSetOperations(t *testing.T) {
+	t.Parallel()
+
+	must := mustFor(t)
+	forEachRandomPair(t, func(s, u rel.Relation) bool {
+		u = must(rel.Rename(u, map[string]string{"y": "x", "z": "y"}))
+		return assertRelationEqual(t, must(rel.Intersection(s, u)), must(rel.Difference(s, must(rel.Difference(s, u))))) &&
+			assertRelationEqual(t, must(rel.Union(s, u)), must(rel.Union(must(rel.Difference(s, u)), u)))
+	})
+
+	s := rel.MustNew([]string{"x", "y"}, []any{1, 2})
+	u := rel.MustNew([]string{"y", "z"})
+	for _, op := range []func(s, t rel.Relation) (rel.Relation, error){
+		rel.Union, rel.Intersection, rel.Difference,
+	} {
+		// A heading mismatch is an error even if a relation is empty.
+		_, err := op(s, u)
+		test.True(t, errors.Is(err, rel.ErrHeadingMismatch))
+		_, err = op(u, s)
+		test.True(t, errors.Is(err, rel.ErrHeadingMismatch))
+	}
+	assertRelationEqual(t, s, must(rel.Union(s, rel.MustNew([]string{"x", "y"}))))
+}
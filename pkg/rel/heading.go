@@ -0,0 +1,97 @@
+package rel
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/arr-ai/hash"
+
+	"github.com/arr-ai/frozen"
+)
+
+// Heading is the set of attributes that every tuple in a Relation has.
+type Heading struct {
+	attrs frozen.Set[string]
+}
+
+// NewHeading returns a Heading with the given attributes.
+func NewHeading(attrs ...string) Heading {
+	return Heading{attrs: frozen.NewSet(attrs...)}
+}
+
+// Attrs returns the attributes of h.
+func (h Heading) Attrs() frozen.Set[string] {
+	return h.attrs
+}
+
+// Elements returns the attributes of h in sorted order.
+func (h Heading) Elements() []string {
+	attrs := h.attrs.Elements()
+	sort.Strings(attrs)
+	return attrs
+}
+
+// Count returns the number of attributes in h.
+func (h Heading) Count() int {
+	return h.attrs.Count()
+}
+
+// Has returns true iff h has attr.
+func (h Heading) Has(attr string) bool {
+	return h.attrs.Has(attr)
+}
+
+// IsSubsetOf returns true iff every attribute in h is also in g.
+func (h Heading) IsSubsetOf(g Heading) bool {
+	return h.attrs.IsSubsetOf(g.attrs)
+}
+
+// Union returns a Heading with the attributes in either h or g.
+func (h Heading) Union(g Heading) Heading {
+	return Heading{attrs: h.attrs.Union(g.attrs)}
+}
+
+// Intersection returns a Heading with the attributes in both h and g.
+func (h Heading) Intersection(g Heading) Heading {
+	return Heading{attrs: h.attrs.Intersection(g.attrs)}
+}
+
+// Difference returns a Heading with the attributes in h that aren't in g.
+func (h Heading) Difference(g Heading) Heading {
+	return Heading{attrs: h.attrs.Difference(g.attrs)}
+}
+
+// Matches returns true iff t has exactly the attributes in h.
+func (h Heading) Matches(t Tuple) bool {
+	if t.Count() != h.Count() {
+		return false
+	}
+	for i := h.attrs.Range(); i.Next(); {
+		if !t.Has(i.Value()) {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash computes a hash value for h.
+func (h Heading) Hash(seed uintptr) uintptr {
+	return hash.Any(h.attrs, seed)
+}
+
+// Equal returns true iff h and g have the same attributes.
+func (h Heading) Equal(g Heading) bool {
+	return h.attrs.Equal(g.attrs)
+}
+
+// Same returns true iff a is a Heading equal to h.
+func (h Heading) Same(a any) bool {
+	g, is := a.(Heading)
+	return is && h.Equal(g)
+}
+
+// String returns a string representation of h, with attributes in sorted
+// order.
+func (h Heading) String() string {
+	return "{" + strings.Join(h.Elements(), ", ") + "}"
+}
@@ -0,0 +1,93 @@
+package rel
+
+import (
+	"github.com/arr-ai/hash"
+
+	"github.com/arr-ai/frozen"
+)
+
+// NullValue is the type of Null.
+type NullValue struct{}
+
+// Null marks an attribute with no value, such as one that an outer join found
+// no matching tuple for. Unlike SQL's NULL, Null equals itself, so tuples with
+// Null in the same attributes can be deduplicated, grouped and joined.
+var Null NullValue
+
+// Hash computes a hash value for Null.
+func (NullValue) Hash(seed uintptr) uintptr {
+	return hash.Uintptr(uintptr(6595843862935123397&uint64(^uintptr(0))), seed)
+}
+
+// Equal returns true, since there's only one Null.
+func (NullValue) Equal(NullValue) bool {
+	return true
+}
+
+// Same returns true iff a is Null.
+func (NullValue) Same(a any) bool {
+	_, is := a.(NullValue)
+	return is
+}
+
+// String returns "null".
+func (NullValue) String() string {
+	return "null"
+}
+
+// Missing selects how outer joins represent attributes that have no matching
+// tuple to take a value from.
+type Missing int
+
+const (
+	// UseNull sets missing attributes to Null, so every tuple still has every
+	// attribute in the heading.
+	UseNull Missing = iota
+
+	// OmitMissing leaves missing attributes out of tuples. Such tuples have
+	// only some of the attributes in the heading, so FromSet rejects them.
+	// Joins treat a missing attribute as equal to nothing: a tuple that lacks
+	// an attribute being joined on matches no tuple, and one that lacks any
+	// other attribute joins as usual, leaving it out of the result.
+	OmitMissing
+)
+
+// LeftJoin returns Join(s, t) plus the tuples of s that match no tuple in t,
+// with the attributes unique to t represented as missing says.
+func LeftJoin(s, t Relation, missing Missing) Relation {
+	j := join(s, t)
+	j.tuples = j.tuples.Union(padMissing(AntiJoin(s, t), t.heading, missing))
+	return j
+}
+
+// RightJoin returns Join(s, t) plus the tuples of t that match no tuple in s,
+// with the attributes unique to s represented as missing says.
+func RightJoin(s, t Relation, missing Missing) Relation {
+	return LeftJoin(t, s, missing)
+}
+
+// FullJoin returns Join(s, t) plus the tuples of either relation that match
+// no tuple in the other, with the attributes unique to the other relation
+// represented as missing says.
+func FullJoin(s, t Relation, missing Missing) Relation {
+	j := LeftJoin(s, t, missing)
+	j.tuples = j.tuples.Union(padMissing(AntiJoin(t, s), s.heading, missing))
+	return j
+}
+
+// padMissing returns the tuples of r with the attributes in heading that r
+// doesn't have represented as missing says.
+func padMissing(r Relation, heading Heading, missing Missing) frozen.Set[Tuple] {
+	absent := heading.Difference(r.heading)
+	if missing == OmitMissing || absent.Count() == 0 {
+		return r.tuples
+	}
+	var nulls frozen.MapBuilder[string, any]
+	for i := absent.attrs.Range(); i.Next(); {
+		nulls.Put(i.Value(), Null)
+	}
+	padding := nulls.Finish()
+	return frozen.SetMap(r.tuples, func(t Tuple) Tuple {
+		return t.Update(padding)
+	})
+}
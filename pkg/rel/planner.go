@@ -0,0 +1,441 @@
+package rel
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/arr-ai/hash"
+
+	"github.com/arr-ai/frozen"
+)
+
+// sampleSize is the number of tuples per relation that the join planner
+// inspects to estimate how many distinct values each attribute has.
+const sampleSize = 1024
+
+// maxExhaustiveJoin is the largest number of relations for which the join
+// planner considers every bushy plan. Beyond it, it joins greedily.
+const maxExhaustiveJoin = 10
+
+// ExplainJoin returns the plan that Join would use to join relations, as an
+// indented tree with one operation per line and estimated result sizes.
+func ExplainJoin(relations ...Relation) string {
+	if len(relations) == 0 {
+		relations = []Relation{dee()}
+	}
+	var b strings.Builder
+	var walk func(p joinPlan, depth int)
+	walk = func(p joinPlan, depth int) {
+		if depth > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(strings.Repeat("  ", depth))
+		op, inputs := p.plan()
+		b.WriteString(op)
+		for _, input := range inputs {
+			walk(input, depth+1)
+		}
+	}
+	walk(planJoin(relations), 0)
+	return b.String()
+}
+
+// dee returns the relation with no attributes and one empty tuple, which is
+// the identity for Join.
+func dee() Relation {
+	return Relation{tuples: frozen.NewSet(NewTuple())}
+}
+
+// joinStats holds estimates of the size of a relation and the number of
+// distinct values of each of its attributes.
+type joinStats struct {
+	count    float64
+	distinct map[string]float64
+}
+
+// collectStats counts the tuples in r and estimates the distinct values of each
+// attribute from a sample of its tuples. The sample is the first sampleSize
+// tuples in iteration order, which is effectively random, since tuples are
+// stored by hash.
+func collectStats(r Relation) joinStats {
+	freqs := map[string]map[uintptr]int{}
+	for i := r.heading.attrs.Range(); i.Next(); {
+		freqs[i.Value()] = map[uintptr]int{}
+	}
+	sampled := 0
+	for i := r.Range(); sampled < sampleSize && i.Next(); sampled++ {
+		for j := i.Value().Range(); j.Next(); {
+			if freq, has := freqs[j.Key()]; has {
+				freq[hash.Any(j.Value(), 0)]++
+			}
+		}
+	}
+	st := joinStats{count: float64(r.Count()), distinct: make(map[string]float64, len(freqs))}
+	for attr, freq := range freqs {
+		st.distinct[attr] = estimateDistinct(freq, sampled, r.Count())
+	}
+	return st
+}
+
+// estimateDistinct estimates the distinct values in a population of n from the
+// frequencies of values in a sample of s, using the Guaranteed-Error Estimator
+// of Charikar et al.: values seen once in the sample stand for sqrt(n/s)
+// values each, and values seen more often stand for themselves.
+func estimateDistinct(freq map[uintptr]int, s, n int) float64 {
+	seen := float64(len(freq))
+	if s == n {
+		return seen
+	}
+	once := 0
+	for _, f := range freq {
+		if f == 1 {
+			once++
+		}
+	}
+	d := math.Sqrt(float64(n)/float64(s))*float64(once) + seen - float64(once)
+	return math.Min(math.Max(d, seen), float64(n))
+}
+
+// join estimates the stats of the join of relations with stats a and b,
+// assuming values are uniformly distributed and, for common attributes, that
+// the side with fewer distinct values has a subset of the other side's.
+func (a joinStats) join(b joinStats) joinStats {
+	count := a.count * b.count
+	for attr, da := range a.distinct {
+		if db, has := b.distinct[attr]; has {
+			if d := math.Max(da, db); d > 0 {
+				count /= d
+			} else {
+				count = 0
+			}
+		}
+	}
+	distinct := make(map[string]float64, len(a.distinct)+len(b.distinct))
+	for _, st := range []joinStats{a, b} {
+		for attr, d := range st.distinct {
+			if e, has := distinct[attr]; has {
+				d = math.Min(d, e)
+			}
+			distinct[attr] = math.Min(d, count)
+		}
+	}
+	return joinStats{count: count, distinct: distinct}
+}
+
+// joinPlan is a node in a join plan.
+type joinPlan interface {
+	// eval computes the result of the plan.
+	eval() Relation
+
+	// stats returns the estimated stats of the result.
+	stats() joinStats
+
+	// plan returns a description of the operation and its inputs.
+	plan() (op string, inputs []joinPlan)
+}
+
+// planJoin returns a plan to join relations, which mustn't be empty. If the
+// relations form a cyclic query, the plan is a single generic join.
+// Otherwise, it is a tree of binary joins chosen to minimize the total
+// estimated size of the intermediate results.
+func planJoin(relations []Relation) joinPlan {
+	scans := make([]joinPlan, 0, len(relations))
+	headings := make([]Heading, 0, len(relations))
+	for i, r := range relations {
+		scans = append(scans, &scanPlan{i: i, r: r, st: collectStats(r)})
+		headings = append(headings, r.heading)
+	}
+	switch {
+	case len(scans) == 1:
+		return scans[0]
+	case isCyclic(headings):
+		return newGenericJoinPlan(scans)
+	case len(scans) <= maxExhaustiveJoin:
+		return planExhaustive(scans)
+	default:
+		return planGreedy(scans)
+	}
+}
+
+// planExhaustive finds the cheapest bushy plan by dynamic programming over
+// the subsets of scans.
+func planExhaustive(scans []joinPlan) joinPlan {
+	n := len(scans)
+	best := make([]joinPlan, 1<<n)
+	cost := make([]float64, 1<<n)
+	for i, scan := range scans {
+		best[1<<i] = scan
+	}
+	for set := 1; set < 1<<n; set++ {
+		if bits.OnesCount(uint(set)) < 2 {
+			continue
+		}
+		cost[set] = math.Inf(1)
+		// Visit each split into left and right once, with the lowest member
+		// of set always on the left.
+		low := set & -set
+		for left := (set - 1) & set; left > 0; left = (left - 1) & set {
+			if left&low == 0 {
+				continue
+			}
+			right := set ^ left
+			p := newBinaryJoinPlan(best[left], best[right])
+			if c := cost[left] + cost[right] + p.st.count; c < cost[set] {
+				best[set], cost[set] = p, c
+			}
+		}
+	}
+	return best[len(best)-1]
+}
+
+// planGreedy repeatedly joins the pair of plans with the smallest estimated
+// result until only one remains.
+func planGreedy(plans []joinPlan) joinPlan {
+	plans = append([]joinPlan(nil), plans...)
+	for len(plans) > 1 {
+		var best *binaryJoinPlan
+		var bi, bj int
+		for i := range plans {
+			for j := i + 1; j < len(plans); j++ {
+				if p := newBinaryJoinPlan(plans[i], plans[j]); best == nil || p.st.count < best.st.count {
+					best, bi, bj = p, i, j
+				}
+			}
+		}
+		plans[bi] = best
+		plans = append(plans[:bj], plans[bj+1:]...)
+	}
+	return plans[0]
+}
+
+// isCyclic reports whether the hypergraph with an edge for each heading is
+// cyclic, using GYO reduction: repeatedly drop attributes that only one edge
+// has and edges contained in another edge. The hypergraph is acyclic iff
+// this leaves at most one edge.
+func isCyclic(headings []Heading) bool {
+	edges := make([]frozen.Set[string], 0, len(headings))
+	for _, h := range headings {
+		edges = append(edges, h.attrs)
+	}
+	for changed := true; changed && len(edges) > 1; {
+		changed = false
+		var counts frozen.Map[string, int]
+		for _, e := range edges {
+			for i := e.Range(); i.Next(); {
+				counts = counts.With(i.Value(), counts.GetElse(i.Value(), 0)+1)
+			}
+		}
+		for i, e := range edges {
+			lonely := e.Where(func(attr string) bool { return counts.MustGet(attr) == 1 })
+			if !lonely.IsEmpty() {
+				edges[i] = e.Difference(lonely)
+				changed = true
+			}
+		}
+		for i := 0; i < len(edges); i++ {
+			for j := range edges {
+				if i != j && edges[i].IsSubsetOf(edges[j]) {
+					edges = append(edges[:i], edges[i+1:]...)
+					i--
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return len(edges) > 1
+}
+
+// scanPlan reads one of the relations being joined.
+type scanPlan struct {
+	i  int
+	r  Relation
+	st joinStats
+}
+
+func (p *scanPlan) eval() Relation {
+	return p.r
+}
+
+func (p *scanPlan) stats() joinStats {
+	return p.st
+}
+
+func (p *scanPlan) plan() (string, []joinPlan) {
+	return fmt.Sprintf("relation %d %v (%d tuples)", p.i, p.r.heading, p.r.Count()), nil
+}
+
+// binaryJoinPlan joins the results of two plans by hashing both on their
+// common attributes.
+type binaryJoinPlan struct {
+	left, right joinPlan
+	st          joinStats
+}
+
+func newBinaryJoinPlan(left, right joinPlan) *binaryJoinPlan {
+	return &binaryJoinPlan{left: left, right: right, st: left.stats().join(right.stats())}
+}
+
+func (p *binaryJoinPlan) eval() Relation {
+	return join(p.left.eval(), p.right.eval())
+}
+
+func (p *binaryJoinPlan) stats() joinStats {
+	return p.st
+}
+
+func (p *binaryJoinPlan) plan() (string, []joinPlan) {
+	var common []string
+	for attr := range p.left.stats().distinct {
+		if _, has := p.right.stats().distinct[attr]; has {
+			common = append(common, attr)
+		}
+	}
+	inputs := []joinPlan{p.left, p.right}
+	if len(common) == 0 {
+		return fmt.Sprintf("product (est. %.0f tuples)", p.st.count), inputs
+	}
+	return fmt.Sprintf("join on %v (est. %.0f tuples)", NewHeading(common...), p.st.count), inputs
+}
+
+// genericJoinPlan joins the results of several plans at once, binding one
+// attribute at a time to the values that every input with that attribute
+// has. Unlike a tree of binary joins, its running time is bounded by the
+// largest possible result, even for cyclic queries.
+type genericJoinPlan struct {
+	inputs []joinPlan
+	order  []string
+	st     joinStats
+}
+
+func newGenericJoinPlan(inputs []joinPlan) *genericJoinPlan {
+	st := inputs[0].stats()
+	for _, input := range inputs[1:] {
+		st = st.join(input.stats())
+	}
+	// Bind attributes with fewer distinct values first, to prune early.
+	order := make([]string, 0, len(st.distinct))
+	for attr := range st.distinct {
+		order = append(order, attr)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		di, dj := st.distinct[order[i]], st.distinct[order[j]]
+		return di < dj || di == dj && order[i] < order[j]
+	})
+	return &genericJoinPlan{inputs: inputs, order: order, st: st}
+}
+
+// joinTrie indexes the tuples of a relation by the values of its attributes,
+// one level per attribute, in the order that a generic join binds them.
+type joinTrie struct {
+	children frozen.Map[any, *joinTrie]
+}
+
+// absent is the joinTrie key for tuples that lack an attribute. It only
+// appears at levels for attributes that no other input has, since tuples
+// that lack an attribute being joined on match nothing.
+type absent struct{}
+
+func newJoinTrie(tuples frozen.Set[Tuple], levels []string) *joinTrie {
+	if len(levels) == 0 {
+		return &joinTrie{}
+	}
+	attr := levels[0]
+	groups := frozen.SetGroupBy(tuples, func(t Tuple) any {
+		if v, has := t.Get(attr); has {
+			return v
+		}
+		return absent{}
+	})
+	return &joinTrie{children: frozen.MapMap(groups, func(_ any, group frozen.Set[Tuple]) *joinTrie {
+		return newJoinTrie(group, levels[1:])
+	})}
+}
+
+func (p *genericJoinPlan) eval() Relation {
+	relations := make([]Relation, 0, len(p.inputs))
+	var heading Heading
+	empty := false
+	for _, input := range p.inputs {
+		r := input.eval()
+		relations = append(relations, r)
+		heading = heading.Union(r.heading)
+		empty = empty || r.IsEmpty()
+	}
+	if empty {
+		return Relation{heading: heading}
+	}
+
+	tries := make([]*joinTrie, 0, len(relations))
+	// holders[i] lists the inputs that have attribute p.order[i].
+	holders := make([][]int, len(p.order))
+	for k, r := range relations {
+		for i, attr := range p.order {
+			if r.heading.Has(attr) {
+				holders[i] = append(holders[i], k)
+			}
+		}
+	}
+	for _, r := range relations {
+		levels := make([]string, 0, r.heading.Count())
+		var shared []string
+		for i, attr := range p.order {
+			if r.heading.Has(attr) {
+				levels = append(levels, attr)
+				if len(holders[i]) > 1 {
+					shared = append(shared, attr)
+				}
+			}
+		}
+		// As in join, tuples that lack an attribute being joined on match
+		// nothing.
+		tuples := r.tuples.Where(func(t Tuple) bool { return hasAttrs(t, shared) })
+		tries = append(tries, newJoinTrie(tuples, levels))
+	}
+
+	var b frozen.SetBuilder[Tuple]
+	var bind func(i int, t Tuple, nodes []*joinTrie)
+	bind = func(i int, t Tuple, nodes []*joinTrie) {
+		if i == len(p.order) {
+			b.Add(t)
+			return
+		}
+		ks := holders[i]
+		smallest := ks[0]
+		for _, k := range ks[1:] {
+			if nodes[k].children.Count() < nodes[smallest].children.Count() {
+				smallest = k
+			}
+		}
+	values:
+		for j := nodes[smallest].children.Range(); j.Next(); {
+			v := j.Key()
+			next := append([]*joinTrie(nil), nodes...)
+			for _, k := range ks {
+				child, has := nodes[k].children.Get(v)
+				if !has {
+					continue values
+				}
+				next[k] = child
+			}
+			if v == (absent{}) {
+				bind(i+1, t, next)
+			} else {
+				bind(i+1, t.With(p.order[i], v), next)
+			}
+		}
+	}
+	bind(0, Tuple{}, tries)
+	return Relation{heading: heading, tuples: b.Finish()}
+}
+
+func (p *genericJoinPlan) stats() joinStats {
+	return p.st
+}
+
+func (p *genericJoinPlan) plan() (string, []joinPlan) {
+	return fmt.Sprintf("generic join over %s (est. %.0f tuples)", strings.Join(p.order, ", "), p.st.count), p.inputs
+}
@@ -0,0 +1,97 @@
+package rel_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	"github.com/arr-ai/frozen/pkg/rel"
+)
+
+// foldJoin joins relations pairwise in argument order.
+func foldJoin(relations ...rel.Relation) rel.Relation {
+	result := relations[0]
+	for _, r := range relations[1:] {
+		result = rel.Join(result, r)
+	}
+	return result
+}
+
+func TestJoinPlanAvoidsProducts(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	xy := randomRelation(r, 200, "x", "y")
+	zw := randomRelation(r, 200, "z", "w")
+	yz := rel.MustNew([]string{"y", "z"}, []any{0, 1})
+
+	plan := rel.ExplainJoin(xy, zw, yz)
+	test.False(t, strings.Contains(plan, "product"), "%s", plan)
+	assertRelationEqual(t, foldJoin(xy, zw, yz), rel.Join(xy, zw, yz))
+}
+
+func TestExplainJoin(t *testing.T) {
+	t.Parallel()
+
+	a := rel.MustNew([]string{"x", "y"}, []any{1, 1}, []any{2, 1}, []any{3, 2})
+	b := rel.MustNew([]string{"y", "z"}, []any{1, 1}, []any{2, 2})
+	c := rel.MustNew([]string{"z", "w"}, []any{1, 1})
+	test.Equal(t,
+		"join on {y} (est. 2 tuples)\n"+
+			"  relation 0 {x, y} (3 tuples)\n"+
+			"  join on {z} (est. 1 tuples)\n"+
+			"    relation 1 {y, z} (2 tuples)\n"+
+			"    relation 2 {w, z} (1 tuples)",
+		rel.ExplainJoin(a, b, c))
+	test.Equal(t, "relation 0 {x, y} (3 tuples)", rel.ExplainJoin(a))
+	test.Equal(t, "relation 0 {} (1 tuples)", rel.ExplainJoin())
+}
+
+func TestJoinCyclic(t *testing.T) {
+	t.Parallel()
+
+	// Triangles in a random graph.
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	edges := randomRelation(r, 300, "from", "to")
+	rename := func(from, to string) rel.Relation {
+		return mustFor(t)(rel.Rename(edges, map[string]string{"from": from, "to": to}))
+	}
+	ab, bc, ca := rename("a", "b"), rename("b", "c"), rename("c", "a")
+
+	plan := rel.ExplainJoin(ab, bc, ca)
+	test.True(t, strings.HasPrefix(plan, "generic join over "), "%s", plan)
+	assertRelationEqual(t, foldJoin(ab, bc, ca), rel.Join(ab, bc, ca))
+
+	// A cycle with an empty relation is empty.
+	empty := rel.MustNew([]string{"c", "a"})
+	assertRelationEqual(t, rel.MustNew([]string{"a", "b", "c"}), rel.Join(ab, bc, empty))
+}
+
+func TestJoinRandom(t *testing.T) {
+	t.Parallel()
+
+	attrs := []string{"a", "b", "c", "d", "e"}
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	rounds := 50
+	if testing.Short() {
+		rounds = 10
+	}
+	for round := 0; round < rounds; round++ {
+		n := 3 + r.Intn(10)
+		relations := make([]rel.Relation, 0, n)
+		for i := 0; i < n; i++ {
+			perm := r.Perm(len(attrs))[:1+r.Intn(2)]
+			header := make([]string, 0, len(perm))
+			for _, p := range perm {
+				header = append(header, attrs[p])
+			}
+			relations = append(relations, randomRelation(r, 6, header...))
+		}
+		if !assertRelationEqual(t, foldJoin(relations...), rel.Join(relations...),
+			fmt.Sprintf("%v\n%s\n", relations, rel.ExplainJoin(relations...))) {
+			break
+		}
+	}
+}
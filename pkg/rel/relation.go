@@ -1,118 +1,284 @@
 package rel
 
 import (
-	"log"
+	"errors"
+	"fmt"
+
+	"github.com/arr-ai/hash"
 
 	"github.com/arr-ai/frozen"
 )
 
-type (
-	Tuple           = frozen.Map[string, any]
-	Relation        = frozen.Set[Tuple]
-	RelationBuilder = frozen.SetBuilder[Tuple]
+var (
+	// ErrHeadingMismatch is returned when a tuple or relation doesn't have the
+	// heading an operation requires.
+	ErrHeadingMismatch = errors.New("heading mismatch")
+
+	// ErrUnknownAttribute is returned when an operation names an attribute
+	// that isn't in a relation's heading.
+	ErrUnknownAttribute = errors.New("unknown attribute")
+
+	// ErrDuplicateAttribute is returned when an operation would give a
+	// relation two attributes with the same name.
+	ErrDuplicateAttribute = errors.New("duplicate attribute")
+
+	// ErrNotRelation is returned when an attribute expected to hold nested
+	// relations holds some other value.
+	ErrNotRelation = errors.New("not a relation")
 )
 
+type Tuple = frozen.Map[string, any]
+
 func NewTuple(kvs ...frozen.KeyValue[string, any]) Tuple {
 	return frozen.NewMap(kvs...)
 }
 
-var trueSet = frozen.NewSet(NewTuple())
+// Relation is a set of tuples that all have the attributes in its heading,
+// except that outer joins using OmitMissing may leave some out. The zero
+// Relation has no attributes and no tuples.
+type Relation struct {
+	heading Heading
+	tuples  frozen.Set[Tuple]
+}
 
-// New returns a new relation.
-func New(header []string, tuples ...[]any) Relation {
-	r := Relation{}
-	for _, row := range tuples {
+// New returns a new relation. It returns an error if header has duplicate
+// attributes or any row doesn't have one value per attribute.
+func New(header []string, rows ...[]any) (Relation, error) {
+	heading := NewHeading(header...)
+	if heading.Count() != len(header) {
+		return Relation{}, fmt.Errorf("New: %w in %v", ErrDuplicateAttribute, header)
+	}
+	b := NewRelationBuilder(heading)
+	for _, row := range rows {
 		if len(row) != len(header) {
-			panic("header/row size mismatch")
+			return Relation{}, fmt.Errorf("New: %w: %d values for %d attributes", ErrHeadingMismatch, len(row), len(header))
 		}
-		t := NewTuple()
+		var t frozen.MapBuilder[string, any]
 		for i, h := range header {
-			t = t.With(h, row[i])
+			t.Put(h, row[i])
 		}
-		r = r.With(t)
+		b.tuples.Add(t.Finish())
+	}
+	return b.Finish(), nil
+}
+
+// MustNew is New, but panics if New would return an error.
+func MustNew(header []string, rows ...[]any) Relation {
+	r, err := New(header, rows...)
+	if err != nil {
+		panic(err)
 	}
 	return r
 }
 
-// Project returns a Set with the result of projecting each map.
-func Project(s Relation, attrs ...string) Relation {
-	return frozen.SetMap(s, func(t Tuple) Tuple {
-		return t.Project(attrs...)
-	})
+// FromSet returns a relation with the given heading and tuples. It returns an
+// error if any tuple doesn't have exactly the attributes in heading.
+func FromSet(heading Heading, tuples frozen.Set[Tuple]) (Relation, error) {
+	for i := tuples.Range(); i.Next(); {
+		if t := i.Value(); !heading.Matches(t) {
+			return Relation{}, fmt.Errorf("FromSet: %w: tuple %v for heading %v", ErrHeadingMismatch, t, heading)
+		}
+	}
+	return Relation{heading: heading, tuples: tuples}, nil
+}
+
+// Heading returns the heading of r.
+func (r Relation) Heading() Heading {
+	return r.heading
+}
+
+// Tuples returns the tuples in r.
+func (r Relation) Tuples() frozen.Set[Tuple] {
+	return r.tuples
+}
+
+// IsEmpty returns true iff r has no tuples.
+func (r Relation) IsEmpty() bool {
+	return r.tuples.IsEmpty()
+}
+
+// Count returns the number of tuples in r.
+func (r Relation) Count() int {
+	return r.tuples.Count()
+}
+
+// Has returns true iff t is in r.
+func (r Relation) Has(t Tuple) bool {
+	return r.tuples.Has(t)
+}
+
+// Range returns an Iterator over the tuples in r.
+func (r Relation) Range() frozen.Iterator[Tuple] {
+	return r.tuples.Range()
+}
+
+// Hash computes a hash value for r.
+func (r Relation) Hash(seed uintptr) uintptr {
+	return hash.Any(r.tuples, r.heading.Hash(seed))
+}
+
+// Equal returns true iff r and s have the same heading and tuples.
+func (r Relation) Equal(s Relation) bool {
+	return r.heading.Equal(s.heading) && r.tuples.Equal(s.tuples)
+}
+
+// Same returns true iff a is a Relation equal to r.
+func (r Relation) Same(a any) bool {
+	s, is := a.(Relation)
+	return is && r.Equal(s)
+}
+
+// String returns a string representation of the tuples in r.
+func (r Relation) String() string {
+	return r.tuples.String()
+}
+
+// Format writes a string representation of the tuples in r into f.
+func (r Relation) Format(f fmt.State, verb rune) {
+	r.tuples.Format(f, verb)
+}
+
+// RelationBuilder provides a more efficient way to build relations
+// incrementally.
+type RelationBuilder struct {
+	heading Heading
+	tuples  frozen.SetBuilder[Tuple]
+}
+
+// NewRelationBuilder returns a RelationBuilder for a relation with the given
+// heading.
+func NewRelationBuilder(heading Heading) *RelationBuilder {
+	return &RelationBuilder{heading: heading}
+}
+
+// Add adds t to the relation under construction. It returns an error if t
+// doesn't have exactly the attributes in the builder's heading.
+func (b *RelationBuilder) Add(t Tuple) error {
+	if !b.heading.Matches(t) {
+		return fmt.Errorf("RelationBuilder.Add: %w: tuple %v for heading %v", ErrHeadingMismatch, t, b.heading)
+	}
+	b.tuples.Add(t)
+	return nil
+}
+
+// Finish returns a Relation containing all tuples added since the
+// RelationBuilder was created or the last call to Finish.
+func (b *RelationBuilder) Finish() Relation {
+	return Relation{heading: b.heading, tuples: b.tuples.Finish()}
+}
+
+// Project returns a relation with the tuples of r restricted to attrs. It
+// returns an error if r doesn't have all of attrs.
+func Project(r Relation, attrs ...string) (Relation, error) {
+	heading := NewHeading(attrs...)
+	if !heading.IsSubsetOf(r.heading) {
+		return Relation{}, fmt.Errorf("Project: %w: %v not in %v",
+			ErrUnknownAttribute, heading.Difference(r.heading), r.heading)
+	}
+	return project(r, heading), nil
+}
+
+func project(r Relation, heading Heading) Relation {
+	attrs := heading.Elements()
+	return Relation{
+		heading: heading,
+		tuples: frozen.SetMap(r.tuples, func(t Tuple) Tuple {
+			return t.Project(attrs...)
+		}),
+	}
 }
 
 // Join returns all {x, y, z} such that s has {x, y} and t has {y, z}.
-// x, y and z represent sets of keys:
+// x, y and z represent sets of attributes:
+//
+//	x: attributes unique to the heading of s
+//	y: attributes common to both headings
+//	z: attributes unique to the heading of t
 //
-//	x: keys unique to maps in s
-//	y: keys common to maps in both
-//	z: keys unique to maps in t
+// Joining no relations returns the relation with no attributes and one empty
+// tuple, which is the identity for Join.
 //
-// It is assumed that all maps in s have the same keys and likewise for t.
+// When joining more than two relations, Join estimates the sizes of the
+// possible intermediate results and chooses an order to keep them small.
+// ExplainJoin shows the chosen plan.
 func Join(relations ...Relation) Relation {
-	s := relations[0]
-	for _, t := range relations[1:] {
-		s = join(s, t)
+	switch len(relations) {
+	case 0:
+		return dee()
+	case 1:
+		return relations[0]
+	case 2:
+		return join(relations[0], relations[1])
 	}
-	return s
+	return planJoin(relations).eval()
 }
 
 func join(s, t Relation) Relation {
+	heading := s.heading.Union(t.heading)
 	if s.IsEmpty() || t.IsEmpty() {
-		return s
+		return Relation{heading: heading}
 	}
-	if s.Equal(trueSet) {
-		return t
-	}
-	if t.Equal(trueSet) {
-		return s
-	}
-	sAttrs := s.Any().Keys()
-	tAttrs := t.Any().Keys()
-	commonAttrs := sAttrs.Intersection(tAttrs).Elements()
-	if len(commonAttrs) == 0 {
-		return CartesianProduct(s, t)
-	}
-	projectCommon := func(t Tuple) frozen.Map[string, any] {
+	commonAttrs := s.heading.Intersection(t.heading).Elements()
+	projectCommon := func(t Tuple) Tuple {
 		return t.Project(commonAttrs...)
 	}
-	sGroup := frozen.SetGroupBy(s, projectCommon)
-	tGroup := frozen.SetGroupBy(t, projectCommon)
-	commonKeys := sGroup.Keys().Intersection(tGroup.Keys())
+	matchable := func(t Tuple) bool {
+		return hasAttrs(t, commonAttrs)
+	}
+	sGroup := frozen.SetGroupBy(s.tuples.Where(matchable), projectCommon)
+	tGroup := frozen.SetGroupBy(t.tuples.Where(matchable), projectCommon)
 
-	var rb RelationBuilder
-	for i := commonKeys.Range(); i.Next(); {
-		key := i.Value()
-		a, has := sGroup.Get(key)
-		if !has {
-			panic("wat?")
+	var b frozen.SetBuilder[Tuple]
+	for i := sGroup.Range(); i.Next(); {
+		if group, has := tGroup.Get(i.Key()); has {
+			buildCartesianProduct(&b, Tuple{}, i.Value(), group)
 		}
-		b, has := tGroup.Get(key)
-		if !has {
-			panic("wat?")
+	}
+	return Relation{heading: heading, tuples: b.Finish()}
+}
+
+// hasAttrs returns true iff t has every attribute in attrs. Joins treat an
+// attribute left out by an outer join using OmitMissing as equal to nothing,
+// so a tuple without one of the attributes being joined on matches no tuple.
+func hasAttrs(t Tuple, attrs []string) bool {
+	for _, attr := range attrs {
+		if !t.Has(attr) {
+			return false
 		}
-		buildCartesianProduct(&rb, Tuple{}, a, b)
 	}
-	return rb.Finish()
+	return true
 }
 
-func CartesianProduct(relations ...Relation) Relation {
-	var b RelationBuilder
-	buildCartesianProduct(&b, Tuple{}, relations...)
-	return b.Finish()
+// CartesianProduct returns every combination of one tuple from each of
+// relations. It returns an error if any two relations share an attribute.
+func CartesianProduct(relations ...Relation) (Relation, error) {
+	var heading Heading
+	tuples := make([]frozen.Set[Tuple], 0, len(relations))
+	for _, r := range relations {
+		if common := heading.Intersection(r.heading); common.Count() > 0 {
+			return Relation{}, fmt.Errorf("CartesianProduct: %w: %v", ErrDuplicateAttribute, common)
+		}
+		heading = heading.Union(r.heading)
+		tuples = append(tuples, r.tuples)
+	}
+	var b frozen.SetBuilder[Tuple]
+	buildCartesianProduct(&b, Tuple{}, tuples...)
+	return Relation{heading: heading, tuples: b.Finish()}, nil
 }
 
-func buildCartesianProduct(b *RelationBuilder, t Tuple, relations ...Relation) {
-	if len(relations) > 0 {
-		for i := relations[0].Range(); i.Next(); {
-			buildCartesianProduct(b, t.Update(i.Value()), relations[1:]...)
+func buildCartesianProduct(b *frozen.SetBuilder[Tuple], t Tuple, sets ...frozen.Set[Tuple]) {
+	if len(sets) > 0 {
+		for i := sets[0].Range(); i.Next(); {
+			buildCartesianProduct(b, t.Update(i.Value()), sets[1:]...)
 		}
 	} else {
 		b.Add(t)
 	}
 }
 
-// Nest returns a relation with some attributes nested as subrelations.
+// Nest returns a relation with some attributes nested as subrelations. It
+// returns an error if r doesn't have all the attributes to be nested or if a
+// new attribute would clash with one that remains.
 //
 // Example:
 //
@@ -139,13 +305,24 @@ func buildCartesianProduct(b *RelationBuilder, t Tuple, relations ...Relation) {
 //	  | 4 |  _a__  |
 //	  |   | |_13_| |
 //	  |___|_|_14_|_|
-func Nest(s Relation, attrAttrs frozen.Map[string, frozen.Set[string]]) Relation {
-	log.Print("s = ", s)
+func Nest(r Relation, attrAttrs frozen.Map[string, frozen.Set[string]]) (Relation, error) {
 	// attrAttrs = {aa: {a}}
 
 	// {a}
-	keyAttrs := frozen.Intersection(attrAttrs.Values().Elements()...).Elements()
-	log.Print("keyAttrs = ", keyAttrs)
+	nested := Heading{attrs: frozen.Union(attrAttrs.Values().Elements()...)}
+	if !nested.IsSubsetOf(r.heading) {
+		return Relation{}, fmt.Errorf("Nest: %w: %v not in %v",
+			ErrUnknownAttribute, nested.Difference(r.heading), r.heading)
+	}
+
+	// {c}
+	keyHeading := r.heading.Difference(nested)
+	heading := keyHeading.Union(Heading{attrs: attrAttrs.Keys()})
+	if heading.Count() != keyHeading.Count()+attrAttrs.Count() {
+		return Relation{}, fmt.Errorf("Nest: %w: %v",
+			ErrDuplicateAttribute, keyHeading.Intersection(Heading{attrs: attrAttrs.Keys()}))
+	}
+	keyAttrs := keyHeading.Elements()
 
 	// {
 	//   {c: 1}: {{a: 10, c: 1}, {a: 11, c: 1}},
@@ -153,13 +330,9 @@ func Nest(s Relation, attrAttrs frozen.Map[string, frozen.Set[string]]) Relation
 	//   {c: 3}: {{a: 10, c: 3}, {a: 11, c: 3}},
 	//   {c: 4}: {{a: 13, c: 4}, {a: 14, c: 4}},
 	// }
-	grouped := frozen.SetGroupBy(s, func(el Tuple) Tuple {
-		for _, attr := range keyAttrs {
-			el = el.Without(attr)
-		}
-		return el
+	grouped := frozen.SetGroupBy(r.tuples, func(el Tuple) Tuple {
+		return el.Project(keyAttrs...)
 	})
-	log.Print("grouped = ", grouped)
 
 	// {
 	//   {c: 1}: {c: 1, aa: {{a: 10}, {a: 11}}},
@@ -167,15 +340,14 @@ func Nest(s Relation, attrAttrs frozen.Map[string, frozen.Set[string]]) Relation
 	//   {c: 3}: {c: 3, aa: {{a: 10}, {a: 11}}},
 	//   {c: 4}: {c: 4, aa: {{a: 13}, {a: 14}}},
 	// }
-	mapped := frozen.MapMap(grouped, func(key Tuple, group Relation) Tuple {
+	mapped := frozen.MapMap(grouped, func(key Tuple, group frozen.Set[Tuple]) Tuple {
 		// {c: 1} => {aa: {{a: 10}, {a: 11}}}
 		a := frozen.MapMap(attrAttrs, func(_ string, attrs frozen.Set[string]) any {
-			return Project(group, attrs.Elements()...)
+			return project(Relation{heading: r.heading, tuples: group}, Heading{attrs: attrs})
 		})
 		// {c: 1} => {c: 1, aa: {{a: 10}, {a: 11}}}
 		return a.Update(key)
 	})
-	log.Print("mapped = ", mapped)
 
 	// {
 	//   {c: 1, aa: {{a: 10}, {a: 11}}},
@@ -183,26 +355,40 @@ func Nest(s Relation, attrAttrs frozen.Map[string, frozen.Set[string]]) Relation
 	//   {c: 3, aa: {{a: 10}, {a: 11}}},
 	//   {c: 4, aa: {{a: 13}, {a: 14}}},
 	// }
-	result := mapped.Values()
-	log.Print("result = ", result)
-	return result
+	return Relation{heading: heading, tuples: mapped.Values()}, nil
 }
 
 // Unnest returns a relation with some subrelations unnested. This is the
-// reverse of Nest.
-func Unnest(s Relation, attr string) Relation {
-	var b RelationBuilder
-	for i := s.Range(); i.Next(); {
+// reverse of Nest. It returns an error if r doesn't have attr, attr holds
+// something other than relations with the same heading, or the subrelations
+// have attributes that clash with the rest of r. If r is empty, there are no
+// subrelations to take a heading from, so the result has only the remaining
+// attributes of r.
+func Unnest(r Relation, attr string) (Relation, error) {
+	if !r.heading.Has(attr) {
+		return Relation{}, fmt.Errorf("Unnest: %w: %q not in %v", ErrUnknownAttribute, attr, r.heading)
+	}
+	keyHeading := r.heading.Difference(NewHeading(attr))
+	var nestedHeading *Heading
+	var b frozen.SetBuilder[Tuple]
+	for i := r.tuples.Range(); i.Next(); {
 		t := i.Value()
-		key := t.Without(attr)
-		nestedValues := frozen.MapMap(t.Project(attr), func(_ string, val any) Relation {
-			return val.(Relation)
-		}).Values()
-		all := nestedValues.With(frozen.NewSet(key))
-		joined := Join(all.Elements()...)
-		for j := joined.Range(); j.Next(); {
-			b.Add(j.Value())
+		nested, is := t.MustGet(attr).(Relation)
+		if !is {
+			return Relation{}, fmt.Errorf("Unnest: %w: %q holds %T", ErrNotRelation, attr, t.MustGet(attr))
 		}
+		if nestedHeading == nil {
+			if common := keyHeading.Intersection(nested.heading); common.Count() > 0 {
+				return Relation{}, fmt.Errorf("Unnest: %w: %v", ErrDuplicateAttribute, common)
+			}
+			nestedHeading = &nested.heading
+		} else if !nested.heading.Equal(*nestedHeading) {
+			return Relation{}, fmt.Errorf("Unnest: %w: %v and %v", ErrHeadingMismatch, *nestedHeading, nested.heading)
+		}
+		buildCartesianProduct(&b, t.Without(attr), nested.tuples)
+	}
+	if nestedHeading != nil {
+		keyHeading = keyHeading.Union(*nestedHeading)
 	}
-	return b.Finish()
+	return Relation{heading: keyHeading, tuples: b.Finish()}, nil
 }
@@ -1,32 +1,120 @@
 package rel_test
 
 import (
+	"errors"
 	"fmt"
 	"math/bits"
 	"testing"
 
 	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
 	testset "github.com/arr-ai/frozen/internal/pkg/test/set"
 	"github.com/arr-ai/frozen/pkg/rel"
 )
 
+func assertRelationEqual(t *testing.T, expected, actual rel.Relation, msgAndArgs ...any) bool {
+	t.Helper()
+
+	return test.True(t, expected.Heading().Equal(actual.Heading()), msgAndArgs...) &&
+		testset.AssertSetEqual(t, expected.Tuples(), actual.Tuples(), msgAndArgs...)
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	r, err := rel.New([]string{"x", "y"}, []any{1, 2}, []any{3, 4})
+	test.NoError(t, err)
+	test.Equal(t, 2, r.Count())
+	test.Equal(t, "{x, y}", r.Heading().String())
+	test.True(t, r.Has(rel.NewTuple(frozen.KV[string, any]("x", 3), frozen.KV[string, any]("y", 4))))
+
+	_, err = rel.New([]string{"x", "y"}, []any{1, 2}, []any{3})
+	test.True(t, errors.Is(err, rel.ErrHeadingMismatch))
+	_, err = rel.New([]string{"x", "x"})
+	test.True(t, errors.Is(err, rel.ErrDuplicateAttribute))
+	test.Panic(t, func() { rel.MustNew([]string{"x"}, []any{1, 2}) })
+
+	// Empty relations keep their headings.
+	empty := rel.MustNew([]string{"x"})
+	test.True(t, empty.IsEmpty())
+	test.False(t, empty.Equal(rel.MustNew([]string{"y"})))
+}
+
+func TestFromSet(t *testing.T) {
+	t.Parallel()
+
+	xy := rel.NewTuple(frozen.KV[string, any]("x", 1), frozen.KV[string, any]("y", 2))
+	x := rel.NewTuple(frozen.KV[string, any]("x", 1))
+	r, err := rel.FromSet(rel.NewHeading("x", "y"), frozen.NewSet(xy))
+	test.NoError(t, err)
+	assertRelationEqual(t, rel.MustNew([]string{"x", "y"}, []any{1, 2}), r)
+
+	_, err = rel.FromSet(rel.NewHeading("x", "y"), frozen.NewSet(xy, x))
+	test.True(t, errors.Is(err, rel.ErrHeadingMismatch))
+
+	b := rel.NewRelationBuilder(rel.NewHeading("x"))
+	test.NoError(t, b.Add(x))
+	test.True(t, errors.Is(b.Add(xy), rel.ErrHeadingMismatch))
+	assertRelationEqual(t, rel.MustNew([]string{"x"}, []any{1}), b.Finish())
+}
+
+func TestProject(t *testing.T) {
+	t.Parallel()
+
+	r := rel.MustNew([]string{"x", "y"}, []any{1, 2}, []any{1, 3})
+	p, err := rel.Project(r, "x")
+	test.NoError(t, err)
+	assertRelationEqual(t, rel.MustNew([]string{"x"}, []any{1}), p)
+
+	p, err = rel.Project(rel.MustNew([]string{"x", "y"}), "y")
+	test.NoError(t, err)
+	assertRelationEqual(t, rel.MustNew([]string{"y"}), p)
+
+	_, err = rel.Project(r, "x", "z")
+	test.True(t, errors.Is(err, rel.ErrUnknownAttribute))
+}
+
+func TestJoinEmpty(t *testing.T) {
+	t.Parallel()
+
+	a := rel.MustNew([]string{"x", "y"}, []any{1, 2})
+	b := rel.MustNew([]string{"y", "z"})
+	assertRelationEqual(t, rel.MustNew([]string{"x", "y", "z"}), rel.Join(a, b))
+	assertRelationEqual(t, a, rel.Join(a))
+	assertRelationEqual(t, rel.MustNew(nil, []any{}), rel.Join())
+}
+
+func TestCartesianProduct(t *testing.T) {
+	t.Parallel()
+
+	a := rel.MustNew([]string{"x"}, []any{1}, []any{2})
+	b := rel.MustNew([]string{"y"}, []any{3})
+	p, err := rel.CartesianProduct(a, b)
+	test.NoError(t, err)
+	assertRelationEqual(t, rel.MustNew([]string{"x", "y"}, []any{1, 3}, []any{2, 3}), p)
+	assertRelationEqual(t, rel.Join(a, b), p)
+
+	_, err = rel.CartesianProduct(a, b, rel.MustNew([]string{"x", "z"}))
+	test.True(t, errors.Is(err, rel.ErrDuplicateAttribute))
+}
+
 func TestJoinSimple(t *testing.T) {
 	t.Parallel()
 
-	a := rel.New(
+	a := rel.MustNew(
 		[]string{"x", "y"},
 		[]any{1, 2},
 	)
-	b := rel.New(
+	b := rel.MustNew(
 		[]string{"y", "z"},
 		[]any{2, 3},
 	)
-	expected := rel.New(
+	expected := rel.MustNew(
 		[]string{"x", "y", "z"},
 		[]any{1, 2, 3},
 	)
 	actual := rel.Join(a, b)
-	testset.AssertSetEqual(t, expected, actual)
+	assertRelationEqual(t, expected, actual)
 }
 
 // We use numbers as follows to represent tuples:
@@ -74,7 +162,7 @@ func (a bitRelation) toRelation() rel.Relation {
 		}
 		rows = append(rows, row)
 	}
-	return rel.New(h, rows...)
+	return rel.MustNew(h, rows...)
 }
 
 func (a bitRelation) join(b bitRelation) bitRelation {
@@ -130,7 +218,7 @@ func testJoinExhaustiveCase(t *testing.T, i0 uint64) {
 			setA := a.toRelation()
 			setB := b.toRelation()
 			setC := c.toRelation()
-			if !testset.AssertSetEqual(t, setC, rel.Join(setA, setB), "a=%b=%v b=%b=%v", a, setA, b, setB) {
+			if !testset.AssertSetEqual(t, setC.Tuples(), rel.Join(setA, setB).Tuples(), "a=%b=%v b=%b=%v", a, setA, b, setB) {
 				_ = a.join(b)
 				rel.Join(setA, setB)
 				t.FailNow()
@@ -144,7 +232,7 @@ func testJoinExhaustiveCase(t *testing.T, i0 uint64) {
 func TestNest(t *testing.T) {
 	t.Parallel()
 
-	ca := rel.New(
+	ca := rel.MustNew(
 		[]string{"c", "a"},
 		[]any{1, 10},
 		[]any{1, 11},
@@ -154,43 +242,55 @@ func TestNest(t *testing.T) {
 		[]any{3, 10},
 		// []any{4, 13},
 	)
-	sharing := rel.Nest(ca, frozen.NewMap(frozen.KV("aa", frozen.NewSet("a"))))
+	sharing, err := rel.Nest(ca, frozen.NewMap(frozen.KV("aa", frozen.NewSet("a"))))
+	test.NoError(t, err)
 	// t.Log(sharing)
-	sharing = rel.Nest(sharing, frozen.NewMap(frozen.KV("cc", frozen.NewSet("c"))))
+	sharing, err = rel.Nest(sharing, frozen.NewMap(frozen.KV("cc", frozen.NewSet("c"))))
+	test.NoError(t, err)
 	// t.Log(sharing)
-	sharing = sharing.Where(func(t rel.Tuple) bool {
+	sharing = rel.Restrict(sharing, func(t rel.Tuple) bool {
 		return t.MustGet("cc").(rel.Relation).Count() > 1
 	})
 	// t.Log(sharing)
-	expected := rel.New(
+	expected := rel.MustNew(
 		[]string{"aa", "cc"},
 		[]any{
-			rel.New([]string{"a"}, []any{10}, []any{11}),
-			rel.New([]string{"c"}, []any{1}, []any{3}),
+			rel.MustNew([]string{"a"}, []any{10}, []any{11}),
+			rel.MustNew([]string{"c"}, []any{1}, []any{3}),
 		},
 	)
-	testset.AssertSetEqual(t, expected, sharing)
+	assertRelationEqual(t, expected, sharing)
+}
+
+func TestNestErrors(t *testing.T) {
+	t.Parallel()
+
+	ca := rel.MustNew([]string{"c", "a"}, []any{1, 10})
+	_, err := rel.Nest(ca, frozen.NewMap(frozen.KV("aa", frozen.NewSet("b"))))
+	test.True(t, errors.Is(err, rel.ErrUnknownAttribute))
+	_, err = rel.Nest(ca, frozen.NewMap(frozen.KV("c", frozen.NewSet("a"))))
+	test.True(t, errors.Is(err, rel.ErrDuplicateAttribute))
 }
 
 func TestUnnest(t *testing.T) {
 	t.Parallel()
 
-	sharing := rel.New(
+	sharing := rel.MustNew(
 		[]string{"aa", "cc"},
 		[]any{
-			rel.New(
+			rel.MustNew(
 				[]string{"a"},
 				[]any{10},
 				[]any{11},
 			),
-			rel.New(
+			rel.MustNew(
 				[]string{"c"},
 				[]any{1},
 				[]any{3},
 			),
 		},
 	)
-	expected := rel.New(
+	expected := rel.MustNew(
 		[]string{"c", "a"},
 		[]any{1, 10},
 		[]any{1, 11},
@@ -198,17 +298,49 @@ func TestUnnest(t *testing.T) {
 		[]any{3, 10},
 	)
 
-	actual := rel.Unnest(rel.Unnest(sharing, "cc"), "aa")
-	testset.AssertSetEqual(t, expected, actual)
+	assertRelationEqual(t, expected, mustUnnest(t, mustUnnest(t, sharing, "cc"), "aa"))
+	assertRelationEqual(t, expected, mustUnnest(t, mustUnnest(t, sharing, "aa"), "cc"))
+}
+
+func TestUnnestErrors(t *testing.T) {
+	t.Parallel()
+
+	r := rel.MustNew([]string{"a", "b"}, []any{1, 2})
+	_, err := rel.Unnest(r, "c")
+	test.True(t, errors.Is(err, rel.ErrUnknownAttribute))
+	_, err = rel.Unnest(r, "a")
+	test.True(t, errors.Is(err, rel.ErrNotRelation))
+
+	r = rel.MustNew([]string{"a", "b"},
+		[]any{1, rel.MustNew([]string{"a"}, []any{1})},
+	)
+	_, err = rel.Unnest(r, "b")
+	test.True(t, errors.Is(err, rel.ErrDuplicateAttribute))
+
+	r = rel.MustNew([]string{"a", "b"},
+		[]any{1, rel.MustNew([]string{"c"}, []any{1})},
+		[]any{2, rel.MustNew([]string{"d"}, []any{1})},
+	)
+	_, err = rel.Unnest(r, "b")
+	test.True(t, errors.Is(err, rel.ErrHeadingMismatch))
+
+	empty, err := rel.Unnest(rel.MustNew([]string{"a", "b"}), "b")
+	test.NoError(t, err)
+	test.True(t, empty.Heading().Equal(rel.NewHeading("a")))
+}
 
-	actual = rel.Unnest(rel.Unnest(sharing, "aa"), "cc")
-	testset.AssertSetEqual(t, expected, actual)
+func mustUnnest(t *testing.T, r rel.Relation, attr string) rel.Relation {
+	t.Helper()
+
+	result, err := rel.Unnest(r, attr)
+	test.NoError(t, err)
+	return result
 }
 
 // func TestNestImpl(t *testing.T) {
 // 	t.Parallel()
 
-// 	s := rel.New(
+// 	s := rel.MustNew(
 // 		[]string{"c", "a"},
 // 		[]any{1, 10},
 // 		[]any{1, 11},
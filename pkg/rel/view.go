@@ -0,0 +1,370 @@
+package rel
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/arr-ai/frozen"
+)
+
+// ErrUnknownRelation is returned when a View is given a base relation that its
+// expression doesn't use.
+var ErrUnknownRelation = errors.New("unknown relation")
+
+// Delta is a change to a relation: tuples to insert and tuples to delete.
+// When applying a Delta, deletions happen before insertions, so a tuple in both
+// ends up in the relation.
+type Delta struct {
+	Inserted frozen.Set[Tuple]
+	Deleted  frozen.Set[Tuple]
+}
+
+// IsEmpty returns true iff d changes nothing.
+func (d Delta) IsEmpty() bool {
+	return d.Inserted.IsEmpty() && d.Deleted.IsEmpty()
+}
+
+// Expr is a relational expression over named base relations, from which
+// NewView builds an incrementally maintained View. Errors in an Expr, such as
+// projecting an attribute it lacks, are reported by NewView.
+type Expr struct {
+	compile func(c *viewCompiler) (viewNode, error)
+}
+
+// Base returns an Expr for the base relation called name, which has the given
+// heading.
+func Base(name string, heading Heading) Expr {
+	return Expr{compile: func(c *viewCompiler) (viewNode, error) {
+		if h, has := c.headings[name]; has && !h.Equal(heading) {
+			return nil, fmt.Errorf("Base: %w: %q has headings %v and %v", ErrHeadingMismatch, name, h, heading)
+		}
+		c.headings[name] = heading
+		return &baseNode{name: name, state: Relation{heading: heading}}, nil
+	}}
+}
+
+// Join returns an Expr for the natural join of e and f.
+func (e Expr) Join(f Expr) Expr {
+	return Expr{compile: func(c *viewCompiler) (viewNode, error) {
+		left, right, err := c.compile2(e, f)
+		if err != nil {
+			return nil, err
+		}
+		common := left.heading().Intersection(right.heading())
+		return &joinNode{left: left, right: right, common: common.Elements()}, nil
+	}}
+}
+
+// Project returns an Expr for the projection of e onto attrs.
+func (e Expr) Project(attrs ...string) Expr {
+	return Expr{compile: func(c *viewCompiler) (viewNode, error) {
+		input, err := e.compile(c)
+		if err != nil {
+			return nil, err
+		}
+		heading := NewHeading(attrs...)
+		if !heading.IsSubsetOf(input.heading()) {
+			return nil, fmt.Errorf("Project: %w: %v not in %v",
+				ErrUnknownAttribute, heading.Difference(input.heading()), input.heading())
+		}
+		return &projectNode{input: input, h: heading, attrs: heading.Elements()}, nil
+	}}
+}
+
+// Restrict returns an Expr for the tuples of e that satisfy pred.
+func (e Expr) Restrict(pred func(t Tuple) bool) Expr {
+	return Expr{compile: func(c *viewCompiler) (viewNode, error) {
+		input, err := e.compile(c)
+		if err != nil {
+			return nil, err
+		}
+		return &restrictNode{input: input, pred: pred}, nil
+	}}
+}
+
+// Union returns an Expr for the union of e and f, which must have the same
+// heading.
+func (e Expr) Union(f Expr) Expr {
+	return Expr{compile: func(c *viewCompiler) (viewNode, error) {
+		left, right, err := c.compile2(e, f)
+		if err != nil {
+			return nil, err
+		}
+		if !left.heading().Equal(right.heading()) {
+			return nil, fmt.Errorf("Union: %w: %v and %v", ErrHeadingMismatch, left.heading(), right.heading())
+		}
+		return &unionNode{left: left, right: right}, nil
+	}}
+}
+
+type viewCompiler struct {
+	headings map[string]Heading
+}
+
+func (c *viewCompiler) compile2(e, f Expr) (viewNode, viewNode, error) {
+	left, err := e.compile(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	right, err := f.compile(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return left, right, nil
+}
+
+// View maintains the result of an Expr as its base relations change. Rather
+// than recomputing the result, it derives the change to the result from the
+// changes to the base relations, keeping just enough intermediate state to do
+// so: indexes on both sides of each join and, for each projection and union,
+// a count of how many ways each tuple is derived.
+type View struct {
+	root     viewNode
+	headings map[string]Heading
+	result   Relation
+}
+
+// NewView returns a View of e with the given initial contents for its base
+// relations. Base relations not in initial start out empty. It returns an error
+// if e is invalid or initial doesn't match e's base relations.
+func NewView(e Expr, initial map[string]Relation) (*View, error) {
+	c := &viewCompiler{headings: map[string]Heading{}}
+	root, err := e.compile(c)
+	if err != nil {
+		return nil, fmt.Errorf("NewView: %w", err)
+	}
+	v := &View{root: root, headings: c.headings, result: Relation{heading: root.heading()}}
+	deltas := make(map[string]Delta, len(initial))
+	for name, r := range initial {
+		h, has := v.headings[name]
+		if !has {
+			return nil, fmt.Errorf("NewView: %w: %q", ErrUnknownRelation, name)
+		}
+		if !r.heading.Equal(h) {
+			return nil, fmt.Errorf("NewView: %w: %q has heading %v, not %v", ErrHeadingMismatch, name, r.heading, h)
+		}
+		deltas[name] = Delta{Inserted: r.tuples}
+	}
+	v.result.tuples = root.apply(deltas).Inserted
+	return v, nil
+}
+
+// Result returns the current result of the view.
+func (v *View) Result() Relation {
+	return v.result
+}
+
+// Apply changes the base relations named in deltas and returns the resulting
+// change to the view's result. The returned Delta only inserts tuples that
+// weren't in the result and only deletes tuples that were. It returns an
+// error, and changes nothing, if deltas names a base relation that the view
+// doesn't use or has tuples that don't match its heading.
+func (v *View) Apply(deltas map[string]Delta) (Delta, error) {
+	for name, d := range deltas {
+		h, has := v.headings[name]
+		if !has {
+			return Delta{}, fmt.Errorf("Apply: %w: %q", ErrUnknownRelation, name)
+		}
+		for _, tuples := range []frozen.Set[Tuple]{d.Inserted, d.Deleted} {
+			for i := tuples.Range(); i.Next(); {
+				if t := i.Value(); !h.Matches(t) {
+					return Delta{}, fmt.Errorf("Apply: %w: tuple %v for %q with heading %v", ErrHeadingMismatch, t, name, h)
+				}
+			}
+		}
+	}
+	d := v.root.apply(deltas)
+	v.result.tuples = v.result.tuples.Difference(d.Deleted).Union(d.Inserted)
+	return d, nil
+}
+
+// viewNode is a node in a compiled View.
+type viewNode interface {
+	// heading returns the heading of the node's result.
+	heading() Heading
+
+	// apply updates the node's state for changes to the base relations and
+	// returns the change to its result. The returned Delta only inserts
+	// tuples that weren't in the result and only deletes tuples that were.
+	apply(deltas map[string]Delta) Delta
+}
+
+// baseNode holds the current contents of a base relation.
+type baseNode struct {
+	name  string
+	state Relation
+}
+
+func (n *baseNode) heading() Heading {
+	return n.state.heading
+}
+
+func (n *baseNode) apply(deltas map[string]Delta) Delta {
+	d, has := deltas[n.name]
+	if !has {
+		return Delta{}
+	}
+	old := n.state.tuples
+	n.state.tuples = old.Difference(d.Deleted).Union(d.Inserted)
+	return Delta{
+		Inserted: d.Inserted.Difference(old),
+		Deleted:  d.Deleted.Intersection(old).Difference(d.Inserted),
+	}
+}
+
+// restrictNode filters its input's changes.
+type restrictNode struct {
+	input viewNode
+	pred  func(t Tuple) bool
+}
+
+func (n *restrictNode) heading() Heading {
+	return n.input.heading()
+}
+
+func (n *restrictNode) apply(deltas map[string]Delta) Delta {
+	d := n.input.apply(deltas)
+	return Delta{Inserted: d.Inserted.Where(n.pred), Deleted: d.Deleted.Where(n.pred)}
+}
+
+// projectNode counts the input tuples that project onto each result tuple.
+type projectNode struct {
+	input  viewNode
+	h      Heading
+	attrs  []string
+	counts tupleCounts
+}
+
+func (n *projectNode) heading() Heading {
+	return n.h
+}
+
+func (n *projectNode) apply(deltas map[string]Delta) Delta {
+	d := n.input.apply(deltas)
+	return n.counts.update(func(add func(t Tuple, change int)) {
+		for i := d.Inserted.Range(); i.Next(); {
+			add(i.Value().Project(n.attrs...), 1)
+		}
+		for i := d.Deleted.Range(); i.Next(); {
+			add(i.Value().Project(n.attrs...), -1)
+		}
+	})
+}
+
+// unionNode counts the inputs that have each result tuple.
+type unionNode struct {
+	left, right viewNode
+	counts      tupleCounts
+}
+
+func (n *unionNode) heading() Heading {
+	return n.left.heading()
+}
+
+func (n *unionNode) apply(deltas map[string]Delta) Delta {
+	l, r := n.left.apply(deltas), n.right.apply(deltas)
+	return n.counts.update(func(add func(t Tuple, change int)) {
+		for _, d := range []Delta{l, r} {
+			for i := d.Inserted.Range(); i.Next(); {
+				add(i.Value(), 1)
+			}
+			for i := d.Deleted.Range(); i.Next(); {
+				add(i.Value(), -1)
+			}
+		}
+	})
+}
+
+// tupleCounts counts the ways each tuple of a result is derived. A tuple is in
+// the result while its count is positive.
+type tupleCounts struct {
+	counts frozen.Map[Tuple, int]
+}
+
+// update calls changes to adjust the counts and returns the tuples that
+// entered and left the result as a consequence.
+func (c *tupleCounts) update(changes func(add func(t Tuple, change int))) Delta {
+	var before frozen.Map[Tuple, int]
+	changes(func(t Tuple, change int) {
+		count := c.counts.GetElse(t, 0)
+		if !before.Has(t) {
+			before = before.With(t, count)
+		}
+		if count += change; count == 0 {
+			c.counts = c.counts.Without(t)
+		} else {
+			c.counts = c.counts.With(t, count)
+		}
+	})
+	var inserted, deleted frozen.SetBuilder[Tuple]
+	for i := before.Range(); i.Next(); {
+		t := i.Key()
+		switch was, is := i.Value() > 0, c.counts.Has(t); {
+		case is && !was:
+			inserted.Add(t)
+		case was && !is:
+			deleted.Add(t)
+		}
+	}
+	return Delta{Inserted: inserted.Finish(), Deleted: deleted.Finish()}
+}
+
+// joinNode indexes both inputs by their common attributes and joins each
+// side's changes with the other side: insertions with its new state and
+// deletions with its old state.
+type joinNode struct {
+	left, right viewNode
+	common      []string
+	leftIndex   joinIndex
+	rightIndex  joinIndex
+}
+
+func (n *joinNode) heading() Heading {
+	return n.left.heading().Union(n.right.heading())
+}
+
+func (n *joinNode) apply(deltas map[string]Delta) Delta {
+	l, r := n.left.apply(deltas), n.right.apply(deltas)
+	var inserted, deleted frozen.SetBuilder[Tuple]
+	n.leftIndex.join(&deleted, r.Deleted, n.common)
+	n.rightIndex.join(&deleted, l.Deleted, n.common)
+	n.leftIndex.apply(l, n.common)
+	n.rightIndex.apply(r, n.common)
+	n.leftIndex.join(&inserted, r.Inserted, n.common)
+	n.rightIndex.join(&inserted, l.Inserted, n.common)
+	return Delta{Inserted: inserted.Finish(), Deleted: deleted.Finish()}
+}
+
+// joinIndex groups the tuples of one side of a join by their common
+// attributes.
+type joinIndex struct {
+	groups frozen.Map[Tuple, frozen.Set[Tuple]]
+}
+
+// apply updates the index for changes to its side.
+func (x *joinIndex) apply(d Delta, common []string) {
+	for i := d.Deleted.Range(); i.Next(); {
+		t := i.Value()
+		key := t.Project(common...)
+		if group := x.groups.MustGet(key).Without(t); group.IsEmpty() {
+			x.groups = x.groups.Without(key)
+		} else {
+			x.groups = x.groups.With(key, group)
+		}
+	}
+	for i := d.Inserted.Range(); i.Next(); {
+		t := i.Value()
+		key := t.Project(common...)
+		x.groups = x.groups.With(key, x.groups.GetElse(key, frozen.Set[Tuple]{}).With(t))
+	}
+}
+
+// join adds to b the join of tuples with the indexed tuples.
+func (x *joinIndex) join(b *frozen.SetBuilder[Tuple], tuples frozen.Set[Tuple], common []string) {
+	for i := tuples.Range(); i.Next(); {
+		t := i.Value()
+		for j := x.groups.GetElse(t.Project(common...), frozen.Set[Tuple]{}).Range(); j.Next(); {
+			b.Add(j.Value().Update(t))
+		}
+	}
+}
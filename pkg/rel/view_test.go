@@ -0,0 +1,145 @@
+package rel_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	testset "github.com/arr-ai/frozen/internal/pkg/test/set"
+	"github.com/arr-ai/frozen/pkg/rel"
+)
+
+func TestViewSimple(t *testing.T) {
+	t.Parallel()
+
+	must := mustFor(t)
+	xy, yz := rel.NewHeading("x", "y"), rel.NewHeading("y", "z")
+	e := rel.Base("a", xy).Join(rel.Base("b", yz)).Project("x", "z")
+	v, err := rel.NewView(e, map[string]rel.Relation{
+		"a": rel.MustNew([]string{"x", "y"}, []any{1, 1}, []any{2, 1}),
+		"b": rel.MustNew([]string{"y", "z"}, []any{1, 10}),
+	})
+	test.RequireNoError(t, err)
+	assertRelationEqual(t, rel.MustNew([]string{"x", "z"}, []any{1, 10}, []any{2, 10}), v.Result())
+
+	yz120 := rel.NewTuple(frozen.KV[string, any]("y", 1), frozen.KV[string, any]("z", 20))
+	d, err := v.Apply(map[string]rel.Delta{"b": {Inserted: frozen.NewSet(yz120)}})
+	test.NoError(t, err)
+	assertRelationEqual(t,
+		rel.MustNew([]string{"x", "z"}, []any{1, 20}, []any{2, 20}),
+		must(rel.FromSet(rel.NewHeading("x", "z"), d.Inserted)))
+	test.True(t, d.Deleted.IsEmpty())
+
+	// Deleting one of two tuples that project to the same result changes nothing.
+	v2, err := rel.NewView(rel.Base("a", xy).Project("x"), map[string]rel.Relation{
+		"a": rel.MustNew([]string{"x", "y"}, []any{1, 1}, []any{1, 2}),
+	})
+	test.RequireNoError(t, err)
+	xy12 := rel.NewTuple(frozen.KV[string, any]("x", 1), frozen.KV[string, any]("y", 2))
+	d, err = v2.Apply(map[string]rel.Delta{"a": {Deleted: frozen.NewSet(xy12)}})
+	test.NoError(t, err)
+	test.True(t, d.IsEmpty())
+	assertRelationEqual(t, rel.MustNew([]string{"x"}, []any{1}), v2.Result())
+}
+
+func TestViewErrors(t *testing.T) {
+	t.Parallel()
+
+	xy, yz := rel.NewHeading("x", "y"), rel.NewHeading("y", "z")
+	a, b := rel.Base("a", xy), rel.Base("b", yz)
+
+	_, err := rel.NewView(a.Project("z"), nil)
+	test.True(t, errors.Is(err, rel.ErrUnknownAttribute))
+	_, err = rel.NewView(a.Union(b), nil)
+	test.True(t, errors.Is(err, rel.ErrHeadingMismatch))
+	_, err = rel.NewView(a.Join(rel.Base("a", yz)), nil)
+	test.True(t, errors.Is(err, rel.ErrHeadingMismatch))
+	_, err = rel.NewView(a, map[string]rel.Relation{"b": rel.MustNew([]string{"y", "z"})})
+	test.True(t, errors.Is(err, rel.ErrUnknownRelation))
+	_, err = rel.NewView(a, map[string]rel.Relation{"a": rel.MustNew([]string{"y", "z"})})
+	test.True(t, errors.Is(err, rel.ErrHeadingMismatch))
+
+	v, err := rel.NewView(a, nil)
+	test.RequireNoError(t, err)
+	_, err = v.Apply(map[string]rel.Delta{"b": {}})
+	test.True(t, errors.Is(err, rel.ErrUnknownRelation))
+	bad := rel.NewTuple(frozen.KV[string, any]("x", 1))
+	_, err = v.Apply(map[string]rel.Delta{"a": {Inserted: frozen.NewSet(bad)}})
+	test.True(t, errors.Is(err, rel.ErrHeadingMismatch))
+	test.True(t, v.Result().IsEmpty())
+}
+
+// randomDelta returns random insertions and deletions for a relation with the
+// given heading, deleting some of the tuples in r.
+func randomDelta(rnd *rand.Rand, r rel.Relation, header ...string) rel.Delta {
+	inserted := randomRelation(rnd, 3, header...).Tuples()
+	deleted := randomRelation(rnd, 2, header...).Tuples()
+	for i := r.Range(); i.Next(); {
+		if rnd.Intn(4) == 0 {
+			deleted = deleted.With(i.Value())
+		}
+	}
+	return rel.Delta{Inserted: inserted, Deleted: deleted}
+}
+
+func applyDelta(t *testing.T, r rel.Relation, d rel.Delta) rel.Relation {
+	t.Helper()
+
+	return mustFor(t)(rel.FromSet(r.Heading(), r.Tuples().Difference(d.Deleted).Union(d.Inserted)))
+}
+
+func TestViewRandomUpdates(t *testing.T) {
+	t.Parallel()
+
+	must := mustFor(t)
+	xy, yz, xz := rel.NewHeading("x", "y"), rel.NewHeading("y", "z"), rel.NewHeading("x", "z")
+	notEqual := func(t rel.Tuple) bool { return t.MustGet("x") != t.MustGet("z") }
+	a, b, c := rel.Base("a", xy), rel.Base("b", yz), rel.Base("c", xz)
+	e := a.Join(b).Restrict(notEqual).Project("x", "z").Union(c.Join(a).Project("x", "z"))
+	recompute := func(ra, rb, rc rel.Relation) rel.Relation {
+		left := must(rel.Project(rel.Restrict(rel.Join(ra, rb), notEqual), "x", "z"))
+		return must(rel.Union(left, must(rel.Project(rel.Join(rc, ra), "x", "z"))))
+	}
+
+	rnd := rand.New(rand.NewSource(0)) //nolint:gosec
+	streams := 20
+	if testing.Short() {
+		streams = 5
+	}
+	for stream := 0; stream < streams; stream++ {
+		ra := randomRelation(rnd, 6, "x", "y")
+		rb := randomRelation(rnd, 6, "y", "z")
+		rc := randomRelation(rnd, 6, "x", "z")
+		v, err := rel.NewView(e, map[string]rel.Relation{"a": ra, "b": rb, "c": rc})
+		test.RequireNoError(t, err)
+		if !assertRelationEqual(t, recompute(ra, rb, rc), v.Result()) {
+			return
+		}
+		for step := 0; step < 30; step++ {
+			deltas := map[string]rel.Delta{}
+			if rnd.Intn(2) == 0 {
+				deltas["a"] = randomDelta(rnd, ra, "x", "y")
+				ra = applyDelta(t, ra, deltas["a"])
+			}
+			if rnd.Intn(2) == 0 {
+				deltas["b"] = randomDelta(rnd, rb, "y", "z")
+				rb = applyDelta(t, rb, deltas["b"])
+			}
+			if rnd.Intn(3) == 0 {
+				deltas["c"] = randomDelta(rnd, rc, "x", "z")
+				rc = applyDelta(t, rc, deltas["c"])
+			}
+			before := v.Result()
+			d, err := v.Apply(deltas)
+			test.RequireNoError(t, err)
+			expected := recompute(ra, rb, rc)
+			if !assertRelationEqual(t, expected, v.Result(), "stream %d step %d: ", stream, step) ||
+				!testset.AssertSetEqual(t, expected.Tuples().Difference(before.Tuples()), d.Inserted) ||
+				!testset.AssertSetEqual(t, before.Tuples().Difference(expected.Tuples()), d.Deleted) {
+				return
+			}
+		}
+	}
+}
@@ -65,6 +65,19 @@ func (s Set[T]) Range() Iterator[T] {
 	return s.tree.Iterator()
 }
 
+// RangeParts returns Iterators over disjoint subsets of s that together cover
+// it, so that s can be processed in parallel. Each subset is a branch of the
+// underlying trie. There are at least n of them unless s is too small to
+// split that far.
+func (s Set[T]) RangeParts(n int) []Iterator[T] {
+	parts := s.tree.Parts(n)
+	iters := make([]Iterator[T], 0, len(parts))
+	for _, part := range parts {
+		iters = append(iters, part)
+	}
+	return iters
+}
+
 func (s Set[T]) Elements() []T {
 	result := make([]T, 0, s.Count())
 	for i := s.Range(); i.Next(); {
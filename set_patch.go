@@ -0,0 +1,149 @@
+package frozen
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+// SetPatch is a serializable set of changes to a Set. The zero value is the
+// empty SetPatch.
+type SetPatch[T any] struct {
+	// Adds holds the elements that the SetPatch adds.
+	Adds Set[T]
+
+	// Removes holds the elements that the SetPatch removes.
+	Removes Set[T]
+}
+
+// DiffPatch returns a SetPatch that turns s into t. Like Map.DiffPatch, it
+// skips subtrees that s and t share.
+func (s Set[T]) DiffPatch(t Set[T]) SetPatch[T] {
+	var adds, removes SetBuilder[T]
+	s.tree.Diff(t.tree, func(a, b *T) {
+		switch {
+		case b == nil:
+			removes.Add(*a)
+		case a == nil:
+			adds.Add(*b)
+		}
+	})
+	return SetPatch[T]{Adds: adds.Finish(), Removes: removes.Finish()}
+}
+
+// Apply returns s with p applied. Preconditions are not checked: adding an
+// element that is present or removing one that is absent has no effect. The
+// edits are applied as bulk tree operations rather than one element at a
+// time.
+func (s Set[T]) Apply(p SetPatch[T]) Set[T] {
+	return s.Difference(p.Removes).Union(p.Adds)
+}
+
+// ApplyStrict is like Apply, but first checks that s satisfies the
+// preconditions of p: elements added must be absent and elements removed must
+// be present. It returns s and an error wrapping ErrPatchMismatch if they
+// don't.
+func (s Set[T]) ApplyStrict(p SetPatch[T]) (Set[T], error) {
+	if present := p.Adds.Intersection(s); !present.IsEmpty() {
+		return s, fmt.Errorf("%w: added elements %v already present", ErrPatchMismatch, present)
+	}
+	if absent := p.Removes.Difference(s); !absent.IsEmpty() {
+		return s, fmt.Errorf("%w: removed elements %v not present", ErrPatchMismatch, absent)
+	}
+	return s.Apply(p), nil
+}
+
+// IsEmpty returns true iff p makes no changes.
+func (p SetPatch[T]) IsEmpty() bool {
+	return p.Adds.IsEmpty() && p.Removes.IsEmpty()
+}
+
+// Count returns the number of elements p changes.
+func (p SetPatch[T]) Count() int {
+	return p.Adds.Count() + p.Removes.Count()
+}
+
+// Invert returns a SetPatch that undoes p.
+func (p SetPatch[T]) Invert() SetPatch[T] {
+	return SetPatch[T]{Adds: p.Removes, Removes: p.Adds}
+}
+
+// Compose returns a SetPatch equivalent to applying p and then q. An element
+// that one patch adds and the other removes is left unchanged.
+func (p SetPatch[T]) Compose(q SetPatch[T]) SetPatch[T] {
+	return SetPatch[T]{
+		Adds:    p.Adds.Difference(q.Removes).Union(q.Adds.Difference(p.Removes)),
+		Removes: p.Removes.Difference(q.Adds).Union(q.Removes.Difference(p.Adds)),
+	}
+}
+
+// Equal returns true iff p and q make the same changes.
+func (p SetPatch[T]) Equal(q SetPatch[T]) bool {
+	return p.Adds.Equal(q.Adds) && p.Removes.Equal(q.Removes)
+}
+
+// String returns a string representation of the SetPatch.
+func (p SetPatch[T]) String() string {
+	return fmt.Sprintf("+%v -%v", p.Adds, p.Removes)
+}
+
+// setPatchProxy is the serialized form of a SetPatch.
+type setPatchProxy[T any] struct {
+	Adds    []T `json:"adds,omitempty"`
+	Removes []T `json:"removes,omitempty"`
+}
+
+func (p SetPatch[T]) proxy() setPatchProxy[T] {
+	return setPatchProxy[T]{Adds: p.Adds.Elements(), Removes: p.Removes.Elements()}
+}
+
+func (x setPatchProxy[T]) patch() SetPatch[T] {
+	return SetPatch[T]{Adds: NewSet(x.Adds...), Removes: NewSet(x.Removes...)}
+}
+
+// MarshalJSON implements json.Marshaler. Each of the two sets is encoded as an
+// array.
+func (p SetPatch[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.proxy())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *SetPatch[T]) UnmarshalJSON(data []byte) error {
+	var x setPatchProxy[T]
+	if err := json.Unmarshal(data, &x); err != nil {
+		return err
+	}
+	*p = x.patch()
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using encoding/gob. If T
+// is an interface type, the concrete types stored in it must be registered
+// with gob.Register.
+func (p SetPatch[T]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(p.proxy()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *SetPatch[T]) UnmarshalBinary(data []byte) error {
+	var x setPatchProxy[T]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&x); err != nil {
+		return err
+	}
+	*p = x.patch()
+	return nil
+}
+
+// Ensure that SetPatch implements the encoding interfaces.
+var (
+	_ json.Marshaler             = SetPatch[int]{}
+	_ json.Unmarshaler           = &SetPatch[int]{}
+	_ encoding.BinaryMarshaler   = SetPatch[int]{}
+	_ encoding.BinaryUnmarshaler = &SetPatch[int]{}
+)
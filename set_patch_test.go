@@ -0,0 +1,96 @@
+package frozen_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/arr-ai/frozen"
+	"github.com/arr-ai/frozen/internal/pkg/test"
+	testset "github.com/arr-ai/frozen/internal/pkg/test/set"
+)
+
+func randomSetInt(r *rand.Rand, n int) frozen.Set[int] {
+	var b frozen.SetBuilder[int]
+	for i := 0; i < n; i++ {
+		b.Add(r.Intn(2 * n))
+	}
+	return b.Finish()
+}
+
+func TestSetPatch(t *testing.T) {
+	t.Parallel()
+
+	s := frozen.NewSet(1, 2, 3)
+	u := frozen.NewSet(1, 2, 4)
+	p := s.DiffPatch(u)
+	testset.AssertSetEqual(t, frozen.NewSet(4), p.Adds)
+	testset.AssertSetEqual(t, frozen.NewSet(3), p.Removes)
+	test.Equal(t, 2, p.Count())
+
+	testset.AssertSetEqual(t, u, s.Apply(p))
+	testset.AssertSetEqual(t, s, u.Apply(p.Invert()))
+	test.True(t, s.DiffPatch(s).IsEmpty())
+	test.True(t, p.Compose(p.Invert()).IsEmpty())
+}
+
+func TestSetPatchApplyStrict(t *testing.T) {
+	t.Parallel()
+
+	s := frozen.NewSet(1, 2)
+	p := s.DiffPatch(s.Without(2).With(3))
+
+	u, err := s.ApplyStrict(p)
+	if test.NoError(t, err) {
+		testset.AssertSetEqual(t, s.Apply(p), u)
+	}
+
+	for _, bad := range []frozen.Set[int]{s.With(3), s.Without(2)} {
+		u, err := bad.ApplyStrict(p)
+		test.True(t, errors.Is(err, frozen.ErrPatchMismatch), "%v", bad)
+		testset.AssertSetEqual(t, bad, u)
+	}
+}
+
+func TestSetPatchCompose(t *testing.T) {
+	t.Parallel()
+
+	rounds := 200
+	if testing.Short() {
+		rounds = 20
+	}
+	r := rand.New(rand.NewSource(0)) //nolint:gosec
+	for round := 0; round < rounds; round++ {
+		a := randomSetInt(r, 20)
+		b := randomSetInt(r, 20)
+		c := randomSetInt(r, 20)
+		p := a.DiffPatch(b).Compose(b.DiffPatch(c))
+		testset.AssertSetEqual(t, c, a.Apply(p))
+		test.True(t, a.DiffPatch(c).Equal(p), "round=%d", round)
+		test.True(t, c.DiffPatch(a).Equal(p.Invert()), "round=%d", round)
+	}
+}
+
+func TestSetPatchEncoding(t *testing.T) {
+	t.Parallel()
+
+	s := frozen.NewSet("a", "b")
+	p := s.DiffPatch(s.Without("b").With("c"))
+
+	data, err := json.Marshal(p)
+	if test.NoError(t, err) {
+		var q frozen.SetPatch[string]
+		if test.NoError(t, json.Unmarshal(data, &q)) {
+			test.True(t, p.Equal(q), "%s", data)
+		}
+	}
+
+	data, err = p.MarshalBinary()
+	if test.NoError(t, err) {
+		var q frozen.SetPatch[string]
+		if test.NoError(t, q.UnmarshalBinary(data)) {
+			test.True(t, p.Equal(q))
+		}
+	}
+}
@@ -688,6 +688,30 @@ func TestSetRange(t *testing.T) {
 	test.Equal(t, ^uint64(0), mask)
 }
 
+func TestSetRangeParts(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int{0, 1, 10, 1000} {
+		s := frozen.Iota(n)
+		for _, parts := range []int{1, 2, 8, 100} {
+			iters := s.RangeParts(parts)
+			if n >= 1000 {
+				test.True(t, len(iters) >= parts, "n=%d parts=%d", n, parts)
+			}
+			var b frozen.SetBuilder[int]
+			count := 0
+			for _, i := range iters {
+				for i.Next() {
+					b.Add(i.Value())
+					count++
+				}
+			}
+			test.Equal(t, n, count, "n=%d parts=%d", n, parts)
+			test.True(t, b.Finish().Equal(s), "n=%d parts=%d", n, parts)
+		}
+	}
+}
+
 func TestSetOrderedRange(t *testing.T) {
 	t.Parallel()
 